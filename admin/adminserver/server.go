@@ -0,0 +1,186 @@
+package adminserver
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"runtime/debug"
+	runtimepprof "runtime/pprof"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/zeebo/errs"
+	"golang.org/x/sync/errgroup"
+
+	"project_template/pkg/config"
+	"project_template/pkg/logger"
+)
+
+var (
+	// Error is an error class that indicates internal http server error.
+	Error = errs.Class("admin web server error")
+)
+
+// Config contains configuration for admin web server.
+type Config struct {
+	Address string `env:"ADMIN_SERVER_ADDRESS" validate:"required" envDefault:"127.0.0.1:8089" desc:"address the admin server with metrics and debug endpoints listens on, it has no authentication and should not be public"`
+}
+
+// DBStats provides statistics of the database connection pool.
+type DBStats interface {
+	Stats() sql.DBStats
+}
+
+// Server represents admin web server with operational endpoints: metrics, profiling and runtime introspection.
+//
+// architecture: Endpoint
+type Server struct {
+	log    logger.Logger
+	config Config
+
+	listener net.Listener
+	server   http.Server
+
+	// appConfig is the effective config of the app, it's served with secrets redacted.
+	appConfig interface{}
+	db        DBStats
+}
+
+// NewServer is a constructor for admin web server.
+func NewServer(config Config, log logger.Logger, listener net.Listener, appConfig interface{}, db DBStats) *Server {
+	server := &Server{
+		log:       log,
+		config:    config,
+		listener:  listener,
+		appConfig: appConfig,
+		db:        db,
+	}
+
+	router := mux.NewRouter()
+
+	// Prometheus' metrics endpoint
+	router.Handle("/metrics", promhttp.Handler())
+
+	debugRouter := router.PathPrefix("/debug").Subrouter()
+	debugRouter.HandleFunc("/pprof/cmdline", pprof.Cmdline)
+	debugRouter.HandleFunc("/pprof/profile", pprof.Profile)
+	debugRouter.HandleFunc("/pprof/symbol", pprof.Symbol)
+	debugRouter.HandleFunc("/pprof/trace", pprof.Trace)
+	// the index serves named profiles, e.g. /debug/pprof/heap.
+	debugRouter.PathPrefix("/pprof/").HandlerFunc(pprof.Index)
+	debugRouter.Handle("/vars", expvar.Handler())
+	debugRouter.HandleFunc("/build", server.build).Methods(http.MethodGet)
+	debugRouter.HandleFunc("/config", server.effectiveConfig).Methods(http.MethodGet)
+	debugRouter.HandleFunc("/goroutines", server.goroutines).Methods(http.MethodGet)
+	debugRouter.HandleFunc("/db", server.dbStats).Methods(http.MethodGet)
+
+	server.server = http.Server{
+		Handler: router,
+	}
+
+	return server
+}
+
+// Run starts the server that hosts operational endpoints.
+func (server *Server) Run(ctx context.Context) (err error) {
+	ctx, cancel := context.WithCancel(ctx)
+	var group errgroup.Group
+	group.Go(func() error {
+		<-ctx.Done()
+		return Error.Wrap(server.server.Shutdown(context.Background()))
+	})
+	group.Go(func() error {
+		defer cancel()
+		err := server.server.Serve(server.listener)
+		isCancelled := errs.IsFunc(err, func(err error) bool { return errors.Is(err, context.Canceled) })
+		if isCancelled || errors.Is(err, http.ErrServerClosed) {
+			err = nil
+		}
+		return Error.Wrap(err)
+	})
+
+	return Error.Wrap(group.Wait())
+}
+
+// Close closes server and underlying listener.
+func (server *Server) Close() error {
+	return Error.Wrap(server.server.Close())
+}
+
+// buildInfo describes the running binary.
+type buildInfo struct {
+	GoVersion string            `json:"goVersion"`
+	Path      string            `json:"path"`
+	Version   string            `json:"version"`
+	Settings  map[string]string `json:"settings"`
+}
+
+// build returns the go version, module version and build settings, e.g. vcs.revision.
+func (server *Server) build(w http.ResponseWriter, r *http.Request) {
+	info := buildInfo{GoVersion: runtime.Version(), Settings: make(map[string]string)}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		info.Path = bi.Main.Path
+		info.Version = bi.Main.Version
+		for _, setting := range bi.Settings {
+			info.Settings[setting.Key] = setting.Value
+		}
+	}
+
+	server.serveJSON(w, info)
+}
+
+// effectiveConfig returns values of all the config env. variables, secrets are redacted.
+func (server *Server) effectiveConfig(w http.ResponseWriter, r *http.Request) {
+	server.serveJSON(w, config.Values(server.appConfig))
+}
+
+// goroutines writes stacks of all goroutines as text.
+func (server *Server) goroutines(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+
+	if err := runtimepprof.Lookup("goroutine").WriteTo(w, 2); err != nil {
+		server.log.Error("failed to write goroutines", Error.Wrap(err))
+	}
+}
+
+// dbStats returns statistics of the database connection pool.
+func (server *Server) dbStats(w http.ResponseWriter, r *http.Request) {
+	stats := server.db.Stats()
+
+	server.serveJSON(w, struct {
+		MaxOpenConnections int    `json:"maxOpenConnections"`
+		OpenConnections    int    `json:"openConnections"`
+		InUse              int    `json:"inUse"`
+		Idle               int    `json:"idle"`
+		WaitCount          int64  `json:"waitCount"`
+		WaitDuration       string `json:"waitDuration"`
+		MaxIdleClosed      int64  `json:"maxIdleClosed"`
+		MaxIdleTimeClosed  int64  `json:"maxIdleTimeClosed"`
+		MaxLifetimeClosed  int64  `json:"maxLifetimeClosed"`
+	}{
+		MaxOpenConnections: stats.MaxOpenConnections,
+		OpenConnections:    stats.OpenConnections,
+		InUse:              stats.InUse,
+		Idle:               stats.Idle,
+		WaitCount:          stats.WaitCount,
+		WaitDuration:       stats.WaitDuration.String(),
+		MaxIdleClosed:      stats.MaxIdleClosed,
+		MaxIdleTimeClosed:  stats.MaxIdleTimeClosed,
+		MaxLifetimeClosed:  stats.MaxLifetimeClosed,
+	})
+}
+
+// serveJSON writes the value as json.
+func (server *Server) serveJSON(w http.ResponseWriter, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		server.log.Error("failed to write json response", Error.Wrap(err))
+	}
+}
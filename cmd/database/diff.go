@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/zeebo/errs"
+
+	"project_template/database"
+	"project_template/pkg/config"
+	"project_template/pkg/logger/zaplog"
+	"project_template/pkg/pgschema"
+	"project_template/pkg/tempdb"
+)
+
+// diff commands.
+var (
+	// compare schema built from migrations with the live database.
+	diffCmd = &cobra.Command{
+		Use:         "diff",
+		Short:       "compares schema built from migrations with the live database, exits with error on drift",
+		Args:        cobra.NoArgs,
+		RunE:        cmdDiff,
+		Annotations: map[string]string{"type": "run"},
+	}
+
+	diffFlags struct {
+		Schema string
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+
+	diffCmd.Flags().StringVar(&diffFlags.Schema, "schema", "public", "schema of the live database to compare")
+}
+
+// cmdDiff applies migrations into a temporary schema and compares it with the live database schema.
+func cmdDiff(cmd *cobra.Command, args []string) (err error) {
+	ctx := context.Background()
+	log := zaplog.NewLog()
+
+	runCfg := Config{}
+	err = config.ReadConfig(&runCfg)
+	if err != nil {
+		log.Error("could not read config", Error.Wrap(err))
+		return Error.Wrap(err)
+	}
+
+	expected, err := migratedSchema(ctx, runCfg)
+	if err != nil {
+		log.Error("could not build schema from migrations", Error.Wrap(err))
+		return Error.Wrap(err)
+	}
+
+	actual, err := introspect(ctx, tempdb.ConnstrWithSearchPath(database.ConnStr(runCfg.DBConfig), diffFlags.Schema, pgschema.ExtensionSchema))
+	if err != nil {
+		log.Error("could not read live database schema", Error.Wrap(err))
+		return Error.Wrap(err)
+	}
+
+	diffs := pgschema.Diff(expected, actual)
+	for _, diff := range diffs {
+		fmt.Println(diff)
+	}
+
+	if len(diffs) > 0 {
+		err = Error.New("schema drift: %d differences between migrations and database", len(diffs))
+		log.Error("\"+\" objects exist only in database, \"-\" objects exist only in migrations", err)
+		return err
+	}
+
+	return nil
+}
+
+// migratedSchema applies all migrations into a temporary schema and introspects it.
+func migratedSchema(ctx context.Context, runCfg Config) (schema *pgschema.Schema, err error) {
+	err = withScratchSchema(ctx, runCfg, func(conn *sql.DB, scratch *pgschema.Schema) error {
+		schema = scratch
+		return nil
+	})
+
+	return schema, err
+}
+
+// withScratchSchema applies all migrations into a temporary schema and calls fn
+// with connection to it and its introspected schema. The schema is dropped afterwards.
+func withScratchSchema(ctx context.Context, runCfg Config, fn func(conn *sql.DB, schema *pgschema.Schema) error) (err error) {
+	tempDB, err := tempdb.OpenUnique(ctx, runCfg.DBConfig, "scratch")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errs.Combine(err, tempDB.Close())
+	}()
+
+	scratch, err := database.NewByCoonStr(tempDB.ConnStr)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errs.Combine(err, scratch.Close())
+	}()
+
+	if err = scratch.Migrations(runCfg.MigrationsPath).Up(ctx); err != nil {
+		return err
+	}
+
+	// the live database is introspected with the same search path, so definitions are printed the same way.
+	conn, err := sql.Open("postgres", tempDB.ConnStrWithSearchPath(pgschema.ExtensionSchema))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errs.Combine(err, conn.Close())
+	}()
+
+	schema, err := pgschema.Introspect(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	return fn(conn, schema)
+}
+
+// introspect reads schema of the database by connection string, it should have the search path of pgschema.Introspect.
+func introspect(ctx context.Context, connStr string) (_ *pgschema.Schema, err error) {
+	conn, err := sql.Open("postgres", connStr)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err = errs.Combine(err, conn.Close())
+	}()
+
+	return pgschema.Introspect(ctx, conn)
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"project_template/database"
+	"project_template/pkg/config"
+	"project_template/pkg/pgschema"
+	"project_template/pkg/tempdb"
+)
+
+func TestDiffMigrated(t *testing.T) {
+	t.Run("Postgres", func(t *testing.T) {
+		ctx := context.Background()
+
+		runCfg := Config{}
+		require.NoError(t, config.ReadConfig(&runCfg))
+
+		// the live schema is migrated like the app schema, its name differs from the scratch one.
+		live, err := tempdb.OpenUnique(ctx, runCfg.DBConfig, "live")
+		require.NoError(t, err)
+		defer func() { require.NoError(t, live.Close()) }()
+
+		liveDB, err := database.NewByCoonStr(live.ConnStr)
+		require.NoError(t, err)
+		defer func() { require.NoError(t, liveDB.Close()) }()
+		require.NoError(t, liveDB.Migrations(runCfg.MigrationsPath).Up(ctx))
+
+		actual, err := introspect(ctx, live.ConnStrWithSearchPath(pgschema.ExtensionSchema))
+		require.NoError(t, err)
+		expected, err := migratedSchema(ctx, runCfg)
+		require.NoError(t, err)
+
+		require.Empty(t, pgschema.Diff(expected, actual))
+		require.Equal(t, "CREATE INDEX dummy_title_trgm_idx ON dummy USING gin (title gin_trgm_ops)",
+			actual.Tables["dummy"].Indexes["dummy_title_trgm_idx"].Definition)
+	})
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"project_template/pkg/config"
+	"project_template/pkg/logger/zaplog"
+	"project_template/pkg/pgschema"
+	"project_template/pkg/sqlgen"
+)
+
+// generate commands.
+var (
+	// generate go code of queries.
+	generateCmd = &cobra.Command{
+		Use:         "generate",
+		Short:       "generates go query functions and row structs from annotated sql queries",
+		Args:        cobra.NoArgs,
+		RunE:        cmdGenerate,
+		Annotations: map[string]string{"type": "run"},
+	}
+
+	generateFlags struct {
+		Queries string
+		Output  string
+		Package string
+		Check   bool
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(generateCmd)
+
+	generateCmd.Flags().StringVar(&generateFlags.Queries, "queries", "database/queries", "directory with annotated sql query files")
+	generateCmd.Flags().StringVar(&generateFlags.Output, "output", "database", "directory to write generated go files to")
+	generateCmd.Flags().StringVar(&generateFlags.Package, "package", "database", "package name of generated go files")
+	generateCmd.Flags().BoolVar(&generateFlags.Check, "check", false, "fails if generated files differ from the ones in the output directory instead of writing them")
+}
+
+// cmdGenerate applies migrations into a temporary schema, infers types of the
+// annotated queries against it and writes generated go code. With --check the
+// generated code is compared to the output directory instead.
+func cmdGenerate(cmd *cobra.Command, args []string) (err error) {
+	ctx := context.Background()
+	log := zaplog.NewLog()
+
+	runCfg := Config{}
+	err = config.ReadConfig(&runCfg)
+	if err != nil {
+		log.Error("could not read config", Error.Wrap(err))
+		return Error.Wrap(err)
+	}
+
+	generated, err := generateFiles(ctx, runCfg, generateFlags.Queries, generateFlags.Package)
+	if err != nil {
+		log.Error("could not generate queries", Error.Wrap(err))
+		return Error.Wrap(err)
+	}
+
+	names := make([]string, 0, len(generated))
+	for name := range generated {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if generateFlags.Check {
+		stale, err := staleFiles(generateFlags.Output, generated)
+		if err != nil {
+			log.Error("could not check generated files", Error.Wrap(err))
+			return Error.Wrap(err)
+		}
+		if len(stale) != 0 {
+			err = Error.New("generated files are out of date, run generate: %s", strings.Join(stale, ", "))
+			log.Error("generated files are out of date", err)
+			return err
+		}
+
+		return nil
+	}
+
+	for _, name := range names {
+		if err = os.WriteFile(filepath.Join(generateFlags.Output, name), generated[name], 0644); err != nil {
+			log.Error(fmt.Sprintf("could not write file '%s'", name), Error.Wrap(err))
+			return Error.Wrap(err)
+		}
+		fmt.Printf("generated: %s\n", filepath.Join(generateFlags.Output, name))
+	}
+
+	return nil
+}
+
+// generateFiles applies migrations into a temporary schema and generates go files of the package
+// with models and queries of the directory, the files are returned by names.
+func generateFiles(ctx context.Context, runCfg Config, queriesDir, pkg string) (map[string][]byte, error) {
+	files, err := parseQueryFiles(queriesDir)
+	if err != nil {
+		return nil, err
+	}
+
+	overrides := make(sqlgen.Overrides)
+	for _, file := range files {
+		for column, typ := range file.Overrides {
+			overrides[column] = typ
+		}
+	}
+
+	generated := make(map[string][]byte)
+	err = withScratchSchema(ctx, runCfg, func(conn *sql.DB, schema *pgschema.Schema) error {
+		if err := sqlgen.Relax(ctx, conn, schema); err != nil {
+			return err
+		}
+
+		models, err := sqlgen.RenderModels(pkg, schema, overrides)
+		if err != nil {
+			return err
+		}
+		generated["models.gen.go"] = models
+
+		for _, file := range files {
+			if err := sqlgen.Infer(ctx, conn, schema, file); err != nil {
+				return err
+			}
+
+			queries, err := sqlgen.RenderQueries(pkg, schema, file, overrides)
+			if err != nil {
+				return err
+			}
+			generated[file.Name+"_queries.gen.go"] = queries
+		}
+
+		return nil
+	})
+	return generated, err
+}
+
+// staleFiles returns names of generated files which differ from the files in the directory,
+// including generated files in the directory which are not generated anymore.
+func staleFiles(dir string, generated map[string][]byte) ([]string, error) {
+	var stale []string
+	for name, content := range generated {
+		existing, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		if !bytes.Equal(existing, content) {
+			stale = append(stale, name)
+		}
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.gen.go"))
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range paths {
+		if _, ok := generated[filepath.Base(path)]; !ok {
+			stale = append(stale, filepath.Base(path))
+		}
+	}
+
+	sort.Strings(stale)
+	return stale, nil
+}
+
+// parseQueryFiles parses all sql files in the directory.
+func parseQueryFiles(dir string) ([]*sqlgen.File, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.sql"))
+	if err != nil {
+		return nil, err
+	}
+
+	var files []*sqlgen.File
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		file, err := sqlgen.Parse(strings.TrimSuffix(filepath.Base(path), ".sql"), string(content))
+		if err != nil {
+			return nil, err
+		}
+
+		files = append(files, file)
+	}
+
+	return files, nil
+}
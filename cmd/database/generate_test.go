@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"project_template/pkg/config"
+)
+
+// TestGenerated checks that generated files of the database package are what generate produces
+// from its queries and migrations, like generate --check does.
+func TestGenerated(t *testing.T) {
+	t.Run("Postgres", func(t *testing.T) {
+		ctx := context.Background()
+
+		runCfg := Config{}
+		require.NoError(t, config.ReadConfig(&runCfg))
+
+		generated, err := generateFiles(ctx, runCfg, filepath.Join("..", "..", "database", "queries"), "database")
+		require.NoError(t, err)
+
+		stale, err := staleFiles(filepath.Join("..", "..", "database"), generated)
+		require.NoError(t, err)
+		require.Empty(t, stale, "generated files are out of date, run go generate ./database")
+	})
+}
+
+func TestStaleFiles(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "models.gen.go"), []byte("models"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "dummy_queries.gen.go"), []byte("old"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "removed_queries.gen.go"), []byte("removed"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "dummy.go"), []byte("handwritten"), 0644))
+
+	stale, err := staleFiles(dir, map[string][]byte{
+		"models.gen.go":        []byte("models"),
+		"dummy_queries.gen.go": []byte("new"),
+		"added_queries.gen.go": []byte("added"),
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"added_queries.gen.go", "dummy_queries.gen.go", "removed_queries.gen.go"}, stale)
+}
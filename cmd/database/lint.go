@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"project_template/database"
+	"project_template/pkg/config"
+	"project_template/pkg/logger"
+	"project_template/pkg/logger/zaplog"
+	"project_template/pkg/migrationlint"
+)
+
+// lint commands.
+var (
+	// check migrations for mistakes and dangerous changes.
+	lintMigrationsCmd = &cobra.Command{
+		Use:         "lint-migrations",
+		Short:       "checks migrations for mistakes and dangerous schema changes",
+		Args:        cobra.NoArgs,
+		RunE:        cmdLintMigrations,
+		Annotations: map[string]string{"type": "run"},
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(lintMigrationsCmd)
+}
+
+// migrationsFS returns migrations directory by path, or embedded migrations if path is empty.
+func migrationsFS(migrationsPath string) fs.FS {
+	if migrationsPath == "" {
+		return database.EmbeddedMigrations()
+	}
+
+	return os.DirFS(migrationsPath)
+}
+
+// lintMigrations prints migration issues and returns an error if there are any besides warnings.
+func lintMigrations(log logger.Logger, migrationsPath string) error {
+	issues, err := migrationlint.Lint(migrationsFS(migrationsPath))
+	if err != nil {
+		log.Error("could not lint migrations", Error.Wrap(err))
+		return Error.Wrap(err)
+	}
+
+	var errorsCount int
+	for _, issue := range issues {
+		fmt.Println(issue)
+		if !issue.Warning {
+			errorsCount++
+		}
+	}
+
+	if errorsCount > 0 {
+		err = Error.New("%d migration issues found", errorsCount)
+		log.Error("migrations have issues, fix them or suppress with a \"-- lint:ignore <rule>\" comment", err)
+		return err
+	}
+
+	return nil
+}
+
+// cmdLintMigrations checks migrations for mistakes and dangerous schema changes.
+func cmdLintMigrations(cmd *cobra.Command, args []string) (err error) {
+	log := zaplog.NewLog()
+
+	// database credentials are not needed to lint migrations.
+	lintCfg := database.MigrationsConfig{}
+	err = config.ReadConfig(&lintCfg)
+	if err != nil {
+		log.Error("could not read config", Error.Wrap(err))
+		return Error.Wrap(err)
+	}
+
+	return lintMigrations(log, lintCfg.MigrationsPath)
+}
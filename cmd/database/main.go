@@ -1,15 +1,12 @@
 package main
 
 import (
-	"context"
 	"fmt"
-	"io/ioutil"
 	"os"
 	"project_template"
 	"project_template/pkg/config"
-	"regexp"
-	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/zeebo/errs"
@@ -17,6 +14,7 @@ import (
 	"project_template/database"
 	"project_template/pkg/fileutils"
 	"project_template/pkg/logger/zaplog"
+	"project_template/pkg/migrationlint"
 )
 
 // Error is a default error type for database cli.
@@ -24,7 +22,7 @@ var Error = errs.Class("database cli error")
 
 // Config contains configurable values for the migration mechanism.
 type Config struct {
-	MigrationsPath string `env:"DB_MIGRATIONS_PATH" validate:"required"`
+	database.MigrationsConfig
 
 	project_template.DBConfig
 }
@@ -39,18 +37,15 @@ var (
 
 	// create database schema.
 	createMigrationCmd = &cobra.Command{
-		Use:         "create-migration",
+		Use:         "create-migration [migration_name] [key=value...]",
 		Short:       "creates a new migration",
 		RunE:        cmdCreateMigration,
 		Annotations: map[string]string{"type": "run"},
 	}
 
-	// execute database migrations.
-	migrateCmd = &cobra.Command{
-		Use:         "migrate",
-		Short:       "executes migrations",
-		RunE:        cmdMigrate,
-		Annotations: map[string]string{"type": "run"},
+	createMigrationFlags struct {
+		Timestamp bool
+		Template  string
 	}
 
 	runCfg Config
@@ -59,6 +54,10 @@ var (
 func init() {
 	rootCmd.AddCommand(createMigrationCmd)
 	rootCmd.AddCommand(migrateCmd)
+
+	createMigrationCmd.Flags().BoolVar(&createMigrationFlags.Timestamp, "timestamp", false, "uses current UTC time as version instead of the next number")
+	createMigrationCmd.Flags().StringVar(&createMigrationFlags.Template, "template", defaultMigrationTemplate,
+		"template of migration files: "+strings.Join(templateNames(), ", "))
 }
 
 func main() {
@@ -68,7 +67,7 @@ func main() {
 	}
 }
 
-// cmdCreateSchema creates schema for all tables and databases.
+// cmdCreateMigration creates up and down migration files from a template.
 func cmdCreateMigration(cmd *cobra.Command, args []string) (err error) {
 	log := zaplog.NewLog()
 
@@ -77,48 +76,65 @@ func cmdCreateMigration(cmd *cobra.Command, args []string) (err error) {
 		return Error.New("invalid arguments")
 	}
 
-	runCfg := Config{}
+	if !migrationlint.ValidTitle(args[0]) {
+		err = Error.New("migration name %q should contain only letters, digits and underscores", args[0])
+		log.Error("invalid migration name", err)
+		return err
+	}
+
+	vars, err := parseTemplateVars(args[1:])
+	if err != nil {
+		log.Error("invalid template variables", err)
+		return err
+	}
+	vars["migration"] = args[0]
+
+	// database credentials are not needed to create migrations.
+	runCfg := database.MigrationsConfig{}
 	err = config.ReadConfig(&runCfg)
 	if err != nil {
 		log.Error("could not read config", Error.Wrap(err))
 		return Error.Wrap(err)
 	}
 
-	fExtExpr := regexp.MustCompile(".sql$")
-	curVer := 0
+	if runCfg.MigrationsPath == "" {
+		log.Error("DB_MIGRATIONS_PATH is required to create a migration", Error.New("invalid config"))
+		return Error.New("invalid config")
+	}
 
-	files, err := ioutil.ReadDir(runCfg.MigrationsPath)
+	files, issues, err := migrationlint.Files(os.DirFS(runCfg.MigrationsPath))
 	if err != nil {
 		log.Error("could not read migrations path", Error.Wrap(err))
-		return err
+		return Error.Wrap(err)
+	}
+	for _, issue := range issues {
+		log.Warn(issue.String())
 	}
-	for _, f := range files {
-		if f.IsDir() {
-			continue
-		}
-
-		fName := f.Name()
-		r := fExtExpr.MatchString(fName)
-		if r == false {
-			continue
-		}
 
-		parts := strings.Split(fName, "_")
-		ver, err := strconv.Atoi(parts[0])
-		if err != nil {
-			// Looks like that file name is without a numeric prefix.
-			continue
-		}
-		if ver > curVer {
-			curVer = ver
-		}
+	var curVer uint64
+	if len(files) > 0 {
+		curVer = files[len(files)-1].Version
 	}
 
+	// timestamp versions don't collide when migrations are added in parallel branches.
 	migName := fmt.Sprintf("%06d_%s", curVer+1, args[0])
+	if createMigrationFlags.Timestamp || migrationlint.IsTimestamp(curVer) {
+		migName = time.Now().UTC().Format(migrationlint.TimestampFormat) + "_" + args[0]
+	}
+
 	fNames := [2]string{
 		migName + ".up.sql",
 		migName + ".down.sql",
 	}
+	var contents [2][]byte
+	for i, direction := range []string{"up", "down"} {
+		contents[i], err = renderMigration(createMigrationFlags.Template, direction, vars)
+		if err != nil {
+			log.Error("could not render migration template", err)
+			return err
+		}
+	}
+
 	for _, fName := range fNames {
 		isExist, err := fileutils.IsFileExist(runCfg.MigrationsPath, fName)
 		if err != nil {
@@ -132,8 +148,8 @@ func cmdCreateMigration(cmd *cobra.Command, args []string) (err error) {
 		}
 	}
 
-	for _, fName := range fNames {
-		if err := fileutils.CreateFile(runCfg.MigrationsPath, fName); err != nil {
+	for i, fName := range fNames {
+		if err := fileutils.WriteFile(runCfg.MigrationsPath, fName, contents[i]); err != nil {
 			errMsg := fmt.Sprintf("could not create file '%s'", fName)
 			log.Error(errMsg, Error.Wrap(err))
 		} else {
@@ -143,49 +159,3 @@ func cmdCreateMigration(cmd *cobra.Command, args []string) (err error) {
 
 	return nil
 }
-
-// cmdMigrate executes migrations by path in database.
-func cmdMigrate(cmd *cobra.Command, args []string) (err error) {
-	var isUp bool
-	ctx := context.Background()
-	log := zaplog.NewLog()
-
-	if len(args) == 0 {
-		log.Error("at least 1 argument is required", Error.New("invalid arguments"))
-		return Error.New("invalid arguments")
-	}
-
-	runCfg := Config{}
-	err = config.ReadConfig(&runCfg)
-	if err != nil {
-		log.Error("could not read config", Error.Wrap(err))
-		return Error.Wrap(err)
-	}
-
-	db, err := database.New(runCfg.DBConfig)
-	if err != nil {
-		log.Error("starting database error", Error.Wrap(err))
-		return Error.Wrap(err)
-	}
-	defer func() {
-		err = Error.Wrap(errs.Combine(err, db.Close()))
-	}()
-
-	switch args[0] {
-	case "up":
-		isUp = true
-		if err = db.ExecuteMigrations(ctx, runCfg.MigrationsPath, isUp); err != nil {
-			log.Error("migrations up error", Error.Wrap(err))
-		}
-	case "down":
-		isUp = false
-		if err = db.ExecuteMigrations(ctx, runCfg.MigrationsPath, isUp); err != nil {
-			log.Error("migrations down error", Error.Wrap(err))
-		}
-	default:
-		err = errs.New("invalid arguments")
-		log.Error("invalid 1 argument", Error.Wrap(err))
-	}
-
-	return Error.Wrap(err)
-}
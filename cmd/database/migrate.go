@@ -0,0 +1,253 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/zeebo/errs"
+
+	"project_template"
+	"project_template/database"
+	"project_template/pkg/config"
+	"project_template/pkg/logger"
+	"project_template/pkg/logger/zaplog"
+)
+
+// migrate commands.
+var (
+	// execute database migrations.
+	migrateCmd = &cobra.Command{
+		Use:   "migrate",
+		Short: "executes migrations",
+	}
+
+	migrateUpCmd = &cobra.Command{
+		Use:         "up",
+		Short:       "applies all pending migrations",
+		Args:        cobra.NoArgs,
+		RunE:        cmdMigrateUp,
+		Annotations: map[string]string{"type": "run"},
+	}
+
+	migrateDownCmd = &cobra.Command{
+		Use:         "down",
+		Short:       "rolls back all applied migrations",
+		Args:        cobra.NoArgs,
+		RunE:        cmdMigrateDown,
+		Annotations: map[string]string{"type": "run"},
+	}
+
+	migrateStatusCmd = &cobra.Command{
+		Use:         "status",
+		Short:       "prints current version, dirty flag and pending migrations",
+		Args:        cobra.NoArgs,
+		RunE:        cmdMigrateStatus,
+		Annotations: map[string]string{"type": "run"},
+	}
+
+	migrateGotoCmd = &cobra.Command{
+		Use:         "goto [version]",
+		Short:       "migrates up or down to the given version",
+		Args:        cobra.ExactArgs(1),
+		RunE:        cmdMigrateGoto,
+		Annotations: map[string]string{"type": "run"},
+	}
+
+	// flag parsing is disabled to accept negative numbers, e.g. "steps -1".
+	migrateStepsCmd = &cobra.Command{
+		Use:                "steps [+N|-N]",
+		Short:              "applies N next migrations or rolls back N last ones",
+		Args:               cobra.ExactArgs(1),
+		DisableFlagParsing: true,
+		RunE:               cmdMigrateSteps,
+		Annotations:        map[string]string{"type": "run"},
+	}
+
+	// flag parsing is disabled to accept version -1, which means no migration is applied.
+	migrateForceCmd = &cobra.Command{
+		Use:                "force [version]",
+		Short:              "sets the version without running migrations and resets the dirty flag",
+		Args:               cobra.ExactArgs(1),
+		DisableFlagParsing: true,
+		RunE:               cmdMigrateForce,
+		Annotations:        map[string]string{"type": "run"},
+	}
+
+	migrateUpFlags struct {
+		DryRun   bool
+		SkipLint bool
+	}
+)
+
+func init() {
+	migrateCmd.AddCommand(migrateUpCmd)
+	migrateCmd.AddCommand(migrateDownCmd)
+	migrateCmd.AddCommand(migrateStatusCmd)
+	migrateCmd.AddCommand(migrateGotoCmd)
+	migrateCmd.AddCommand(migrateStepsCmd)
+	migrateCmd.AddCommand(migrateForceCmd)
+
+	migrateUpCmd.Flags().BoolVar(&migrateUpFlags.DryRun, "dry-run", false, "prints sql of pending migrations without executing it")
+	migrateUpCmd.Flags().BoolVar(&migrateUpFlags.SkipLint, "skip-lint", false, "applies migrations even if linter reports issues")
+}
+
+// withDatabase reads config, connects to the database and calls fn with it.
+func withDatabase(fn func(ctx context.Context, log logger.Logger, runCfg Config, db project_template.DB) error) (err error) {
+	ctx := context.Background()
+	log := zaplog.NewLog()
+
+	runCfg := Config{}
+	err = config.ReadConfig(&runCfg)
+	if err != nil {
+		log.Error("could not read config", Error.Wrap(err))
+		return Error.Wrap(err)
+	}
+
+	db, err := database.New(runCfg.DBConfig)
+	if err != nil {
+		log.Error("starting database error", Error.Wrap(err))
+		return Error.Wrap(err)
+	}
+	defer func() {
+		err = Error.Wrap(errs.Combine(err, db.Close()))
+	}()
+
+	return Error.Wrap(fn(ctx, log, runCfg, db))
+}
+
+// cmdMigrateUp applies all pending migrations or prints them in dry-run mode.
+func cmdMigrateUp(cmd *cobra.Command, args []string) error {
+	return withDatabase(func(ctx context.Context, log logger.Logger, runCfg Config, db project_template.DB) error {
+		migrations := db.Migrations(runCfg.MigrationsPath)
+
+		if !migrateUpFlags.SkipLint {
+			if err := lintMigrations(log, runCfg.MigrationsPath); err != nil {
+				return err
+			}
+		}
+
+		if !migrateUpFlags.DryRun {
+			err := migrations.Up(ctx)
+			if err != nil {
+				log.Error("migrations up error", Error.Wrap(err))
+			}
+			return err
+		}
+
+		pending, err := migrations.Pending(ctx)
+		if err != nil {
+			log.Error("could not get pending migrations", Error.Wrap(err))
+			return err
+		}
+
+		for _, migration := range pending {
+			fmt.Printf("-- migration %d: %s\n%s\n", migration.Version, migration.Name, migration.SQL)
+		}
+
+		return nil
+	})
+}
+
+// cmdMigrateDown rolls back all applied migrations.
+func cmdMigrateDown(cmd *cobra.Command, args []string) error {
+	return withDatabase(func(ctx context.Context, log logger.Logger, runCfg Config, db project_template.DB) error {
+		migrations := db.Migrations(runCfg.MigrationsPath)
+
+		err := migrations.Down(ctx)
+		if err != nil {
+			log.Error("migrations down error", Error.Wrap(err))
+		}
+		return err
+	})
+}
+
+// cmdMigrateStatus prints the current schema version and pending migrations.
+func cmdMigrateStatus(cmd *cobra.Command, args []string) error {
+	return withDatabase(func(ctx context.Context, log logger.Logger, runCfg Config, db project_template.DB) error {
+		migrations := db.Migrations(runCfg.MigrationsPath)
+
+		status, err := migrations.Status(ctx)
+		if err != nil {
+			log.Error("could not get migrations status", Error.Wrap(err))
+			return err
+		}
+
+		fmt.Printf("version: %d\n", status.Version)
+		fmt.Printf("dirty: %t\n", status.Dirty)
+		fmt.Printf("pending: %d\n", len(status.Pending))
+		for _, migration := range status.Pending {
+			fmt.Printf("  %d %s\n", migration.Version, migration.Name)
+		}
+
+		return nil
+	})
+}
+
+// cmdMigrateGoto migrates up or down to the given version.
+func cmdMigrateGoto(cmd *cobra.Command, args []string) error {
+	version, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		return Error.New("invalid version %q", args[0])
+	}
+
+	return withDatabase(func(ctx context.Context, log logger.Logger, runCfg Config, db project_template.DB) error {
+		migrations := db.Migrations(runCfg.MigrationsPath)
+
+		err := migrations.Goto(ctx, uint(version))
+		if err != nil {
+			log.Error("migrations goto error", Error.Wrap(err))
+		}
+		return err
+	})
+}
+
+// cmdMigrateSteps applies or rolls back the given number of migrations.
+func cmdMigrateSteps(cmd *cobra.Command, args []string) error {
+	if isHelp(args[0]) {
+		return cmd.Help()
+	}
+
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n == 0 {
+		return Error.New("invalid number of steps %q", args[0])
+	}
+
+	return withDatabase(func(ctx context.Context, log logger.Logger, runCfg Config, db project_template.DB) error {
+		migrations := db.Migrations(runCfg.MigrationsPath)
+
+		err := migrations.Steps(ctx, n)
+		if err != nil {
+			log.Error("migrations steps error", Error.Wrap(err))
+		}
+		return err
+	})
+}
+
+// cmdMigrateForce sets the schema version and resets the dirty flag.
+func cmdMigrateForce(cmd *cobra.Command, args []string) error {
+	if isHelp(args[0]) {
+		return cmd.Help()
+	}
+
+	version, err := strconv.Atoi(args[0])
+	if err != nil || version < -1 {
+		return Error.New("invalid version %q", args[0])
+	}
+
+	return withDatabase(func(ctx context.Context, log logger.Logger, runCfg Config, db project_template.DB) error {
+		migrations := db.Migrations(runCfg.MigrationsPath)
+
+		err := migrations.Force(ctx, version)
+		if err != nil {
+			log.Error("migrations force error", Error.Wrap(err))
+		}
+		return err
+	})
+}
+
+// isHelp checks if argument is a help flag, for commands with disabled flag parsing.
+func isHelp(arg string) bool {
+	return arg == "-h" || arg == "--help"
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"project_template"
+	"project_template/dummy"
+	"project_template/pkg/logger"
+)
+
+// seed commands.
+var (
+	// load fixtures into the database.
+	seedCmd = &cobra.Command{
+		Use:         "seed [file|directory...]",
+		Short:       "loads yaml and json fixtures and synthetic data through domain services",
+		RunE:        cmdSeed,
+		Annotations: map[string]string{"type": "run"},
+	}
+
+	seedFlags struct {
+		Count int
+	}
+)
+
+// defaultFixturesPath is a directory fixtures are loaded from if no paths are given.
+const defaultFixturesPath = "database/fixtures"
+
+// syntheticNamespace is used to derive stable ids of synthetic dummies, so re-running seed updates them instead of duplicating.
+var syntheticNamespace = uuid.MustParse("0f6d3c1e-5a0b-4b8e-9a51-3c2f1d7e8b40")
+
+func init() {
+	rootCmd.AddCommand(seedCmd)
+
+	seedCmd.Flags().IntVar(&seedFlags.Count, "count", 0, "number of synthetic dummies to generate in addition to fixtures")
+}
+
+// fixtures is the content of a fixture file.
+type fixtures struct {
+	Dummies []dummyFixture `json:"dummies" yaml:"dummies"`
+}
+
+// dummyFixture describes a dummy entity in a fixture file.
+type dummyFixture struct {
+	ID     uuid.UUID    `json:"id" yaml:"id"`
+	Title  string       `json:"title" yaml:"title"`
+	Status dummy.Status `json:"status" yaml:"status"`
+}
+
+// cmdSeed loads fixtures and synthetic dummies. Entities are upserted by id, so the command can be re-run.
+func cmdSeed(cmd *cobra.Command, args []string) error {
+	if seedFlags.Count < 0 {
+		return Error.New("count should not be negative")
+	}
+
+	paths := args
+	if len(paths) == 0 {
+		paths = []string{defaultFixturesPath}
+	}
+
+	files, err := fixtureFiles(paths)
+	if err != nil {
+		return Error.Wrap(err)
+	}
+
+	var all fixtures
+	for _, file := range files {
+		loaded, err := loadFixtures(file)
+		if err != nil {
+			return Error.Wrap(err)
+		}
+		all.Dummies = append(all.Dummies, loaded.Dummies...)
+	}
+	all.Dummies = append(all.Dummies, syntheticDummies(seedFlags.Count)...)
+
+	return withDatabase(func(ctx context.Context, log logger.Logger, runCfg Config, db project_template.DB) error {
+		return seedDummies(ctx, log, dummy.NewService(db.Dummy()), all.Dummies)
+	})
+}
+
+// seedDummies upserts dummies through the service and prints how many were created and updated.
+func seedDummies(ctx context.Context, log logger.Logger, service *dummy.Service, dummies []dummyFixture) error {
+	var created, unchanged int
+	for _, fixture := range dummies {
+		isNew, err := service.Upsert(ctx, dummy.Dummy{
+			ID:     fixture.ID,
+			Title:  fixture.Title,
+			Status: fixture.Status,
+		})
+		if err != nil {
+			log.Error(fmt.Sprintf("could not seed dummy %s", fixture.ID), Error.Wrap(err))
+			return err
+		}
+
+		if isNew {
+			created++
+		} else {
+			unchanged++
+		}
+	}
+
+	fmt.Printf("dummies: %d created, %d already existed\n", created, unchanged)
+	return nil
+}
+
+// fixtureFiles returns yaml and json files of the paths, directories are read non-recursively in name order.
+func fixtureFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+
+		var names []string
+		for _, entry := range entries {
+			if !entry.IsDir() && isFixtureFile(entry.Name()) {
+				names = append(names, filepath.Join(path, entry.Name()))
+			}
+		}
+		sort.Strings(names)
+		files = append(files, names...)
+	}
+
+	return files, nil
+}
+
+// isFixtureFile checks if the file has a supported extension.
+func isFixtureFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".yaml", ".yml", ".json":
+		return true
+	default:
+		return false
+	}
+}
+
+// loadFixtures reads a yaml or json fixture file, unknown fields are rejected.
+func loadFixtures(path string) (fixtures, error) {
+	var result fixtures
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return result, err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(content))
+		decoder.KnownFields(true)
+		err = decoder.Decode(&result)
+	case ".json":
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&result)
+	default:
+		return result, Error.New("%s: unsupported fixture format", path)
+	}
+	if err != nil {
+		return result, Error.New("%s: %v", path, err)
+	}
+
+	for i, fixture := range result.Dummies {
+		if fixture.ID == uuid.Nil {
+			return result, Error.New("%s: dummy #%d: id is required", path, i+1)
+		}
+	}
+
+	return result, nil
+}
+
+// syntheticDummies generates count dummies with ids derived from their number.
+func syntheticDummies(count int) []dummyFixture {
+	dummies := make([]dummyFixture, 0, count)
+	for i := 1; i <= count; i++ {
+		status := dummy.StatusActive
+		if i%2 == 0 {
+			status = dummy.StatusInactive
+		}
+
+		dummies = append(dummies, dummyFixture{
+			ID:     uuid.NewSHA1(syntheticNamespace, []byte(fmt.Sprintf("dummy-%d", i))),
+			Title:  fmt.Sprintf("Synthetic dummy %d", i),
+			Status: status,
+		})
+	}
+
+	return dummies
+}
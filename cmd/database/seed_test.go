@@ -0,0 +1,63 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"project_template/dummy"
+)
+
+func TestLoadFixtures(t *testing.T) {
+	dir := t.TempDir()
+
+	yamlPath := filepath.Join(dir, "a.yaml")
+	require.NoError(t, os.WriteFile(yamlPath, []byte("dummies:\n  - id: 7c9e6679-7425-40de-944b-e07fc1f90ae7\n    title: yaml\n    status: 1\n"), 0644))
+
+	jsonPath := filepath.Join(dir, "b.json")
+	require.NoError(t, os.WriteFile(jsonPath, []byte(`{"dummies": [{"id": "a1d4c3b2-5e6f-4a7b-8c9d-0e1f2a3b4c5d", "title": "json", "status": 0}]}`), 0644))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("not a fixture"), 0644))
+
+	files, err := fixtureFiles([]string{dir})
+	require.NoError(t, err)
+	require.Equal(t, []string{yamlPath, jsonPath}, files)
+
+	loaded, err := loadFixtures(yamlPath)
+	require.NoError(t, err)
+	require.Equal(t, []dummyFixture{{ID: uuid.MustParse("7c9e6679-7425-40de-944b-e07fc1f90ae7"), Title: "yaml", Status: dummy.StatusActive}}, loaded.Dummies)
+
+	loaded, err = loadFixtures(jsonPath)
+	require.NoError(t, err)
+	require.Equal(t, []dummyFixture{{ID: uuid.MustParse("a1d4c3b2-5e6f-4a7b-8c9d-0e1f2a3b4c5d"), Title: "json", Status: dummy.StatusInactive}}, loaded.Dummies)
+
+	t.Run("unknown field", func(t *testing.T) {
+		path := filepath.Join(dir, "unknown.yaml")
+		require.NoError(t, os.WriteFile(path, []byte("dummies:\n  - id: 7c9e6679-7425-40de-944b-e07fc1f90ae7\n    name: typo\n"), 0644))
+
+		_, err := loadFixtures(path)
+		require.Error(t, err)
+	})
+
+	t.Run("missing id", func(t *testing.T) {
+		path := filepath.Join(dir, "noid.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"dummies": [{"title": "json"}]}`), 0644))
+
+		_, err := loadFixtures(path)
+		require.Error(t, err)
+	})
+}
+
+func TestSyntheticDummiesAreStable(t *testing.T) {
+	first := syntheticDummies(3)
+	require.Len(t, first, 3)
+	require.Equal(t, first, syntheticDummies(3))
+	require.Equal(t, first[:2], syntheticDummies(2))
+
+	for _, fixture := range first {
+		require.NoError(t, dummy.Dummy{ID: fixture.ID, Title: fixture.Title, Status: fixture.Status}.Validate())
+	}
+}
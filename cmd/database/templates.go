@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"embed"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// migrationTemplates contains templates of up and down migrations,
+// named "{template}.up.sql.tmpl" and "{template}.down.sql.tmpl".
+//
+//go:embed templates/*.sql.tmpl
+var migrationTemplates embed.FS
+
+// defaultMigrationTemplate is used when no template is specified.
+const defaultMigrationTemplate = "empty"
+
+// templateNames returns names of all available migration templates.
+func templateNames() []string {
+	entries, err := migrationTemplates.ReadDir("templates")
+	if err != nil {
+		panic(err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), ".up.sql.tmpl")
+		if name != entry.Name() {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// parseTemplateVars parses template variables given as "key=value" arguments.
+func parseTemplateVars(args []string) (map[string]string, error) {
+	vars := make(map[string]string)
+	for _, arg := range args {
+		parts := strings.SplitN(arg, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, Error.New("template variable %q should have key=value format", arg)
+		}
+
+		vars[parts[0]] = parts[1]
+	}
+
+	return vars, nil
+}
+
+// renderMigration renders up or down migration from the named template.
+func renderMigration(name, direction string, vars map[string]string) ([]byte, error) {
+	tmpl, err := template.New("").Option("missingkey=error").ParseFS(migrationTemplates, "templates/"+name+"."+direction+".sql.tmpl")
+	if err != nil {
+		return nil, Error.New("unknown template %q, available templates: %s", name, strings.Join(templateNames(), ", "))
+	}
+
+	var b bytes.Buffer
+	if err = tmpl.ExecuteTemplate(&b, name+"."+direction+".sql.tmpl", vars); err != nil {
+		return nil, Error.Wrap(err)
+	}
+
+	return b.Bytes(), nil
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"project_template/pkg/logger/zaplog"
+)
+
+func TestCreatedMigrationsPassLint(t *testing.T) {
+	defer func(template string) { createMigrationFlags.Template = template }(createMigrationFlags.Template)
+
+	for _, name := range templateNames() {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			t.Setenv("DB_MIGRATIONS_PATH", dir)
+
+			createMigrationFlags.Template = name
+			err := cmdCreateMigration(nil, []string{"init", "name=foo", "table=bar", "columns=id", "type=TEXT"})
+			require.NoError(t, err)
+
+			require.NoError(t, lintMigrations(zaplog.NewLog(), dir))
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/zeebo/errs"
+
+	"project_template"
+	"project_template/database"
+	"project_template/dummy"
+	"project_template/pkg/config"
+	"project_template/pkg/logger"
+	"project_template/pkg/logger/zaplog"
+)
+
+// dummy commands.
+var (
+	dummyCmd = &cobra.Command{
+		Use:   "dummy",
+		Short: "dummy related commands",
+	}
+
+	dummyImportCmd = &cobra.Command{
+		Use:         "import [file]",
+		Short:       "imports dummies from a csv or ndjson file, stdin if no file is given",
+		Args:        cobra.MaximumNArgs(1),
+		RunE:        cmdDummyImport,
+		Annotations: map[string]string{"type": "run"},
+	}
+
+	dummyExportCmd = &cobra.Command{
+		Use:         "export",
+		Short:       "exports all dummies in csv or ndjson format",
+		Args:        cobra.NoArgs,
+		RunE:        cmdDummyExport,
+		Annotations: map[string]string{"type": "run"},
+	}
+
+	dummyImportFlags struct {
+		Format string
+	}
+
+	dummyExportFlags struct {
+		Format string
+		Output string
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(dummyCmd)
+	dummyCmd.AddCommand(dummyImportCmd)
+	dummyCmd.AddCommand(dummyExportCmd)
+
+	dummyImportCmd.Flags().StringVar(&dummyImportFlags.Format, "format", "", "input format: csv or ndjson, detected by file extension if empty")
+	dummyExportCmd.Flags().StringVar(&dummyExportFlags.Format, "format", string(dummy.FormatNDJSON), "output format: csv or ndjson")
+	dummyExportCmd.Flags().StringVar(&dummyExportFlags.Output, "output", "", "file to write to, stdout if empty")
+}
+
+// withDummyService connects to the database and calls fn with dummy service.
+func withDummyService(fn func(ctx context.Context, log logger.Logger, service *dummy.Service) error) (err error) {
+	ctx := context.Background()
+	log := zaplog.NewLog()
+
+	dbCfg := project_template.DBConfig{}
+	if err = config.ReadConfig(&dbCfg); err != nil {
+		log.Error("could not read config", Error.Wrap(err))
+		return Error.Wrap(err)
+	}
+
+	db, err := database.New(dbCfg)
+	if err != nil {
+		log.Error("could not connect to database", Error.Wrap(err))
+		return Error.Wrap(err)
+	}
+	defer func() {
+		err = Error.Wrap(errs.Combine(err, db.Close()))
+	}()
+
+	return fn(ctx, log, dummy.NewService(db.Dummy()))
+}
+
+func cmdDummyImport(cmd *cobra.Command, args []string) error {
+	var (
+		r    io.Reader = os.Stdin
+		name           = dummyImportFlags.Format
+	)
+
+	if len(args) > 0 && args[0] != "-" {
+		f, err := os.Open(args[0])
+		if err != nil {
+			return Error.Wrap(err)
+		}
+		defer func() { _ = f.Close() }()
+
+		r = f
+		if name == "" {
+			name = strings.TrimPrefix(filepath.Ext(args[0]), ".")
+		}
+	}
+
+	format, err := dummy.ParseFormat(name)
+	if err != nil {
+		return Error.Wrap(err)
+	}
+
+	return withDummyService(func(ctx context.Context, log logger.Logger, service *dummy.Service) error {
+		result, err := service.Import(ctx, r, format)
+		if err != nil {
+			log.Error("could not import dummies", Error.Wrap(err))
+			return err
+		}
+
+		fmt.Printf("imported: %d\nfailed: %d\n", result.Imported, result.Failed)
+		for _, rowErr := range result.Errors {
+			fmt.Printf("line %d: %s\n", rowErr.Line, rowErr.Error)
+		}
+
+		if result.Failed > 0 {
+			return Error.New("%d rows were not imported", result.Failed)
+		}
+		return nil
+	})
+}
+
+func cmdDummyExport(cmd *cobra.Command, args []string) error {
+	format, err := dummy.ParseFormat(dummyExportFlags.Format)
+	if err != nil {
+		return Error.Wrap(err)
+	}
+
+	return withDummyService(func(ctx context.Context, log logger.Logger, service *dummy.Service) (err error) {
+		var w io.Writer = os.Stdout
+		if dummyExportFlags.Output != "" {
+			f, err := os.Create(dummyExportFlags.Output)
+			if err != nil {
+				log.Error("could not create output file", Error.Wrap(err))
+				return err
+			}
+			defer func() {
+				err = errs.Combine(err, f.Close())
+			}()
+
+			w = f
+		}
+
+		if err = service.Export(ctx, w, format); err != nil {
+			log.Error("could not export dummies", Error.Wrap(err))
+			return err
+		}
+
+		return nil
+	})
+}
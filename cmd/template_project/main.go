@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"io"
 	"os"
 	"project_template"
 	"project_template/database"
@@ -20,6 +21,7 @@ var Error = errs.Class("template_project cli error")
 type Config struct {
 	project_template.Config
 	project_template.DBConfig
+	database.MigrationsConfig
 }
 
 // commands.
@@ -36,10 +38,37 @@ var (
 		RunE:        cmdRun,
 		Annotations: map[string]string{"type": "run"},
 	}
+
+	configCmd = &cobra.Command{
+		Use:   "config",
+		Short: "config related commands",
+	}
+
+	configDocsCmd = &cobra.Command{
+		Use:         "docs",
+		Short:       "generates config documentation or a sample .env file",
+		RunE:        cmdConfigDocs,
+		Annotations: map[string]string{"type": "run"},
+	}
+
+	runFlags struct {
+		Migrate bool
+	}
+
+	configDocsFlags struct {
+		Format string
+		Output string
+	}
 )
 
 func init() {
 	rootCmd.AddCommand(runCmd)
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configDocsCmd)
+
+	runCmd.Flags().BoolVar(&runFlags.Migrate, "migrate", false, "applies pending migrations before start")
+	configDocsCmd.Flags().StringVar(&configDocsFlags.Format, "format", "markdown", "output format: markdown or env")
+	configDocsCmd.Flags().StringVar(&configDocsFlags.Output, "output", "", "file to write to, stdout if empty")
 }
 
 func main() {
@@ -69,6 +98,13 @@ func cmdRun(cmd *cobra.Command, args []string) error {
 		err = errs.Combine(err, db.Close())
 	}()
 
+	if runFlags.Migrate {
+		if err = db.Migrations(runCfg.MigrationsPath).Up(ctx); err != nil {
+			log.Error("could not apply migrations", Error.Wrap(err))
+			return Error.Wrap(err)
+		}
+	}
+
 	app, err := project_template.New(runCfg.Config, log, db)
 	if err != nil {
 		log.Error("could not start template_project service", Error.Wrap(err))
@@ -80,3 +116,47 @@ func cmdRun(cmd *cobra.Command, args []string) error {
 
 	return Error.Wrap(errs.Combine(runError, closeError))
 }
+
+// configSections lists all the config structs read by the project binaries.
+func configSections() []config.Section {
+	return []config.Section{
+		{Title: "Application", Config: Config{}},
+	}
+}
+
+// writeConfigDocs writes config documentation in the given format.
+func writeConfigDocs(w io.Writer, format string) error {
+	switch format {
+	case "markdown":
+		return config.WriteMarkdown(w, configSections()...)
+	case "env":
+		return config.WriteEnvDist(w, configSections()...)
+	default:
+		return Error.New("unknown format %q", format)
+	}
+}
+
+func cmdConfigDocs(cmd *cobra.Command, args []string) (err error) {
+	log := zaplog.NewLog()
+
+	var w io.Writer = os.Stdout
+	if configDocsFlags.Output != "" {
+		f, err := os.Create(configDocsFlags.Output)
+		if err != nil {
+			log.Error("could not create output file", Error.Wrap(err))
+			return Error.Wrap(err)
+		}
+		defer func() {
+			err = errs.Combine(err, f.Close())
+		}()
+
+		w = f
+	}
+
+	if err = writeConfigDocs(w, configDocsFlags.Format); err != nil {
+		log.Error("could not write config docs", Error.Wrap(err))
+		return Error.Wrap(err)
+	}
+
+	return nil
+}
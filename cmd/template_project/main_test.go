@@ -0,0 +1,29 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestConfigDocs fails when committed config docs don't match the config structs.
+// Run `go run ./cmd/template_project config docs` to regenerate them.
+func TestConfigDocs(t *testing.T) {
+	files := map[string]string{
+		"markdown": "../../docs/config.md",
+		"env":      "../../.env.dist",
+	}
+
+	for format, path := range files {
+		var generated bytes.Buffer
+		err := writeConfigDocs(&generated, format)
+		require.NoError(t, err)
+
+		committed, err := os.ReadFile(path)
+		require.NoError(t, err)
+
+		require.Equal(t, generated.String(), string(committed), "%s is stale, regenerate it with `config docs --format %s`", path, format)
+	}
+}
@@ -0,0 +1,261 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zeebo/errs"
+
+	"project_template/pkg/backoff"
+)
+
+// Error is an error class of the console client.
+var Error = errs.Class("console client error")
+
+// maxErrorBody limits the size of an error response which is read.
+const maxErrorBody = 64 << 10
+
+// Config contains configuration of the console api client.
+type Config struct {
+	URL          string        `env:"CONSOLE_CLIENT_URL" validate:"required" desc:"base url of the console server, e.g. https://console.example.com"`
+	Token        string        `env:"CONSOLE_CLIENT_TOKEN" secret:"true" desc:"bearer token sent in the Authorization header"`
+	Timeout      time.Duration `env:"CONSOLE_CLIENT_TIMEOUT" envDefault:"30s" desc:"timeout of a single attempt of a request"`
+	MaxRetries   int           `env:"CONSOLE_CLIENT_MAX_RETRIES" envDefault:"3" desc:"how many times a failed request is retried"`
+	MinRetryWait time.Duration `env:"CONSOLE_CLIENT_MIN_RETRY_WAIT" envDefault:"100ms" desc:"wait before the first retry, it's doubled for every next one"`
+	MaxRetryWait time.Duration `env:"CONSOLE_CLIENT_MAX_RETRY_WAIT" envDefault:"5s" desc:"maximum wait between retries, unless the server asks for a longer one"`
+	TLS          TLSConfig
+}
+
+// TLSConfig contains files of TLS, the system roots verify the server if CAFile isn't set.
+type TLSConfig struct {
+	CAFile   string `env:"CONSOLE_CLIENT_TLS_CA_FILE" desc:"path of the PEM encoded CA bundle which verifies the server certificate"`
+	CertFile string `env:"CONSOLE_CLIENT_TLS_CERT_FILE" validate:"required_with=KeyFile" desc:"path of the PEM encoded client certificate for mTLS"`
+	KeyFile  string `env:"CONSOLE_CLIENT_TLS_KEY_FILE" validate:"required_with=CertFile" desc:"path of the PEM encoded private key of the client certificate"`
+}
+
+// APIError is an error response of the api, Message is the error sent by the server.
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+// Error implements error.
+func (err *APIError) Error() string {
+	return fmt.Sprintf("%d %s: %s", err.StatusCode, http.StatusText(err.StatusCode), err.Message)
+}
+
+// StatusCode returns the status of the api error, it's 0 for other errors, e.g. network ones.
+func StatusCode(err error) int {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	return 0
+}
+
+// Client calls the console api. Requests are retried after network errors and 502, 503 and 504 responses
+// if they are idempotent, and after 429 responses, which are rejected before they are handled, in any case.
+type Client struct {
+	config  Config
+	baseURL *url.URL
+	http    *http.Client
+}
+
+// New is a constructor for the console api client, it fails if TLS files can't be loaded.
+func New(config Config) (*Client, error) {
+	baseURL, err := url.Parse(strings.TrimSuffix(config.URL, "/") + "/api/v0")
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	if baseURL.Scheme != "http" && baseURL.Scheme != "https" {
+		return nil, Error.New("url must be an absolute http or https url")
+	}
+
+	tlsConfig, err := config.TLS.load()
+	if err != nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	return &Client{
+		config:  config,
+		baseURL: baseURL,
+		http: &http.Client{
+			Transport: transport,
+			Timeout:   config.Timeout,
+		},
+	}, nil
+}
+
+// load returns TLS config with the CA and the client certificate from files.
+func (config TLSConfig) load() (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if config.CAFile != "" {
+		pem, err := os.ReadFile(config.CAFile)
+		if err != nil {
+			return nil, Error.Wrap(err)
+		}
+
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, Error.New("no certificates in %s", config.CAFile)
+		}
+	}
+
+	if config.CertFile != "" || config.KeyFile != "" {
+		certificate, err := tls.LoadX509KeyPair(config.CertFile, config.KeyFile)
+		if err != nil {
+			return nil, Error.Wrap(err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	return tlsConfig, nil
+}
+
+// Dummy returns the client of dummies.
+func (client *Client) Dummy() *DummyAPI {
+	return &DummyAPI{client: client}
+}
+
+// do sends the request with the json body, retrying it if it's allowed, and decodes the json response into result.
+// The body is ignored if result is nil.
+func (client *Client) do(ctx context.Context, method, path string, query url.Values, body, result interface{}) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return Error.Wrap(err)
+		}
+	}
+
+	endpoint := *client.baseURL
+	endpoint.Path += path
+	endpoint.RawQuery = query.Encode()
+
+	for attempt := 1; ; attempt++ {
+		resp, err := client.send(ctx, method, endpoint.String(), payload)
+
+		var wait time.Duration
+		retry := attempt <= client.config.MaxRetries
+		switch {
+		case err != nil:
+			retry = retry && idempotent(method) && ctx.Err() == nil
+		case resp.StatusCode == http.StatusTooManyRequests:
+			wait = retryAfter(resp)
+		case resp.StatusCode == http.StatusBadGateway || resp.StatusCode == http.StatusServiceUnavailable || resp.StatusCode == http.StatusGatewayTimeout:
+			retry = retry && idempotent(method)
+			wait = retryAfter(resp)
+		default:
+			retry = false
+		}
+
+		if !retry {
+			if err != nil {
+				return Error.Wrap(err)
+			}
+			return Error.Wrap(decodeResponse(resp, result))
+		}
+
+		if resp != nil {
+			// the body is drained, so the connection is reused by the next attempt.
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxErrorBody))
+			_ = resp.Body.Close()
+		}
+
+		if backoffWait := backoff.Exponential(attempt, client.config.MinRetryWait, client.config.MaxRetryWait); wait < backoffWait {
+			wait = backoffWait
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return Error.Wrap(ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// send sends a single attempt of the request.
+func (client *Client) send(ctx context.Context, method, endpoint string, payload []byte) (*http.Response, error) {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if client.config.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+client.config.Token)
+	}
+
+	return client.http.Do(req)
+}
+
+// decodeResponse decodes the json body of a successful response into result, or returns the error of the api.
+func decodeResponse(resp *http.Response, result interface{}) (err error) {
+	defer func() { err = errs.Combine(err, resp.Body.Close()) }()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		if err != nil {
+			return err
+		}
+
+		// errors are written by serveError of the server, proxies may answer with other bodies.
+		var response struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(body, &response) != nil || response.Error == "" {
+			response.Error = strings.TrimSpace(string(body))
+		}
+		return &APIError{StatusCode: resp.StatusCode, Message: response.Error}
+	}
+
+	if result == nil {
+		_, err = io.Copy(io.Discard, resp.Body)
+		return err
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+// idempotent checks if the request may be sent again after it could have been handled.
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// retryAfter returns the wait requested by the Retry-After header in seconds, it's zero if it's not set.
+func retryAfter(resp *http.Response) time.Duration {
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
@@ -0,0 +1,288 @@
+package client_test
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"project_template"
+	"project_template/console/client"
+	"project_template/console/consoleserver"
+	"project_template/database/dbtesting"
+	"project_template/dummy"
+	"project_template/jobs"
+	"project_template/pkg/logger/zaplog"
+	"project_template/ratelimit"
+)
+
+func TestDummy(t *testing.T) {
+	dbtesting.Run(t, func(ctx context.Context, t *testing.T, db project_template.DB) {
+		var (
+			mu            sync.Mutex
+			authorization []string
+		)
+		handler := newHandler(t, db)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			authorization = append(authorization, r.Header.Get("Authorization"))
+			mu.Unlock()
+			handler.ServeHTTP(w, r)
+		}))
+		defer server.Close()
+
+		api := newClient(t, client.Config{URL: server.URL + "/", Token: "secret"}).Dummy()
+
+		created, err := api.Create(ctx, "first", dummy.StatusActive)
+		require.NoError(t, err)
+		require.NotEqual(t, uuid.Nil, created.ID)
+		require.Equal(t, "first", created.Title)
+
+		t.Run("get", func(t *testing.T) {
+			got, err := api.Get(ctx, created.ID)
+			require.NoError(t, err)
+			require.Equal(t, created.ID, got.ID)
+			require.Equal(t, created.Title, got.Title)
+			require.Equal(t, created.Status, got.Status)
+		})
+
+		t.Run("update", func(t *testing.T) {
+			require.NoError(t, api.Update(ctx, created.ID, "updated", dummy.StatusInactive))
+
+			got, err := api.Get(ctx, created.ID)
+			require.NoError(t, err)
+			require.Equal(t, "updated", got.Title)
+			require.Equal(t, dummy.Status(dummy.StatusInactive), got.Status)
+		})
+
+		t.Run("invalid", func(t *testing.T) {
+			_, err := api.Create(ctx, " ", dummy.StatusActive)
+			require.Error(t, err)
+			require.True(t, client.Error.Has(err))
+			require.Equal(t, http.StatusBadRequest, client.StatusCode(err))
+			require.Contains(t, err.Error(), "title is required")
+		})
+
+		t.Run("not found", func(t *testing.T) {
+			_, err := api.Get(ctx, uuid.New())
+			require.Error(t, err)
+			require.Equal(t, http.StatusNotFound, client.StatusCode(err))
+
+			err = api.Update(ctx, uuid.New(), "title", dummy.StatusActive)
+			require.Equal(t, http.StatusNotFound, client.StatusCode(err))
+		})
+
+		t.Run("delete", func(t *testing.T) {
+			require.NoError(t, api.Delete(ctx, created.ID))
+
+			_, err := api.Get(ctx, created.ID)
+			require.Equal(t, http.StatusNotFound, client.StatusCode(err))
+
+			list, err := api.List(ctx)
+			require.NoError(t, err)
+			require.Empty(t, list)
+		})
+
+		t.Run("token", func(t *testing.T) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			require.NotEmpty(t, authorization)
+			for _, header := range authorization {
+				require.Equal(t, "Bearer secret", header)
+			}
+		})
+	})
+}
+
+func TestIterate(t *testing.T) {
+	dbtesting.Run(t, func(ctx context.Context, t *testing.T, db project_template.DB) {
+		server := httptest.NewServer(newHandler(t, db))
+		defer server.Close()
+
+		api := newClient(t, client.Config{URL: server.URL}).Dummy()
+
+		var created []uuid.UUID
+		for i := 0; i < 7; i++ {
+			d, err := api.Create(ctx, "dummy", dummy.StatusActive)
+			require.NoError(t, err)
+			created = append(created, d.ID)
+		}
+
+		list, err := api.List(ctx)
+		require.NoError(t, err)
+		require.Len(t, list, 7)
+
+		page, err := api.Page(ctx, dummy.ListOptions{Limit: 2, Offset: 6})
+		require.NoError(t, err)
+		require.Len(t, page, 1)
+		require.Equal(t, created[6], page[0].ID)
+
+		for _, pageSize := range []int{1, 3, 7, 0} {
+			var iterated []uuid.UUID
+			it := api.Iterate(pageSize)
+			for it.Next(ctx) {
+				iterated = append(iterated, it.Item().ID)
+			}
+			require.NoError(t, it.Err())
+			require.Equal(t, created, iterated, "page size %d", pageSize)
+		}
+
+		var found int
+		it := api.IterateSearch("dummy", 2)
+		for it.Next(ctx) {
+			require.Equal(t, "dummy", it.Item().Title)
+			found++
+		}
+		require.NoError(t, it.Err())
+		require.Equal(t, 7, found)
+
+		it = api.IterateSearch(" ", 2)
+		require.False(t, it.Next(ctx))
+		require.Equal(t, http.StatusBadRequest, client.StatusCode(it.Err()))
+	})
+}
+
+func TestRetries(t *testing.T) {
+	dbtesting.Run(t, func(ctx context.Context, t *testing.T, db project_template.DB) {
+		handler := newHandler(t, db)
+
+		// failures holds statuses returned before requests are passed to the handler.
+		var (
+			mu       sync.Mutex
+			failures []int
+			requests int
+		)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			requests++
+			var status int
+			if len(failures) > 0 {
+				status, failures = failures[0], failures[1:]
+			}
+			mu.Unlock()
+
+			if status != 0 {
+				w.Header().Set("Content-Type", "text/plain")
+				w.WriteHeader(status)
+				_, _ = w.Write([]byte(http.StatusText(status)))
+				return
+			}
+			handler.ServeHTTP(w, r)
+		}))
+		defer server.Close()
+
+		api := newClient(t, client.Config{URL: server.URL, MaxRetries: 2}).Dummy()
+
+		fail := func(statuses ...int) {
+			mu.Lock()
+			defer mu.Unlock()
+			failures, requests = statuses, 0
+		}
+		count := func() int {
+			mu.Lock()
+			defer mu.Unlock()
+			return requests
+		}
+
+		t.Run("idempotent", func(t *testing.T) {
+			fail(http.StatusServiceUnavailable, http.StatusBadGateway)
+			_, err := api.List(ctx)
+			require.NoError(t, err)
+			require.Equal(t, 3, count())
+		})
+
+		t.Run("too many requests", func(t *testing.T) {
+			fail(http.StatusTooManyRequests)
+			_, err := api.Create(ctx, "retried", dummy.StatusActive)
+			require.NoError(t, err)
+			require.Equal(t, 2, count())
+		})
+
+		t.Run("not idempotent", func(t *testing.T) {
+			fail(http.StatusServiceUnavailable)
+			_, err := api.Create(ctx, "failed", dummy.StatusActive)
+			require.Equal(t, http.StatusServiceUnavailable, client.StatusCode(err))
+			require.Contains(t, err.Error(), "Service Unavailable")
+			require.Equal(t, 1, count())
+		})
+
+		t.Run("exhausted", func(t *testing.T) {
+			fail(http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable)
+			_, err := api.List(ctx)
+			require.Equal(t, http.StatusServiceUnavailable, client.StatusCode(err))
+			require.Equal(t, 3, count())
+		})
+
+		t.Run("canceled", func(t *testing.T) {
+			fail(http.StatusTooManyRequests)
+
+			ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+			defer cancel()
+
+			slow := newClient(t, client.Config{URL: server.URL, MaxRetries: 1, MinRetryWait: time.Minute, MaxRetryWait: time.Minute})
+			_, err := slow.Dummy().List(ctx)
+			require.ErrorIs(t, err, context.DeadlineExceeded)
+		})
+	})
+}
+
+func TestTLS(t *testing.T) {
+	dbtesting.Run(t, func(ctx context.Context, t *testing.T, db project_template.DB) {
+		server := httptest.NewTLSServer(newHandler(t, db))
+		defer server.Close()
+
+		_, err := newClient(t, client.Config{URL: server.URL}).Dummy().List(ctx)
+		require.Error(t, err)
+		require.Zero(t, client.StatusCode(err))
+
+		caFile := filepath.Join(t.TempDir(), "ca.pem")
+		require.NoError(t, os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0o600))
+
+		list, err := newClient(t, client.Config{URL: server.URL, TLS: client.TLSConfig{CAFile: caFile}}).Dummy().List(ctx)
+		require.NoError(t, err)
+		require.Empty(t, list)
+
+		_, err = client.New(client.Config{URL: server.URL, TLS: client.TLSConfig{CAFile: filepath.Join(t.TempDir(), "missing.pem")}})
+		require.Error(t, err)
+	})
+}
+
+// newClient creates the client with short retry waits, unless they are set.
+func newClient(t *testing.T, config client.Config) *client.Client {
+	if config.Timeout == 0 {
+		config.Timeout = 10 * time.Second
+	}
+	if config.MinRetryWait == 0 {
+		config.MinRetryWait, config.MaxRetryWait = time.Millisecond, 10*time.Millisecond
+	}
+
+	c, err := client.New(config)
+	require.NoError(t, err)
+	return c
+}
+
+// newHandler returns the handler of the console server with dummies stored in the database.
+func newHandler(t *testing.T, db project_template.DB) http.Handler {
+	log := zaplog.NewLog()
+
+	config := consoleserver.Config{RequestTimeout: time.Minute, MaxBodySize: 1 << 20, MaxImportSize: 1 << 20}
+
+	server, err := consoleserver.NewServer(config, log, nil, consoleserver.Services{
+		Dummy:       dummy.NewService(db.Dummy()),
+		DummyStream: dummy.NewStream(log, dummy.StreamConfig{ReplayLimit: 10, BufferSize: 10}, db.Dummy()),
+		Jobs:        jobs.NewScheduler(log, jobs.Config{}, nil),
+		RateLimiter: ratelimit.NewLimiter(log, ratelimit.Config{}, ratelimit.NewMemoryDB()),
+	})
+	require.NoError(t, err)
+
+	return server.Handler()
+}
@@ -0,0 +1,200 @@
+package client_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/zeebo/errs"
+
+	"project_template"
+	"project_template/console/client"
+	"project_template/console/consoleserver"
+	"project_template/database/dbtesting"
+	"project_template/dummy"
+	"project_template/pkg/openapi"
+)
+
+// TestContract calls every method of the client and checks that requests and responses match the OpenAPI
+// document of the server, so the client can't drift from the api.
+func TestContract(t *testing.T) {
+	dbtesting.Run(t, func(ctx context.Context, t *testing.T, db project_template.DB) {
+		spec, err := openapi.Parse(consoleserver.OpenAPISpec)
+		require.NoError(t, err)
+
+		var (
+			mu       sync.Mutex
+			requests []contractRequest
+		)
+		handler := newHandler(t, db)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			request := checkContract(spec, handler, w, r)
+
+			mu.Lock()
+			defer mu.Unlock()
+			requests = append(requests, request)
+		}))
+		defer server.Close()
+
+		c := newClient(t, client.Config{URL: server.URL})
+		api := c.Dummy()
+
+		created, err := api.Create(ctx, "first", dummy.StatusActive)
+		require.NoError(t, err)
+
+		calls := []struct {
+			name string
+			call func() error
+		}{
+			{"DummyAPI.Create", func() error {
+				if _, err := api.Create(ctx, "second", dummy.StatusInactive); err != nil {
+					return err
+				}
+				_, err := api.Create(ctx, " ", dummy.StatusActive)
+				return expectStatus(err, http.StatusBadRequest)
+			}},
+			{"DummyAPI.List", func() error {
+				_, err := api.List(ctx)
+				return err
+			}},
+			{"DummyAPI.Page", func() error {
+				_, err := api.Page(ctx, dummy.ListOptions{Limit: 1, Offset: 1})
+				return err
+			}},
+			{"DummyAPI.Iterate", func() error {
+				it := api.Iterate(1)
+				for it.Next(ctx) {
+				}
+				return it.Err()
+			}},
+			{"DummyAPI.Search", func() error {
+				if _, err := api.Search(ctx, "first", dummy.SearchOptions{Limit: 10}); err != nil {
+					return err
+				}
+				_, err := api.Search(ctx, " ", dummy.SearchOptions{})
+				return expectStatus(err, http.StatusBadRequest)
+			}},
+			{"DummyAPI.IterateSearch", func() error {
+				it := api.IterateSearch("first", 1)
+				for it.Next(ctx) {
+				}
+				return it.Err()
+			}},
+			{"DummyAPI.Get", func() error {
+				if _, err := api.Get(ctx, created.ID); err != nil {
+					return err
+				}
+				_, err := api.Get(ctx, uuid.New())
+				return expectStatus(err, http.StatusNotFound)
+			}},
+			{"DummyAPI.Update", func() error {
+				if err := api.Update(ctx, created.ID, "updated", dummy.StatusInactive); err != nil {
+					return err
+				}
+				return expectStatus(api.Update(ctx, uuid.New(), "updated", dummy.StatusActive), http.StatusNotFound)
+			}},
+			{"DummyAPI.Delete", func() error {
+				return api.Delete(ctx, created.ID)
+			}},
+		}
+
+		covered := make(map[string]bool)
+		for _, test := range calls {
+			covered[test.name] = true
+
+			t.Run(test.name, func(t *testing.T) {
+				mu.Lock()
+				requests = nil
+				mu.Unlock()
+
+				require.NoError(t, test.call())
+
+				mu.Lock()
+				defer mu.Unlock()
+				require.NotEmpty(t, requests)
+				for _, request := range requests {
+					require.NoError(t, request.err, "%s %s", request.method, request.uri)
+				}
+			})
+		}
+
+		for _, name := range clientMethods(c) {
+			require.True(t, covered[name], "%s is not covered by the contract test", name)
+		}
+	})
+}
+
+// contractRequest is a request sent by the client, err describes how it or its response differs from the document.
+type contractRequest struct {
+	method string
+	uri    string
+	err    error
+}
+
+// checkContract serves the request by the handler and checks the request and the response against the document.
+func checkContract(spec *openapi.Document, handler http.Handler, w http.ResponseWriter, r *http.Request) contractRequest {
+	request := contractRequest{method: r.Method, uri: r.URL.RequestURI()}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		request.err = err
+		return request
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, r)
+
+	for name, values := range recorder.Header() {
+		w.Header()[name] = values
+	}
+	w.WriteHeader(recorder.Code)
+	_, _ = w.Write(recorder.Body.Bytes())
+
+	_, operation, ok := spec.Find(r.Method, r.URL.Path)
+	if !ok {
+		request.err = errs.New("operation is not documented")
+		return request
+	}
+
+	request.err = errs.Combine(
+		spec.ValidateQuery(operation, r.URL.Query()),
+		spec.ValidateRequest(operation, r.Header.Get("Content-Type"), body),
+		spec.ValidateResponse(operation, recorder.Code, recorder.Header().Get("Content-Type"), recorder.Body.Bytes()),
+	)
+	return request
+}
+
+// clientMethods returns names of methods of all apis of the client, e.g. "DummyAPI.List".
+func clientMethods(c *client.Client) []string {
+	var names []string
+
+	value := reflect.ValueOf(c)
+	for i := 0; i < value.NumMethod(); i++ {
+		method := value.Type().Method(i)
+		if method.Type.NumIn() != 1 || method.Type.NumOut() != 1 {
+			continue
+		}
+
+		api := method.Type.Out(0)
+		for k := 0; k < api.NumMethod(); k++ {
+			names = append(names, api.Elem().Name()+"."+api.Method(k).Name)
+		}
+	}
+	return names
+}
+
+// expectStatus checks that the error is an api error with the status.
+func expectStatus(err error, status int) error {
+	if client.StatusCode(err) != status {
+		return errs.New("expected status %d, got %v", status, err)
+	}
+	return nil
+}
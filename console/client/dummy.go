@@ -0,0 +1,110 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/google/uuid"
+
+	"project_template/dummy"
+)
+
+// DummyAPI calls dummy endpoints of the console api.
+type DummyAPI struct {
+	client *Client
+}
+
+// dummyRequest is the body of create and update requests.
+type dummyRequest struct {
+	Title  string       `json:"title"`
+	Status dummy.Status `json:"status"`
+}
+
+// List returns all dummies, Iterate should be used if there may be many of them.
+func (api *DummyAPI) List(ctx context.Context) ([]dummy.Dummy, error) {
+	var result []dummy.Dummy
+	err := api.client.do(ctx, http.MethodGet, "/dummy", nil, nil, &result)
+	return result, err
+}
+
+// Page returns a page of dummies ordered by creation time, the server applies the default limit if it's not set.
+func (api *DummyAPI) Page(ctx context.Context, opts dummy.ListOptions) ([]dummy.Dummy, error) {
+	var result []dummy.Dummy
+	err := api.client.do(ctx, http.MethodGet, "/dummy", pageQuery(opts.Limit, opts.Offset), nil, &result)
+	return result, err
+}
+
+// Iterate returns an iterator over all dummies ordered by creation time, fetched in pages of the size.
+func (api *DummyAPI) Iterate(pageSize int) *Iterator[dummy.Dummy] {
+	pageSize = clampPageSize(pageSize, dummy.DefaultListLimit, dummy.MaxListLimit)
+
+	return newIterator(pageSize, func(ctx context.Context, offset int) ([]dummy.Dummy, error) {
+		return api.Page(ctx, dummy.ListOptions{Limit: pageSize, Offset: offset})
+	})
+}
+
+// Search returns dummies which titles match the query, ordered by rank.
+func (api *DummyAPI) Search(ctx context.Context, query string, opts dummy.SearchOptions) ([]dummy.SearchResult, error) {
+	params := pageQuery(opts.Limit, opts.Offset)
+	params.Set("q", query)
+
+	var result []dummy.SearchResult
+	err := api.client.do(ctx, http.MethodGet, "/dummy/search", params, nil, &result)
+	return result, err
+}
+
+// IterateSearch returns an iterator over all dummies which titles match the query, fetched in pages of the size.
+func (api *DummyAPI) IterateSearch(query string, pageSize int) *Iterator[dummy.SearchResult] {
+	pageSize = clampPageSize(pageSize, dummy.DefaultSearchLimit, dummy.MaxSearchLimit)
+
+	return newIterator(pageSize, func(ctx context.Context, offset int) ([]dummy.SearchResult, error) {
+		return api.Search(ctx, query, dummy.SearchOptions{Limit: pageSize, Offset: offset})
+	})
+}
+
+// Get returns the dummy by id, the error has status 404 if it doesn't exist.
+func (api *DummyAPI) Get(ctx context.Context, id uuid.UUID) (dummy.Dummy, error) {
+	var result dummy.Dummy
+	err := api.client.do(ctx, http.MethodGet, "/dummy/"+id.String(), nil, nil, &result)
+	return result, err
+}
+
+// Create creates a dummy and returns it. It's not retried after network errors, since it could have been created.
+func (api *DummyAPI) Create(ctx context.Context, title string, status dummy.Status) (dummy.Dummy, error) {
+	var result dummy.Dummy
+	err := api.client.do(ctx, http.MethodPost, "/dummy", nil, dummyRequest{Title: title, Status: status}, &result)
+	return result, err
+}
+
+// Update updates title and status of the dummy, the error has status 404 if it doesn't exist.
+func (api *DummyAPI) Update(ctx context.Context, id uuid.UUID, title string, status dummy.Status) error {
+	return api.client.do(ctx, http.MethodPut, "/dummy/"+id.String(), nil, dummyRequest{Title: title, Status: status}, nil)
+}
+
+// Delete deletes the dummy, it's not an error if it doesn't exist.
+func (api *DummyAPI) Delete(ctx context.Context, id uuid.UUID) error {
+	return api.client.do(ctx, http.MethodDelete, "/dummy/"+id.String(), nil, nil, nil)
+}
+
+// pageQuery returns query parameters of the page, both are always set, so the server paginates the result.
+func pageQuery(limit, offset int) url.Values {
+	return url.Values{
+		"limit":  {strconv.Itoa(limit)},
+		"offset": {strconv.Itoa(offset)},
+	}
+}
+
+// clampPageSize returns the default size if it's not set and the maximum one if it's exceeded,
+// since the server returns short pages in that case and the iterator would stop early.
+func clampPageSize(pageSize, defaultSize, maxSize int) int {
+	switch {
+	case pageSize <= 0:
+		return defaultSize
+	case pageSize > maxSize:
+		return maxSize
+	default:
+		return pageSize
+	}
+}
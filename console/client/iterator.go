@@ -0,0 +1,73 @@
+package client
+
+import (
+	"context"
+)
+
+// Iterator iterates over items of a paginated endpoint, pages are fetched when the previous one is consumed.
+// Pages are requested by offset, so items created or deleted during the iteration may be skipped or repeated.
+//
+//	it := client.Dummy().Iterate(100)
+//	for it.Next(ctx) {
+//		item := it.Item()
+//	}
+//	if err := it.Err(); err != nil {
+//	}
+type Iterator[T any] struct {
+	pageSize int
+	fetch    func(ctx context.Context, offset int) ([]T, error)
+
+	page   []T
+	index  int
+	offset int
+	done   bool
+	err    error
+}
+
+// newIterator is a constructor for the iterator, fetch returns the page of the size at the offset.
+func newIterator[T any](pageSize int, fetch func(ctx context.Context, offset int) ([]T, error)) *Iterator[T] {
+	return &Iterator[T]{
+		pageSize: pageSize,
+		fetch:    fetch,
+		index:    -1,
+	}
+}
+
+// Next advances to the next item, it returns false when there are no more items or a page could not be fetched.
+func (it *Iterator[T]) Next(ctx context.Context) bool {
+	if it.err != nil {
+		return false
+	}
+
+	it.index++
+	if it.index < len(it.page) {
+		return true
+	}
+
+	// a short page is the last one.
+	if it.done {
+		return false
+	}
+
+	page, err := it.fetch(ctx, it.offset)
+	if err != nil {
+		it.err = err
+		return false
+	}
+
+	it.page, it.index = page, 0
+	it.offset += len(page)
+	it.done = len(page) < it.pageSize
+
+	return len(page) > 0
+}
+
+// Item returns the current item, it's valid only after Next returned true.
+func (it *Iterator[T]) Item() T {
+	return it.page[it.index]
+}
+
+// Err returns the error which stopped the iteration.
+func (it *Iterator[T]) Err() error {
+	return it.err
+}
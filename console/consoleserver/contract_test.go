@@ -0,0 +1,407 @@
+package consoleserver_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"project_template/console/consoleserver"
+	"project_template/dummy"
+	"project_template/events"
+	"project_template/jobs"
+	"project_template/pkg/logger/zaplog"
+	"project_template/pkg/openapi"
+	"project_template/ratelimit"
+	"project_template/webhooks"
+)
+
+var (
+	testDummy = dummy.Dummy{
+		ID:        uuid.MustParse("1b4e28ba-2fa1-11d2-883f-0016d3cca427"),
+		Title:     "first",
+		Status:    dummy.StatusActive,
+		CreatedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	testSubscription = webhooks.Subscription{
+		ID:         uuid.MustParse("6ba7b810-9dad-11d1-80b4-00c04fd430c8"),
+		URL:        "https://hooks.test/dummy",
+		EventTypes: []string{"dummy.*"},
+		Secret:     "secret",
+		Active:     true,
+		CreatedAt:  time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		UpdatedAt:  time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	testDelivery = webhooks.Delivery{
+		ID:             uuid.MustParse("7d444840-9dc0-11d1-b245-5ffdce74fad2"),
+		SubscriptionID: testSubscription.ID,
+		EventID:        uuid.MustParse("f47ac10b-58cc-4372-a567-0e02b2c3d479"),
+		EventType:      "dummy.created",
+		Payload:        json.RawMessage(`{"id":"1b4e28ba-2fa1-11d2-883f-0016d3cca427"}`),
+		Status:         webhooks.DeliveryDead,
+		Attempts:       3,
+		NextAttemptAt:  time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		ResponseStatus: http.StatusBadGateway,
+		LastError:      "bad gateway",
+		CreatedAt:      time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	testRun = jobs.Run{
+		ID:        uuid.MustParse("9f8e7d6c-5b4a-4c3d-8e2f-1a0b9c8d7e6f"),
+		Job:       "test.job",
+		Status:    jobs.RunFailed,
+		StartedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Error:     "failed",
+	}
+	missingID = uuid.MustParse("00000000-0000-4000-8000-000000000001")
+)
+
+// TestContract sends requests to the api and checks that responses match the OpenAPI document.
+func TestContract(t *testing.T) {
+	spec, err := openapi.Parse(consoleserver.OpenAPISpec)
+	require.NoError(t, err)
+
+	config := testConfig()
+	config.MaxBodySize = 1024
+	url := runContractServer(t, config)
+
+	covered := make(map[openapi.Route]bool)
+	for _, test := range []struct {
+		name        string
+		method      string
+		path        string
+		contentType string
+		body        string
+		status      int
+	}{
+		{"openapi", http.MethodGet, "/api/v0/openapi.json", "", "", http.StatusOK},
+
+		{"list dummies", http.MethodGet, "/api/v0/dummy", "", "", http.StatusOK},
+		{"list page of dummies", http.MethodGet, "/api/v0/dummy?limit=10&offset=0", "", "", http.StatusOK},
+		{"list page with invalid limit", http.MethodGet, "/api/v0/dummy?limit=ten", "", "", http.StatusBadRequest},
+		{"create dummy", http.MethodPost, "/api/v0/dummy", "application/json", `{"title": "second", "status": 0}`, http.StatusOK},
+		{"create invalid dummy", http.MethodPost, "/api/v0/dummy", "application/json", `{"title": "second", "status": 2}`, http.StatusBadRequest},
+		{"create too large dummy", http.MethodPost, "/api/v0/dummy", "application/json", `{"title": "` + strings.Repeat("a", 2048) + `"}`, http.StatusRequestEntityTooLarge},
+		{"search dummies", http.MethodGet, "/api/v0/dummy/search?q=first&limit=5", "", "", http.StatusOK},
+		{"search without query", http.MethodGet, "/api/v0/dummy/search", "", "", http.StatusBadRequest},
+		{"batch", http.MethodPost, "/api/v0/dummy/batch", "application/json", `{"mode": "best_effort", "operations": [{"op": "create", "title": "third", "status": 1}, {"op": "delete", "id": "` + testDummy.ID.String() + `"}]}`, http.StatusOK},
+		{"invalid atomic batch", http.MethodPost, "/api/v0/dummy/batch", "application/json", `{"operations": [{"op": "update", "id": "` + testDummy.ID.String() + `", "title": "", "status": 1}]}`, http.StatusUnprocessableEntity},
+		{"import", http.MethodPost, "/api/v0/dummy/import", "text/csv", "title,status\nfourth,1\nfifth,7\n", http.StatusOK},
+		{"import without format", http.MethodPost, "/api/v0/dummy/import", "text/plain", "fourth", http.StatusBadRequest},
+		{"export ndjson", http.MethodGet, "/api/v0/dummy/export", "", "", http.StatusOK},
+		{"export csv", http.MethodGet, "/api/v0/dummy/export?format=csv", "", "", http.StatusOK},
+		{"export unknown format", http.MethodGet, "/api/v0/dummy/export?format=xml", "", "", http.StatusBadRequest},
+		{"get dummy", http.MethodGet, "/api/v0/dummy/" + testDummy.ID.String(), "", "", http.StatusOK},
+		{"get missing dummy", http.MethodGet, "/api/v0/dummy/" + missingID.String(), "", "", http.StatusNotFound},
+		{"get dummy by invalid id", http.MethodGet, "/api/v0/dummy/1", "", "", http.StatusBadRequest},
+		{"update dummy", http.MethodPut, "/api/v0/dummy/" + testDummy.ID.String(), "application/json", `{"title": "renamed", "status": 0}`, http.StatusOK},
+		{"update missing dummy", http.MethodPut, "/api/v0/dummy/" + missingID.String(), "application/json", `{"title": "renamed", "status": 0}`, http.StatusNotFound},
+		{"resume stream from pruned change", http.MethodGet, "/api/v0/dummy/stream?lastEventId=1", "", "", http.StatusGone},
+		{"delete dummy", http.MethodDelete, "/api/v0/dummy/" + testDummy.ID.String(), "", "", http.StatusOK},
+
+		{"list subscriptions", http.MethodGet, "/api/v0/webhooks", "", "", http.StatusOK},
+		{"create subscription", http.MethodPost, "/api/v0/webhooks", "application/json", `{"url": "https://hooks.test/new", "eventTypes": ["dummy.created"]}`, http.StatusCreated},
+		{"create invalid subscription", http.MethodPost, "/api/v0/webhooks", "application/json", `{"url": "hooks"}`, http.StatusBadRequest},
+		{"get subscription", http.MethodGet, "/api/v0/webhooks/" + testSubscription.ID.String(), "", "", http.StatusOK},
+		{"get missing subscription", http.MethodGet, "/api/v0/webhooks/" + missingID.String(), "", "", http.StatusNotFound},
+		{"update subscription", http.MethodPut, "/api/v0/webhooks/" + testSubscription.ID.String(), "application/json", `{"url": "https://hooks.test/updated", "active": false}`, http.StatusOK},
+		{"delete subscription", http.MethodDelete, "/api/v0/webhooks/" + testSubscription.ID.String(), "", "", http.StatusOK},
+		{"delete missing subscription", http.MethodDelete, "/api/v0/webhooks/" + missingID.String(), "", "", http.StatusNotFound},
+		{"list deliveries", http.MethodGet, "/api/v0/webhooks/" + testSubscription.ID.String() + "/deliveries?limit=10", "", "", http.StatusOK},
+		{"list deliveries with invalid limit", http.MethodGet, "/api/v0/webhooks/" + testSubscription.ID.String() + "/deliveries?limit=1000", "", "", http.StatusBadRequest},
+		{"redeliver", http.MethodPost, "/api/v0/webhooks/" + testSubscription.ID.String() + "/deliveries/" + testDelivery.ID.String() + "/redeliver", "", "", http.StatusAccepted},
+		{"redeliver missing delivery", http.MethodPost, "/api/v0/webhooks/" + testSubscription.ID.String() + "/deliveries/" + missingID.String() + "/redeliver", "", "", http.StatusNotFound},
+
+		{"list jobs", http.MethodGet, "/api/v0/jobs", "", "", http.StatusOK},
+		{"list runs", http.MethodGet, "/api/v0/jobs/test.job/runs", "", "", http.StatusOK},
+		{"list runs of idle job", http.MethodGet, "/api/v0/jobs/test.idle/runs", "", "", http.StatusOK},
+		{"list runs of missing job", http.MethodGet, "/api/v0/jobs/missing/runs", "", "", http.StatusNotFound},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			req, err := http.NewRequest(test.method, url+test.path, strings.NewReader(test.body))
+			require.NoError(t, err)
+			if test.contentType != "" {
+				req.Header.Set("Content-Type", test.contentType)
+			}
+
+			route, operation, ok := spec.Find(req.Method, req.URL.Path)
+			require.True(t, ok, "operation is not documented")
+			if test.status < http.StatusBadRequest {
+				require.NoError(t, spec.ValidateRequest(operation, test.contentType, []byte(test.body)))
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+
+			require.Equal(t, test.status, resp.StatusCode, string(body))
+			require.NoError(t, spec.ValidateResponse(operation, resp.StatusCode, resp.Header.Get("Content-Type"), body))
+			covered[route] = true
+		})
+	}
+
+	t.Run("stream", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url+"/api/v0/dummy/stream", nil)
+		require.NoError(t, err)
+		req.Header.Set("Last-Event-ID", "41")
+
+		route, operation, ok := spec.Find(req.Method, req.URL.Path)
+		require.True(t, ok)
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.NoError(t, spec.ValidateResponse(operation, resp.StatusCode, resp.Header.Get("Content-Type"), nil))
+
+		// the replayed change is the first event.
+		var data string
+		scanner := bufio.NewScanner(resp.Body)
+		for data == "" && scanner.Scan() {
+			if value, ok := cutField(scanner.Text(), "data: "); ok {
+				data = value
+			}
+		}
+		require.NoError(t, scanner.Err())
+
+		var change interface{}
+		decoder := json.NewDecoder(strings.NewReader(data))
+		decoder.UseNumber()
+		require.NoError(t, decoder.Decode(&change))
+		require.NoError(t, spec.Validate(spec.Components.Schemas["Change"], change))
+		covered[route] = true
+	})
+
+	t.Run("rate limited", func(t *testing.T) {
+		config := testConfig()
+		config.RateLimit.JobsIP = ratelimit.Limit{Requests: 1, Period: time.Minute}
+		url := runContractServer(t, config)
+
+		route, operation, ok := spec.Find(http.MethodGet, "/api/v0/jobs")
+		require.True(t, ok)
+
+		for _, status := range []int{http.StatusOK, http.StatusTooManyRequests} {
+			resp, err := http.Get(url + route.Path)
+			require.NoError(t, err)
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+
+			require.Equal(t, status, resp.StatusCode)
+			require.NoError(t, spec.ValidateResponse(operation, resp.StatusCode, resp.Header.Get("Content-Type"), body))
+		}
+	})
+
+	for _, route := range spec.Routes() {
+		require.True(t, covered[route], "%s %s is not covered by contract tests", route.Method, route.Path)
+	}
+}
+
+func TestSwaggerUI(t *testing.T) {
+	config := testConfig()
+	config.Docs = true
+	url := runServer(t, config)
+
+	resp, err := http.Get(url + "/docs")
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, url+"/docs/", resp.Request.URL.String())
+	require.Contains(t, string(body), `<div id="swagger-ui"></div>`)
+	require.NotContains(t, string(body), "https://")
+	require.Contains(t, resp.Header.Get("Content-Security-Policy"), "script-src 'self';")
+
+	for _, asset := range []string{"swagger-ui-bundle.js", "swagger-ui.css", "swagger-initializer.js"} {
+		resp, err := http.Get(url + "/docs/" + asset)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		require.Equal(t, http.StatusOK, resp.StatusCode, asset)
+		require.NotZero(t, resp.ContentLength, asset)
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		url := runServer(t, testConfig())
+
+		resp, err := http.Get(url + "/docs/")
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
+
+// cutField returns the value of the server-sent event line with the prefix.
+func cutField(line, prefix string) (string, bool) {
+	if !strings.HasPrefix(line, prefix) {
+		return "", false
+	}
+	return line[len(prefix):], true
+}
+
+// runContractServer runs the server with services backed by fixed test data until the test ends and returns its url.
+func runContractServer(t *testing.T, config consoleserver.Config) string {
+	log := zaplog.NewLog()
+
+	dummyDB := &contractDummyDB{}
+	scheduler := jobs.NewScheduler(log, jobs.Config{}, &contractJobsDB{})
+	for _, name := range []string{"test.job", "test.idle"} {
+		require.NoError(t, scheduler.Register(jobs.Job{
+			Name:     name,
+			Schedule: jobs.Every(time.Hour),
+			Run:      func(ctx context.Context) error { return nil },
+		}))
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server, err := consoleserver.NewServer(config, log, listener, consoleserver.Services{
+		Dummy:       dummy.NewService(dummyDB),
+		DummyStream: dummy.NewStream(log, dummy.StreamConfig{ReplayLimit: 10, BufferSize: 10}, dummyDB),
+		Webhooks:    webhooks.NewService(webhooks.Config{}, &contractWebhooksDB{}),
+		Jobs:        scheduler,
+		RateLimiter: ratelimit.NewLimiter(log, ratelimit.Config{}, ratelimit.NewMemoryDB()),
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- server.Run(ctx) }()
+
+	t.Cleanup(func() {
+		cancel()
+		require.NoError(t, <-done)
+	})
+
+	return "http://" + listener.Addr().String()
+}
+
+// contractDummyDB serves testDummy, changes aren't stored. Methods which aren't used by the api are not implemented.
+type contractDummyDB struct {
+	dummy.DB
+}
+
+func (db *contractDummyDB) List(ctx context.Context) ([]dummy.Dummy, error) {
+	return []dummy.Dummy{testDummy}, nil
+}
+
+func (db *contractDummyDB) ListPage(ctx context.Context, opts dummy.ListOptions) ([]dummy.Dummy, error) {
+	if opts.Offset > 0 {
+		return nil, nil
+	}
+	return []dummy.Dummy{testDummy}, nil
+}
+
+func (db *contractDummyDB) Get(ctx context.Context, id uuid.UUID) (dummy.Dummy, error) {
+	if id != testDummy.ID {
+		return dummy.Dummy{}, dummy.ErrNoDummy.New("%s", id)
+	}
+	return testDummy, nil
+}
+
+func (db *contractDummyDB) Create(ctx context.Context, d dummy.Dummy, event events.Event) error {
+	return nil
+}
+
+func (db *contractDummyDB) Update(ctx context.Context, id uuid.UUID, title string, status dummy.Status, event events.Event) error {
+	_, err := db.Get(ctx, id)
+	return err
+}
+
+func (db *contractDummyDB) Delete(ctx context.Context, id uuid.UUID, event events.Event) error {
+	return nil
+}
+
+func (db *contractDummyDB) Import(ctx context.Context, dummies []dummy.Dummy, created map[uuid.UUID]events.Event) ([]uuid.UUID, error) {
+	return nil, nil
+}
+
+func (db *contractDummyDB) Export(ctx context.Context, fn func(d dummy.Dummy) error) error {
+	return fn(testDummy)
+}
+
+func (db *contractDummyDB) Batch(ctx context.Context, batch dummy.Batch, atomic bool) ([]uuid.UUID, error) {
+	return nil, nil
+}
+
+func (db *contractDummyDB) Search(ctx context.Context, query string, opts dummy.SearchOptions) ([]dummy.SearchResult, error) {
+	return []dummy.SearchResult{{Dummy: testDummy, Rank: 0.5, Highlight: "<mark>first</mark>"}}, nil
+}
+
+func (db *contractDummyDB) GetChange(ctx context.Context, id int64) (dummy.Change, error) {
+	if id != 41 {
+		return dummy.Change{}, dummy.ErrNoChange.New("%d", id)
+	}
+	return dummy.Change{ID: id, Op: dummy.ChangeCreated, Dummy: testDummy, ChangedAt: testDummy.CreatedAt}, nil
+}
+
+func (db *contractDummyDB) Changes(ctx context.Context, after int64, limit int) ([]dummy.Change, error) {
+	return []dummy.Change{{ID: after + 1, Op: dummy.ChangeCreated, Dummy: testDummy, ChangedAt: testDummy.CreatedAt}}, nil
+}
+
+// contractWebhooksDB serves testSubscription with testDelivery, changes aren't stored.
+type contractWebhooksDB struct {
+	webhooks.DB
+}
+
+func (db *contractWebhooksDB) ListSubscriptions(ctx context.Context) ([]webhooks.Subscription, error) {
+	return []webhooks.Subscription{testSubscription}, nil
+}
+
+func (db *contractWebhooksDB) GetSubscription(ctx context.Context, id uuid.UUID) (webhooks.Subscription, error) {
+	if id != testSubscription.ID {
+		return webhooks.Subscription{}, webhooks.ErrNoSubscription.New("%s", id)
+	}
+	return testSubscription, nil
+}
+
+func (db *contractWebhooksDB) CreateSubscription(ctx context.Context, subscription webhooks.Subscription) error {
+	return nil
+}
+
+func (db *contractWebhooksDB) UpdateSubscription(ctx context.Context, subscription webhooks.Subscription) error {
+	return nil
+}
+
+func (db *contractWebhooksDB) DeleteSubscription(ctx context.Context, id uuid.UUID) error {
+	_, err := db.GetSubscription(ctx, id)
+	return err
+}
+
+func (db *contractWebhooksDB) ListDeliveries(ctx context.Context, subscriptionID uuid.UUID, limit, offset int) ([]webhooks.Delivery, error) {
+	return []webhooks.Delivery{testDelivery}, nil
+}
+
+func (db *contractWebhooksDB) Redeliver(ctx context.Context, subscriptionID, id uuid.UUID) error {
+	if subscriptionID != testSubscription.ID || id != testDelivery.ID {
+		return webhooks.ErrNoDelivery.New("%s", id)
+	}
+	return nil
+}
+
+// contractJobsDB serves testRun as the only run of its job.
+type contractJobsDB struct {
+	jobs.DB
+}
+
+func (db *contractJobsDB) ListRuns(ctx context.Context, job string, limit int) ([]jobs.Run, error) {
+	if job != testRun.Job {
+		return nil, nil
+	}
+	return []jobs.Run{testRun}, nil
+}
@@ -1,9 +1,15 @@
 package controllers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"mime"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
@@ -22,26 +28,53 @@ var (
 type Dummy struct {
 	log logger.Logger
 
-	dummy *dummy.Service
+	dummy  *dummy.Service
+	stream *dummy.Stream
 }
 
 // NewDummy is a constructor for dummy controller.
-func NewDummy(log logger.Logger, dummy *dummy.Service) *Dummy {
+func NewDummy(log logger.Logger, dummy *dummy.Service, stream *dummy.Stream) *Dummy {
 	dummyController := &Dummy{
-		log:   log,
-		dummy: dummy,
+		log:    log,
+		dummy:  dummy,
+		stream: stream,
 	}
 
 	return dummyController
 }
 
+// List returns all dummies, or a page of them ordered by creation time if "limit" or "offset" parameters are set.
 func (controller *Dummy) List(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
+	query := r.URL.Query()
 
-	result, err := controller.dummy.List(ctx)
+	var (
+		result []dummy.Dummy
+		err    error
+	)
+	if query.Has("limit") || query.Has("offset") {
+		var opts dummy.ListOptions
+		for name, value := range map[string]*int{"limit": &opts.Limit, "offset": &opts.Offset} {
+			if param := query.Get(name); param != "" {
+				if *value, err = strconv.Atoi(param); err != nil {
+					controller.serveError(w, http.StatusBadRequest, ErrDummy.New("invalid %s: %v", name, err))
+					return
+				}
+			}
+		}
+
+		result, err = controller.dummy.ListPage(ctx, opts)
+	} else {
+		result, err = controller.dummy.List(ctx)
+	}
 	if err != nil {
 		controller.log.Error("could not get list of dummy", ErrDummy.Wrap(err))
-		controller.serveError(w, http.StatusInternalServerError, ErrDummy.Wrap(err))
+		switch {
+		case dummy.ErrInvalidDummy.Has(err):
+			controller.serveError(w, http.StatusBadRequest, ErrDummy.Wrap(err))
+		default:
+			controller.serveError(w, http.StatusInternalServerError, ErrDummy.Wrap(err))
+		}
 		return
 	}
 
@@ -68,15 +101,20 @@ func (controller *Dummy) Create(w http.ResponseWriter, r *http.Request) {
 		req request
 	)
 
-	if err = json.NewDecoder(r.Body).Decode(&req); err != nil {
-		controller.serveError(w, http.StatusBadRequest, ErrDummy.Wrap(err))
+	if err = decodeJSON(r, &req); err != nil {
+		controller.serveError(w, bodyErrorStatus(err), ErrDummy.Wrap(err))
 		return
 	}
 
 	result, err := controller.dummy.Create(ctx, req.Title, req.Status)
 	if err != nil {
 		controller.log.Error(fmt.Sprint("could not create dummy"), ErrDummy.Wrap(err))
-		controller.serveError(w, http.StatusInternalServerError, ErrDummy.Wrap(err))
+		switch {
+		case dummy.ErrInvalidDummy.Has(err):
+			controller.serveError(w, http.StatusBadRequest, ErrDummy.Wrap(err))
+		default:
+			controller.serveError(w, http.StatusInternalServerError, ErrDummy.Wrap(err))
+		}
 		return
 	}
 
@@ -131,8 +169,8 @@ func (controller *Dummy) Update(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req request
-	if err = json.NewDecoder(r.Body).Decode(&req); err != nil {
-		controller.serveError(w, http.StatusBadRequest, ErrDummy.Wrap(err))
+	if err = decodeJSON(r, &req); err != nil {
+		controller.serveError(w, bodyErrorStatus(err), ErrDummy.Wrap(err))
 		return
 	}
 
@@ -140,6 +178,8 @@ func (controller *Dummy) Update(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		controller.log.Error("could not update dummy", ErrDummy.Wrap(err))
 		switch {
+		case dummy.ErrInvalidDummy.Has(err):
+			controller.serveError(w, http.StatusBadRequest, ErrDummy.Wrap(err))
 		case dummy.ErrNoDummy.Has(err):
 			controller.serveError(w, http.StatusNotFound, ErrDummy.Wrap(err))
 		default:
@@ -167,6 +207,234 @@ func (controller *Dummy) Delete(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// Search returns dummies which titles match the "q" parameter, paginated with "limit" and "offset" parameters.
+func (controller *Dummy) Search(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := r.URL.Query()
+
+	var (
+		opts dummy.SearchOptions
+		err  error
+	)
+	for name, value := range map[string]*int{"limit": &opts.Limit, "offset": &opts.Offset} {
+		if param := query.Get(name); param != "" {
+			if *value, err = strconv.Atoi(param); err != nil {
+				controller.serveError(w, http.StatusBadRequest, ErrDummy.New("invalid %s: %v", name, err))
+				return
+			}
+		}
+	}
+
+	result, err := controller.dummy.Search(ctx, query.Get("q"), opts)
+	if err != nil {
+		controller.log.Error("could not search dummies", ErrDummy.Wrap(err))
+		switch {
+		case dummy.ErrInvalidDummy.Has(err):
+			controller.serveError(w, http.StatusBadRequest, ErrDummy.Wrap(err))
+		default:
+			controller.serveError(w, http.StatusInternalServerError, ErrDummy.Wrap(err))
+		}
+		return
+	}
+
+	if result == nil {
+		result = make([]dummy.SearchResult, 0)
+	}
+
+	if err = json.NewEncoder(w).Encode(result); err != nil {
+		controller.log.Error("failed to write json response", ErrDummy.Wrap(err))
+		return
+	}
+}
+
+// Import stores dummies from the request body streamed in csv or ndjson format,
+// rows which could not be imported are listed in the response.
+func (controller *Dummy) Import(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	format, err := requestFormat(r)
+	if err != nil {
+		controller.serveError(w, http.StatusBadRequest, ErrDummy.Wrap(err))
+		return
+	}
+
+	result, err := controller.dummy.Import(ctx, r.Body, format)
+	if err != nil {
+		if bodyErrorStatus(err) == http.StatusRequestEntityTooLarge {
+			controller.serveError(w, http.StatusRequestEntityTooLarge, ErrDummy.Wrap(err))
+			return
+		}
+
+		controller.log.Error("could not import dummies", ErrDummy.Wrap(err))
+		controller.serveError(w, http.StatusInternalServerError, ErrDummy.Wrap(err))
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(result); err != nil {
+		controller.log.Error("failed to write json response", ErrDummy.Wrap(err))
+		return
+	}
+}
+
+// Export streams all dummies in csv or ndjson format.
+func (controller *Dummy) Export(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	format := dummy.FormatNDJSON
+	if name := r.URL.Query().Get("format"); name != "" {
+		var err error
+		if format, err = dummy.ParseFormat(name); err != nil {
+			controller.serveError(w, http.StatusBadRequest, ErrDummy.Wrap(err))
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="dummies.%s"`, format))
+
+	// the status is already sent when rows are being written, so errors can only be logged.
+	if err := controller.dummy.Export(ctx, w, format); err != nil {
+		controller.log.Error("could not export dummies", ErrDummy.Wrap(err))
+		return
+	}
+}
+
+// Batch applies a list of create, update and delete operations. Failed operations of a best-effort batch
+// are reported in the response, an atomic batch which was rolled back is answered with 422 status.
+func (controller *Dummy) Batch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	type request struct {
+		Mode       dummy.BatchMode   `json:"mode"`
+		Operations []dummy.BatchItem `json:"operations"`
+	}
+
+	req := request{Mode: dummy.BatchAtomic}
+	if err := decodeJSON(r, &req); err != nil {
+		controller.serveError(w, bodyErrorStatus(err), ErrDummy.Wrap(err))
+		return
+	}
+
+	result, err := controller.dummy.Batch(ctx, req.Operations, req.Mode)
+	if err != nil {
+		controller.log.Error("could not apply batch", ErrDummy.Wrap(err))
+		switch {
+		case dummy.ErrInvalidDummy.Has(err):
+			controller.serveError(w, http.StatusBadRequest, ErrDummy.Wrap(err))
+		default:
+			controller.serveError(w, http.StatusInternalServerError, ErrDummy.Wrap(err))
+		}
+		return
+	}
+
+	if !result.Applied {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	}
+
+	if err = json.NewEncoder(w).Encode(result); err != nil {
+		controller.log.Error("failed to write json response", ErrDummy.Wrap(err))
+		return
+	}
+}
+
+// streamHeartbeat is how often a comment is sent to idle stream clients, so proxies keep the connection open.
+const streamHeartbeat = 15 * time.Second
+
+// Stream pushes dummy changes to the client as server-sent events with change ids as event ids.
+// A client resuming with the Last-Event-ID header, or the lastEventId parameter, receives missed changes first,
+// or 410 if the change it saw last was pruned.
+func (controller *Dummy) Stream(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		controller.serveError(w, http.StatusInternalServerError, ErrDummy.New("streaming is not supported"))
+		return
+	}
+
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("lastEventId")
+	}
+
+	var after int64
+	if lastEventID != "" {
+		var err error
+		if after, err = strconv.ParseInt(lastEventID, 10, 64); err != nil || after < 0 {
+			controller.serveError(w, http.StatusBadRequest, ErrDummy.New("invalid last event id %q", lastEventID))
+			return
+		}
+	}
+
+	subscription, err := controller.stream.Subscribe(ctx, after)
+	if dummy.ErrChangesPruned.Has(err) {
+		// the client must fetch dummies again, changes it missed may be lost.
+		controller.serveError(w, http.StatusGone, ErrDummy.Wrap(err))
+		return
+	}
+	if err != nil {
+		controller.log.Error("could not subscribe to dummy changes", ErrDummy.Wrap(err))
+		controller.serveError(w, http.StatusServiceUnavailable, ErrDummy.Wrap(err))
+		return
+	}
+	defer subscription.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		nextCtx, cancel := context.WithTimeout(ctx, streamHeartbeat)
+		change, err := subscription.Next(nextCtx)
+		cancel()
+
+		switch {
+		case err == nil:
+			err = writeEvent(w, change)
+		case errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil:
+			_, err = io.WriteString(w, ": heartbeat\n\n")
+		default:
+			// the client reconnects and resumes when the stream is closed.
+			return
+		}
+
+		if err != nil {
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+// writeEvent writes the change as a server-sent event.
+func writeEvent(w io.Writer, change dummy.Change) error {
+	data, err := json.Marshal(change)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", change.ID, change.EventType(), data)
+	return err
+}
+
+// requestFormat returns format of the request body from the "format" query parameter or the Content-Type header.
+func requestFormat(r *http.Request) (dummy.Format, error) {
+	if name := r.URL.Query().Get("format"); name != "" {
+		return dummy.ParseFormat(name)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case dummy.FormatCSV.ContentType():
+		return dummy.FormatCSV, nil
+	case dummy.FormatNDJSON.ContentType():
+		return dummy.FormatNDJSON, nil
+	default:
+		return "", ErrDummy.New("format is required, use the format parameter or a text/csv or application/x-ndjson content type")
+	}
+}
+
 // serveError replies to request with specific code and error.
 func (controller *Dummy) serveError(w http.ResponseWriter, status int, err error) {
 	w.WriteHeader(status)
@@ -0,0 +1,102 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/zeebo/errs"
+
+	"project_template/jobs"
+	"project_template/pkg/logger"
+)
+
+var (
+	// ErrJobs is an internal error type for jobs controller.
+	ErrJobs = errs.Class("jobs controller error")
+)
+
+// Jobs is a mvc controller that exposes scheduled jobs and the history of their runs.
+type Jobs struct {
+	log logger.Logger
+
+	scheduler *jobs.Scheduler
+}
+
+// NewJobs is a constructor for jobs controller.
+func NewJobs(log logger.Logger, scheduler *jobs.Scheduler) *Jobs {
+	return &Jobs{
+		log:       log,
+		scheduler: scheduler,
+	}
+}
+
+// List returns registered jobs with their last runs.
+func (controller *Jobs) List(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	result, err := controller.scheduler.Jobs(ctx)
+	if err != nil {
+		controller.log.Error("could not get list of jobs", ErrJobs.Wrap(err))
+		controller.serveError(w, http.StatusInternalServerError, ErrJobs.Wrap(err))
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(result); err != nil {
+		controller.log.Error("failed to write json response", ErrJobs.Wrap(err))
+		return
+	}
+}
+
+// Runs returns the latest runs of the job, the most recent first.
+func (controller *Jobs) Runs(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var limit int
+	if param := r.URL.Query().Get("limit"); param != "" {
+		var err error
+		if limit, err = strconv.Atoi(param); err != nil {
+			controller.serveError(w, http.StatusBadRequest, ErrJobs.New("invalid limit: %v", err))
+			return
+		}
+	}
+
+	result, err := controller.scheduler.Runs(ctx, mux.Vars(r)["name"], limit)
+	if err != nil {
+		controller.log.Error("could not get job runs", ErrJobs.Wrap(err))
+		switch {
+		case jobs.ErrInvalidJob.Has(err):
+			controller.serveError(w, http.StatusBadRequest, ErrJobs.Wrap(err))
+		case jobs.ErrNoJob.Has(err):
+			controller.serveError(w, http.StatusNotFound, ErrJobs.Wrap(err))
+		default:
+			controller.serveError(w, http.StatusInternalServerError, ErrJobs.Wrap(err))
+		}
+		return
+	}
+
+	if result == nil {
+		result = make([]jobs.Run, 0)
+	}
+
+	if err = json.NewEncoder(w).Encode(result); err != nil {
+		controller.log.Error("failed to write json response", ErrJobs.Wrap(err))
+		return
+	}
+}
+
+// serveError replies to request with specific code and error.
+func (controller *Jobs) serveError(w http.ResponseWriter, status int, err error) {
+	w.WriteHeader(status)
+
+	var response struct {
+		Error string `json:"error"`
+	}
+
+	response.Error = err.Error()
+
+	if err = json.NewEncoder(w).Encode(response); err != nil {
+		controller.log.Error("failed to write json error response", ErrJobs.Wrap(err))
+	}
+}
@@ -0,0 +1,25 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// bodyTooLarge is the message of the error returned by a body limited with http.MaxBytesReader.
+const bodyTooLarge = "http: request body too large"
+
+// decodeJSON decodes the json request body, fields which the value doesn't have are rejected.
+func decodeJSON(r *http.Request, v interface{}) error {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(v)
+}
+
+// bodyErrorStatus returns the status of a response to a request whose body could not be read or decoded.
+func bodyErrorStatus(err error) int {
+	if strings.Contains(err.Error(), bodyTooLarge) {
+		return http.StatusRequestEntityTooLarge
+	}
+	return http.StatusBadRequest
+}
@@ -0,0 +1,246 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/zeebo/errs"
+
+	"project_template/pkg/logger"
+	"project_template/webhooks"
+)
+
+var (
+	// ErrWebhooks is an internal error type for webhooks controller.
+	ErrWebhooks = errs.Class("webhooks controller error")
+)
+
+// Webhooks is a mvc controller that handles webhook subscriptions and their deliveries.
+type Webhooks struct {
+	log logger.Logger
+
+	webhooks *webhooks.Service
+}
+
+// NewWebhooks is a constructor for webhooks controller.
+func NewWebhooks(log logger.Logger, webhooks *webhooks.Service) *Webhooks {
+	return &Webhooks{
+		log:      log,
+		webhooks: webhooks,
+	}
+}
+
+// subscriptionRequest is a body of create and update requests, the secret is generated
+// on creation and kept on update if it's empty.
+type subscriptionRequest struct {
+	URL        string   `json:"url"`
+	EventTypes []string `json:"eventTypes"`
+	Secret     string   `json:"secret"`
+	Active     *bool    `json:"active"`
+}
+
+func (controller *Webhooks) List(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	result, err := controller.webhooks.List(ctx)
+	if err != nil {
+		controller.log.Error("could not get list of webhook subscriptions", ErrWebhooks.Wrap(err))
+		controller.serveError(w, http.StatusInternalServerError, ErrWebhooks.Wrap(err))
+		return
+	}
+
+	if result == nil {
+		result = make([]webhooks.Subscription, 0)
+	}
+
+	if err = json.NewEncoder(w).Encode(result); err != nil {
+		controller.log.Error("failed to write json response", ErrWebhooks.Wrap(err))
+		return
+	}
+}
+
+// Create creates a subscription, the response is the only one which contains its secret.
+func (controller *Webhooks) Create(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req subscriptionRequest
+	if err := decodeJSON(r, &req); err != nil {
+		controller.serveError(w, bodyErrorStatus(err), ErrWebhooks.Wrap(err))
+		return
+	}
+
+	result, err := controller.webhooks.Create(ctx, req.URL, req.EventTypes, req.Secret)
+	if err != nil {
+		controller.log.Error("could not create webhook subscription", ErrWebhooks.Wrap(err))
+		controller.serveServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	if err = json.NewEncoder(w).Encode(result); err != nil {
+		controller.log.Error("failed to write json response", ErrWebhooks.Wrap(err))
+		return
+	}
+}
+
+func (controller *Webhooks) Get(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		controller.serveError(w, http.StatusBadRequest, ErrWebhooks.Wrap(err))
+		return
+	}
+
+	result, err := controller.webhooks.Get(ctx, id)
+	if err != nil {
+		controller.log.Error("could not get webhook subscription", ErrWebhooks.Wrap(err))
+		controller.serveServiceError(w, err)
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(result); err != nil {
+		controller.log.Error("failed to write json response", ErrWebhooks.Wrap(err))
+		return
+	}
+}
+
+// Update replaces url, event types and active flag of the subscription, the secret is rotated if it's set.
+func (controller *Webhooks) Update(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		controller.serveError(w, http.StatusBadRequest, ErrWebhooks.Wrap(err))
+		return
+	}
+
+	var req subscriptionRequest
+	if err = decodeJSON(r, &req); err != nil {
+		controller.serveError(w, bodyErrorStatus(err), ErrWebhooks.Wrap(err))
+		return
+	}
+
+	active := req.Active == nil || *req.Active
+	result, err := controller.webhooks.Update(ctx, id, req.URL, req.EventTypes, active, req.Secret)
+	if err != nil {
+		controller.log.Error("could not update webhook subscription", ErrWebhooks.Wrap(err))
+		controller.serveServiceError(w, err)
+		return
+	}
+
+	if err = json.NewEncoder(w).Encode(result); err != nil {
+		controller.log.Error("failed to write json response", ErrWebhooks.Wrap(err))
+		return
+	}
+}
+
+func (controller *Webhooks) Delete(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		controller.serveError(w, http.StatusBadRequest, ErrWebhooks.Wrap(err))
+		return
+	}
+
+	if err = controller.webhooks.Delete(ctx, id); err != nil {
+		controller.log.Error("could not delete webhook subscription", ErrWebhooks.Wrap(err))
+		controller.serveServiceError(w, err)
+		return
+	}
+}
+
+// Deliveries returns delivery history of the subscription, newest first, paginated with "limit" and "offset" parameters.
+func (controller *Webhooks) Deliveries(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := r.URL.Query()
+
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		controller.serveError(w, http.StatusBadRequest, ErrWebhooks.Wrap(err))
+		return
+	}
+
+	var limit, offset int
+	for name, value := range map[string]*int{"limit": &limit, "offset": &offset} {
+		if param := query.Get(name); param != "" {
+			if *value, err = strconv.Atoi(param); err != nil {
+				controller.serveError(w, http.StatusBadRequest, ErrWebhooks.New("invalid %s: %v", name, err))
+				return
+			}
+		}
+	}
+
+	result, err := controller.webhooks.Deliveries(ctx, id, limit, offset)
+	if err != nil {
+		controller.log.Error("could not get webhook deliveries", ErrWebhooks.Wrap(err))
+		controller.serveServiceError(w, err)
+		return
+	}
+
+	if result == nil {
+		result = make([]webhooks.Delivery, 0)
+	}
+
+	if err = json.NewEncoder(w).Encode(result); err != nil {
+		controller.log.Error("failed to write json response", ErrWebhooks.Wrap(err))
+		return
+	}
+}
+
+// Redeliver schedules the delivery for an immediate attempt.
+func (controller *Webhooks) Redeliver(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	vars := mux.Vars(r)
+
+	id, err := uuid.Parse(vars["id"])
+	if err != nil {
+		controller.serveError(w, http.StatusBadRequest, ErrWebhooks.Wrap(err))
+		return
+	}
+
+	deliveryID, err := uuid.Parse(vars["deliveryID"])
+	if err != nil {
+		controller.serveError(w, http.StatusBadRequest, ErrWebhooks.Wrap(err))
+		return
+	}
+
+	if err = controller.webhooks.Redeliver(ctx, id, deliveryID); err != nil {
+		controller.log.Error("could not redeliver webhook delivery", ErrWebhooks.Wrap(err))
+		controller.serveServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// serveServiceError replies with the status matching the class of the service error.
+func (controller *Webhooks) serveServiceError(w http.ResponseWriter, err error) {
+	switch {
+	case webhooks.ErrInvalidSubscription.Has(err):
+		controller.serveError(w, http.StatusBadRequest, ErrWebhooks.Wrap(err))
+	case webhooks.ErrNoSubscription.Has(err), webhooks.ErrNoDelivery.Has(err):
+		controller.serveError(w, http.StatusNotFound, ErrWebhooks.Wrap(err))
+	default:
+		controller.serveError(w, http.StatusInternalServerError, ErrWebhooks.Wrap(err))
+	}
+}
+
+// serveError replies to request with specific code and error.
+func (controller *Webhooks) serveError(w http.ResponseWriter, status int, err error) {
+	w.WriteHeader(status)
+
+	var response struct {
+		Error string `json:"error"`
+	}
+
+	response.Error = err.Error()
+
+	if err = json.NewEncoder(w).Encode(response); err != nil {
+		controller.log.Error("failed to write json error response", ErrWebhooks.Wrap(err))
+	}
+}
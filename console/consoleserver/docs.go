@@ -0,0 +1,92 @@
+package consoleserver
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/zeebo/errs"
+
+	"project_template/pkg/openapi"
+)
+
+// OpenAPISpec is the OpenAPI 3 document of the api, it's served at /api/v0/openapi.json.
+//
+//go:embed openapi.json
+var OpenAPISpec []byte
+
+// swaggerFiles is the Swagger UI page with swagger-ui.css, swagger-ui-bundle.js and favicons of
+// swagger-ui-dist 5.18.2, they are updated by copying the files of a newer version of the package.
+//
+//go:embed swagger
+var swaggerFiles embed.FS
+
+// docsContentSecurityPolicy allows the Swagger UI page to load only its own scripts and styles and
+// to call the api, other pages allow no content. Swagger UI sets inline styles and uses data: images.
+const docsContentSecurityPolicy = "default-src 'none'; script-src 'self'; style-src 'self' 'unsafe-inline'; " +
+	"img-src 'self' data:; connect-src 'self'; frame-ancestors 'none'"
+
+// serveOpenAPI serves the OpenAPI document of the api.
+func (server *Server) serveOpenAPI(w http.ResponseWriter, r *http.Request) {
+	if _, err := w.Write(OpenAPISpec); err != nil {
+		server.log.Error("failed to write openapi document", Error.Wrap(err))
+	}
+}
+
+// swaggerUI serves the Swagger UI page of the OpenAPI document.
+func (server *Server) swaggerUI() http.Handler {
+	files, err := fs.Sub(swaggerFiles, "swagger")
+	if err != nil {
+		// the directory is embedded, so it's always there.
+		panic(err)
+	}
+
+	fileServer := http.StripPrefix("/docs/", http.FileServer(http.FS(files)))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Security-Policy", docsContentSecurityPolicy)
+		fileServer.ServeHTTP(w, r)
+	})
+}
+
+// checkRoutes checks that api routes of the router and operations of the OpenAPI document are the same,
+// so the document can't get stale.
+func checkRoutes(router *mux.Router, doc *openapi.Document) error {
+	documented := make(map[openapi.Route]bool)
+	for _, route := range doc.Routes() {
+		documented[route] = true
+	}
+
+	var group errs.Group
+	err := router.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil || !strings.HasPrefix(path, doc.BasePath()+"/") {
+			return nil
+		}
+		// subrouters and prefixes have no methods.
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+
+		for _, method := range methods {
+			served := openapi.Route{Method: method, Path: path}
+			if !documented[served] {
+				group.Add(Error.New("route %s %s is not documented in openapi.json", method, path))
+			}
+			delete(documented, served)
+		}
+		return nil
+	})
+	if err != nil {
+		return Error.Wrap(err)
+	}
+
+	for _, route := range doc.Routes() {
+		if documented[route] {
+			group.Add(Error.New("route %s %s of openapi.json is not served", route.Method, route.Path))
+		}
+	}
+	return group.Err()
+}
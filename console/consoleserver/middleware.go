@@ -0,0 +1,251 @@
+package consoleserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"project_template/pkg/auth"
+	"project_template/ratelimit"
+)
+
+// names of routes which are handled differently by middlewares.
+const (
+	routeDummyStream = "dummy.stream"
+	routeDummyImport = "dummy.import"
+	routeDummyExport = "dummy.export"
+)
+
+// routeName returns the name of the matched route, it's empty for unnamed routes.
+func routeName(r *http.Request) string {
+	if route := mux.CurrentRoute(r); route != nil {
+		return route.GetName()
+	}
+	return ""
+}
+
+// withRecovery logs a panic of a handler with its stack and responds with 500 instead of dropping the connection.
+func (server *Server) withRecovery(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			// the handler aborted the response on purpose, the server handles it.
+			if rec == http.ErrAbortHandler {
+				panic(rec)
+			}
+
+			server.log.Error(fmt.Sprintf("panic while handling %s %s", r.Method, r.URL.Path), Error.New("%v\n%s", rec, debug.Stack()))
+			server.serveError(w, http.StatusInternalServerError, Error.New("internal server error"))
+		}()
+
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// withTimeout cancels the context of a request after RequestTimeout, streaming routes aren't limited.
+func (server *Server) withTimeout(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch routeName(r) {
+		case routeDummyStream, routeDummyImport, routeDummyExport:
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), server.config.RequestTimeout)
+		defer cancel()
+
+		handler.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// limitBody limits size of the request body by MaxBodySize, or by MaxImportSize for import.
+// Requests which declare a larger body are rejected right away, others fail when the limit is reached.
+func (server *Server) limitBody(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limit := server.config.MaxBodySize
+		if routeName(r) == routeDummyImport {
+			limit = server.config.MaxImportSize
+		}
+
+		if r.ContentLength > limit {
+			server.serveError(w, http.StatusRequestEntityTooLarge, Error.New("request body is larger than %d bytes", limit))
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, limit)
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// withSecurityHeaders sets security headers of every response, HSTS is sent only over TLS.
+func (server *Server) withSecurityHeaders(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := w.Header()
+		header.Set("X-Content-Type-Options", "nosniff")
+		header.Set("Referrer-Policy", "no-referrer")
+		if server.config.Security.FrameOptions != "" {
+			header.Set("X-Frame-Options", server.config.Security.FrameOptions)
+		}
+		if server.config.Security.ContentSecurityPolicy != "" {
+			header.Set("Content-Security-Policy", server.config.Security.ContentSecurityPolicy)
+		} else {
+			header.Set("Content-Security-Policy", defaultContentSecurityPolicy)
+		}
+		if r.TLS != nil && server.config.Security.HSTSMaxAge > 0 {
+			header.Set("Strict-Transport-Security", fmt.Sprintf("max-age=%d; includeSubDomains", int(server.config.Security.HSTSMaxAge.Seconds())))
+		}
+
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// withCORS allows cross-origin requests from the allowed origins and answers their preflight requests.
+// Requests from other origins are served without CORS headers, so browsers don't let scripts read the responses.
+func (server *Server) withCORS(handler http.Handler) http.Handler {
+	config := server.config.CORS
+	if len(config.AllowedOrigins) == 0 {
+		return handler
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		header := w.Header()
+		header.Add("Vary", "Origin")
+
+		preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+		if preflight {
+			header.Add("Vary", "Access-Control-Request-Method")
+			header.Add("Vary", "Access-Control-Request-Headers")
+		}
+
+		allowed := server.allowedOrigin(origin)
+		if allowed {
+			if allowsAnyOrigin(config.AllowedOrigins) {
+				header.Set("Access-Control-Allow-Origin", "*")
+			} else {
+				header.Set("Access-Control-Allow-Origin", origin)
+			}
+			if config.AllowCredentials {
+				header.Set("Access-Control-Allow-Credentials", "true")
+			}
+		}
+
+		if preflight {
+			if allowed {
+				header.Set("Access-Control-Allow-Methods", strings.Join(config.AllowedMethods, ", "))
+				header.Set("Access-Control-Allow-Headers", strings.Join(config.AllowedHeaders, ", "))
+				header.Set("Access-Control-Max-Age", strconv.Itoa(int(config.MaxAge.Seconds())))
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		if allowed && len(config.ExposedHeaders) > 0 {
+			header.Set("Access-Control-Expose-Headers", strings.Join(config.ExposedHeaders, ", "))
+		}
+
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// allowedOrigin checks if cross-origin requests are allowed from the origin.
+func (server *Server) allowedOrigin(origin string) bool {
+	for _, allowed := range server.config.CORS.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowsAnyOrigin checks if the origins contain "*".
+func allowsAnyOrigin(origins []string) bool {
+	for _, origin := range origins {
+		if origin == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// rateLimit limits requests of the route group per principal of the verified client certificate, or per client ip
+// for other requests. The state of the bucket is sent in RateLimit-* headers, rejected requests get 429.
+// Requests are allowed if the limiter fails, so its storage doesn't take the api down.
+func (server *Server) rateLimit(group string, perIP, perPrincipal ratelimit.Limit) mux.MiddlewareFunc {
+	return func(handler http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key, limit := group+":ip:"+clientIP(r), perIP
+			if identity, ok := auth.IdentityFromContext(r.Context()); ok {
+				key, limit = group+":principal:"+identity.Subject, perPrincipal
+			}
+
+			result, err := server.services.RateLimiter.Allow(r.Context(), key, limit)
+			if err != nil {
+				server.log.Error("could not check rate limit", Error.Wrap(err))
+				handler.ServeHTTP(w, r)
+				return
+			}
+
+			if !limit.Unlimited() {
+				w.Header().Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+				w.Header().Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+				w.Header().Set("RateLimit-Reset", ceilSeconds(result.Reset))
+			}
+
+			if !result.Allowed {
+				w.Header().Set("Retry-After", ceilSeconds(result.RetryAfter))
+				server.serveError(w, http.StatusTooManyRequests, Error.New("rate limit of %s is exceeded", limit))
+				return
+			}
+
+			handler.ServeHTTP(w, r)
+		})
+	}
+}
+
+// clientIP returns the ip of the client connection.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// ceilSeconds formats the duration as whole seconds rounded up, as headers expect.
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
+
+// serveError writes the error as json, it's used by middlewares, controllers have their own.
+func (server *Server) serveError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	var response struct {
+		Error string `json:"error"`
+	}
+
+	response.Error = err.Error()
+
+	if err = json.NewEncoder(w).Encode(response); err != nil {
+		server.log.Error("failed to write json error response", Error.Wrap(err))
+	}
+}
@@ -2,18 +2,25 @@ package consoleserver
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"net"
 	"net/http"
+	"time"
 
 	"github.com/gorilla/mux"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/zeebo/errs"
 	"golang.org/x/sync/errgroup"
 
 	"project_template/console/consoleserver/controllers"
 	"project_template/dummy"
+	"project_template/jobs"
+	"project_template/pkg/auth"
 	"project_template/pkg/logger"
+	"project_template/pkg/openapi"
+	"project_template/pkg/tlsreload"
+	"project_template/ratelimit"
+	"project_template/webhooks"
 )
 
 var (
@@ -23,7 +30,66 @@ var (
 
 // Config contains configuration for console web server.
 type Config struct {
-	Address string `env:"CONSOLE_SERVER_ADDRESS" validate:"required"`
+	Address string `env:"CONSOLE_SERVER_ADDRESS" validate:"required" envDefault:"localhost:8088" desc:"address the console web server listens on"`
+	TLS     TLSConfig
+
+	ReadHeaderTimeout time.Duration `env:"CONSOLE_SERVER_READ_HEADER_TIMEOUT" envDefault:"10s" desc:"how long reading of request headers may take"`
+	ReadTimeout       time.Duration `env:"CONSOLE_SERVER_READ_TIMEOUT" envDefault:"1m" desc:"how long reading of the whole request, including the body, may take"`
+	IdleTimeout       time.Duration `env:"CONSOLE_SERVER_IDLE_TIMEOUT" envDefault:"2m" desc:"how long a keep-alive connection waits for the next request"`
+	RequestTimeout    time.Duration `env:"CONSOLE_SERVER_REQUEST_TIMEOUT" envDefault:"30s" desc:"how long handling of a request may take, the stream of changes, import and export are not limited"`
+	MaxBodySize       int64         `env:"CONSOLE_SERVER_MAX_BODY_SIZE" validate:"min=1" envDefault:"1048576" desc:"maximum size of a request body in bytes"`
+	MaxImportSize     int64         `env:"CONSOLE_SERVER_MAX_IMPORT_SIZE" validate:"min=1" envDefault:"67108864" desc:"maximum size of an import request body in bytes"`
+	Docs              bool          `env:"CONSOLE_SERVER_DOCS" envDefault:"true" desc:"serves the Swagger UI of the api at /docs/"`
+
+	RateLimit RateLimitConfig
+	CORS      CORSConfig
+	Security  SecurityConfig
+}
+
+// CORSConfig contains configuration of cross-origin requests from browsers, they are allowed only from AllowedOrigins.
+type CORSConfig struct {
+	AllowedOrigins   []string      `env:"CONSOLE_SERVER_CORS_ALLOWED_ORIGINS" desc:"comma separated origins which browsers may call the api from, e.g. https://ui.example.com, * allows any origin"`
+	AllowedMethods   []string      `env:"CONSOLE_SERVER_CORS_ALLOWED_METHODS" envDefault:"GET,POST,PUT,DELETE" desc:"comma separated methods allowed in cross-origin requests"`
+	AllowedHeaders   []string      `env:"CONSOLE_SERVER_CORS_ALLOWED_HEADERS" envDefault:"Content-Type,Authorization,Last-Event-ID" desc:"comma separated request headers allowed in cross-origin requests"`
+	ExposedHeaders   []string      `env:"CONSOLE_SERVER_CORS_EXPOSED_HEADERS" envDefault:"RateLimit-Limit,RateLimit-Remaining,RateLimit-Reset,Retry-After" desc:"comma separated response headers readable by cross-origin scripts"`
+	AllowCredentials bool          `env:"CONSOLE_SERVER_CORS_ALLOW_CREDENTIALS" envDefault:"false" desc:"allows cookies and client certificates in cross-origin requests, it can't be used with any origin"`
+	MaxAge           time.Duration `env:"CONSOLE_SERVER_CORS_MAX_AGE" envDefault:"10m" desc:"how long browsers cache the result of a preflight request"`
+}
+
+// SecurityConfig contains security headers of responses.
+type SecurityConfig struct {
+	HSTSMaxAge            time.Duration `env:"CONSOLE_SERVER_HSTS_MAX_AGE" envDefault:"8760h" desc:"max-age of the Strict-Transport-Security header sent over TLS, 0 disables it"`
+	FrameOptions          string        `env:"CONSOLE_SERVER_FRAME_OPTIONS" envDefault:"DENY" desc:"value of the X-Frame-Options header"`
+	ContentSecurityPolicy string        `env:"CONSOLE_SERVER_CONTENT_SECURITY_POLICY" desc:"value of the Content-Security-Policy header, the api allows no content by default"`
+}
+
+// defaultContentSecurityPolicy allows no content, the api serves only json. It isn't a default of the config
+// as values with spaces can't be exported from env. files.
+const defaultContentSecurityPolicy = "default-src 'none'; frame-ancestors 'none'"
+
+// RateLimitConfig contains limits of route groups as "requests/period" or "off". Requests with a verified
+// client certificate are limited per principal, other requests per client ip.
+type RateLimitConfig struct {
+	DummyIP           ratelimit.Limit `env:"CONSOLE_SERVER_RATE_LIMIT_DUMMY_IP" envDefault:"300/1m" desc:"limit of dummy requests per client ip"`
+	DummyPrincipal    ratelimit.Limit `env:"CONSOLE_SERVER_RATE_LIMIT_DUMMY_PRINCIPAL" envDefault:"1200/1m" desc:"limit of dummy requests per principal"`
+	WebhooksIP        ratelimit.Limit `env:"CONSOLE_SERVER_RATE_LIMIT_WEBHOOKS_IP" envDefault:"60/1m" desc:"limit of webhooks requests per client ip"`
+	WebhooksPrincipal ratelimit.Limit `env:"CONSOLE_SERVER_RATE_LIMIT_WEBHOOKS_PRINCIPAL" envDefault:"300/1m" desc:"limit of webhooks requests per principal"`
+	JobsIP            ratelimit.Limit `env:"CONSOLE_SERVER_RATE_LIMIT_JOBS_IP" envDefault:"60/1m" desc:"limit of jobs requests per client ip"`
+	JobsPrincipal     ratelimit.Limit `env:"CONSOLE_SERVER_RATE_LIMIT_JOBS_PRINCIPAL" envDefault:"300/1m" desc:"limit of jobs requests per principal"`
+}
+
+// TLSConfig contains configuration of TLS, the server accepts plain http if the certificate isn't set.
+type TLSConfig struct {
+	CertFile       string        `env:"CONSOLE_SERVER_TLS_CERT_FILE" validate:"required_with=KeyFile ClientCAFile" desc:"path of the PEM encoded server certificate, TLS is enabled when it's set"`
+	KeyFile        string        `env:"CONSOLE_SERVER_TLS_KEY_FILE" validate:"required_with=CertFile" desc:"path of the PEM encoded private key of the server certificate"`
+	MinVersion     string        `env:"CONSOLE_SERVER_TLS_MIN_VERSION" validate:"oneof=1.2 1.3" envDefault:"1.2" desc:"minimum TLS version, 1.2 or 1.3"`
+	ClientCAFile   string        `env:"CONSOLE_SERVER_TLS_CLIENT_CA_FILE" desc:"path of the PEM encoded CA bundle, client certificates signed by it are required when it's set (mTLS)"`
+	ReloadInterval time.Duration `env:"CONSOLE_SERVER_TLS_RELOAD_INTERVAL" envDefault:"1m" desc:"how often the certificate files are checked for changes"`
+}
+
+// Enabled checks if TLS is configured.
+func (config TLSConfig) Enabled() bool {
+	return config.CertFile != ""
 }
 
 // Server represents console web server.
@@ -35,44 +101,135 @@ type Server struct {
 
 	listener net.Listener
 	server   http.Server
+	// certs is nil if TLS is disabled.
+	certs *tlsreload.Reloader
+
+	services Services
+}
 
-	dummyService *dummy.Service
+// Services are the services served by the console server. A new service is added as a field,
+// so constructing the server doesn't change for callers which don't use it.
+type Services struct {
+	Dummy       *dummy.Service
+	DummyStream *dummy.Stream
+	Webhooks    *webhooks.Service
+	Jobs        *jobs.Scheduler
+	RateLimiter *ratelimit.Limiter
 }
 
-// NewServer is a constructor for console web server.
-func NewServer(config Config, log logger.Logger, listener net.Listener, dummyService *dummy.Service) *Server {
+// NewServer is a constructor for console web server, it fails if TLS is enabled and certificates can't be loaded
+// or if routes don't match the OpenAPI document.
+func NewServer(config Config, log logger.Logger, listener net.Listener, services Services) (*Server, error) {
+	if config.CORS.AllowCredentials && allowsAnyOrigin(config.CORS.AllowedOrigins) {
+		return nil, Error.New("cors credentials can't be allowed for any origin")
+	}
+
 	server := &Server{
-		log:          log,
-		config:       config,
-		listener:     listener,
-		dummyService: dummyService,
+		log:      log,
+		config:   config,
+		listener: listener,
+		services: services,
 	}
 
 	// controllers
-	dummyController := controllers.NewDummy(server.log, dummyService)
+	dummyController := controllers.NewDummy(server.log, services.Dummy, services.DummyStream)
+	webhooksController := controllers.NewWebhooks(server.log, services.Webhooks)
+	jobsController := controllers.NewJobs(server.log, services.Jobs)
 
 	// routes
 	router := mux.NewRouter()
 
-	// Prometheus' metrics endpoint
-	router.Handle("/metrics", promhttp.Handler())
-
 	apiRouter := router.PathPrefix("/api/v0").Subrouter()
-	apiRouter.Use(server.jsonResponse)
+	apiRouter.Use(server.jsonResponse, server.withTimeout, server.limitBody)
+	apiRouter.HandleFunc("/openapi.json", server.serveOpenAPI).Methods(http.MethodGet)
 
 	dummyRouter := apiRouter.PathPrefix("/dummy").Subrouter()
-	dummyRouter.Use(server.withAuth)
+	dummyRouter.Use(server.withAuth, server.rateLimit("dummy", config.RateLimit.DummyIP, config.RateLimit.DummyPrincipal))
 	dummyRouter.HandleFunc("", dummyController.List).Methods(http.MethodGet)
 	dummyRouter.HandleFunc("", dummyController.Create).Methods(http.MethodPost)
+	dummyRouter.HandleFunc("/search", dummyController.Search).Methods(http.MethodGet)
+	dummyRouter.HandleFunc("/stream", dummyController.Stream).Methods(http.MethodGet).Name(routeDummyStream)
+	dummyRouter.HandleFunc("/batch", dummyController.Batch).Methods(http.MethodPost)
+	dummyRouter.HandleFunc("/import", dummyController.Import).Methods(http.MethodPost).Name(routeDummyImport)
+	dummyRouter.HandleFunc("/export", dummyController.Export).Methods(http.MethodGet).Name(routeDummyExport)
 	dummyRouter.HandleFunc("/{id}", dummyController.Get).Methods(http.MethodGet)
 	dummyRouter.HandleFunc("/{id}", dummyController.Update).Methods(http.MethodPut)
 	dummyRouter.HandleFunc("/{id}", dummyController.Delete).Methods(http.MethodDelete)
 
+	webhooksRouter := apiRouter.PathPrefix("/webhooks").Subrouter()
+	webhooksRouter.Use(server.withAuth, server.rateLimit("webhooks", config.RateLimit.WebhooksIP, config.RateLimit.WebhooksPrincipal))
+	webhooksRouter.HandleFunc("", webhooksController.List).Methods(http.MethodGet)
+	webhooksRouter.HandleFunc("", webhooksController.Create).Methods(http.MethodPost)
+	webhooksRouter.HandleFunc("/{id}", webhooksController.Get).Methods(http.MethodGet)
+	webhooksRouter.HandleFunc("/{id}", webhooksController.Update).Methods(http.MethodPut)
+	webhooksRouter.HandleFunc("/{id}", webhooksController.Delete).Methods(http.MethodDelete)
+	webhooksRouter.HandleFunc("/{id}/deliveries", webhooksController.Deliveries).Methods(http.MethodGet)
+	webhooksRouter.HandleFunc("/{id}/deliveries/{deliveryID}/redeliver", webhooksController.Redeliver).Methods(http.MethodPost)
+
+	jobsRouter := apiRouter.PathPrefix("/jobs").Subrouter()
+	jobsRouter.Use(server.withAuth, server.rateLimit("jobs", config.RateLimit.JobsIP, config.RateLimit.JobsPrincipal))
+	jobsRouter.HandleFunc("", jobsController.List).Methods(http.MethodGet)
+	jobsRouter.HandleFunc("/{name}/runs", jobsController.Runs).Methods(http.MethodGet)
+
+	if config.Docs {
+		router.Handle("/docs", http.RedirectHandler("/docs/", http.StatusMovedPermanently)).Methods(http.MethodGet)
+		router.PathPrefix("/docs/").Handler(server.swaggerUI()).Methods(http.MethodGet)
+	}
+
+	spec, err := openapi.Parse(OpenAPISpec)
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+	if err = checkRoutes(router, spec); err != nil {
+		return nil, err
+	}
+
+	// WriteTimeout isn't set as it would break the stream of changes, handlers are limited by RequestTimeout instead.
 	server.server = http.Server{
-		Handler: router,
+		Handler:           server.withRecovery(server.withSecurityHeaders(server.withCORS(router))),
+		ReadHeaderTimeout: config.ReadHeaderTimeout,
+		ReadTimeout:       config.ReadTimeout,
+		IdleTimeout:       config.IdleTimeout,
 	}
 
-	return server
+	if config.TLS.Enabled() {
+		server.certs, err = tlsreload.New(log, tlsreload.Files{
+			CertFile:     config.TLS.CertFile,
+			KeyFile:      config.TLS.KeyFile,
+			ClientCAFile: config.TLS.ClientCAFile,
+		})
+		if err != nil {
+			return nil, Error.Wrap(err)
+		}
+
+		server.server.TLSConfig = server.tlsConfig()
+	}
+
+	return server, nil
+}
+
+// tlsConfig returns TLS config which uses the current certificate and client CAs of the reloader.
+func (server *Server) tlsConfig() *tls.Config {
+	config := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: server.certs.GetCertificate,
+	}
+	if server.config.TLS.MinVersion == "1.3" {
+		config.MinVersion = tls.VersionTLS13
+	}
+
+	if server.config.TLS.ClientCAFile != "" {
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+		// client CAs are taken per connection, so reloaded ones are used by new connections.
+		config.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			clientConfig := config.Clone()
+			clientConfig.GetConfigForClient = nil
+			clientConfig.ClientCAs = server.certs.ClientCAs()
+			return clientConfig, nil
+		}
+	}
+
+	return config
 }
 
 // Run starts the server that host api endpoints.
@@ -83,9 +240,24 @@ func (server *Server) Run(ctx context.Context) (err error) {
 		<-ctx.Done()
 		return Error.Wrap(server.server.Shutdown(context.Background()))
 	})
+	if server.certs != nil {
+		group.Go(func() error {
+			err := server.certs.Run(ctx, server.config.TLS.ReloadInterval)
+			if errors.Is(err, context.Canceled) {
+				err = nil
+			}
+			return Error.Wrap(err)
+		})
+	}
 	group.Go(func() error {
 		defer cancel()
-		err := server.server.Serve(server.listener)
+		var err error
+		if server.certs != nil {
+			// certificates are provided by the TLS config.
+			err = server.server.ServeTLS(server.listener, "", "")
+		} else {
+			err = server.server.Serve(server.listener)
+		}
 		isCancelled := errs.IsFunc(err, func(err error) bool { return errors.Is(err, context.Canceled) })
 		if isCancelled || errors.Is(err, http.ErrServerClosed) {
 			err = nil
@@ -96,16 +268,26 @@ func (server *Server) Run(ctx context.Context) (err error) {
 	return Error.Wrap(group.Wait())
 }
 
+// Handler returns the handler of all routes with middlewares, e.g. to serve the api from httptest.
+func (server *Server) Handler() http.Handler {
+	return server.server.Handler
+}
+
 // Close closes server and underlying listener.
 func (server *Server) Close() error {
 	return Error.Wrap(server.server.Close())
 }
 
-// withAuth performs initial authorization before every request.
+// withAuth performs initial authorization before every request. With mTLS the identity of the verified
+// client certificate is available from the context, see auth.IdentityFromContext.
 func (server *Server) withAuth(handler http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 
+		if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 && len(r.TLS.VerifiedChains[0]) > 0 {
+			ctx = auth.WithIdentity(ctx, auth.IdentityFromCertificate(r.TLS.VerifiedChains[0][0]))
+		}
+
 		/* TODO: Implement auth logic here */
 
 		handler.ServeHTTP(w, r.Clone(ctx))
@@ -0,0 +1,152 @@
+package consoleserver_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"project_template/console/consoleserver"
+	"project_template/jobs"
+	"project_template/pkg/logger/zaplog"
+	"project_template/ratelimit"
+)
+
+func TestCORS(t *testing.T) {
+	config := testConfig()
+	config.CORS = consoleserver.CORSConfig{
+		AllowedOrigins: []string{"https://ui.test"},
+		AllowedMethods: []string{http.MethodGet, http.MethodPost},
+		AllowedHeaders: []string{"Content-Type"},
+		ExposedHeaders: []string{"RateLimit-Remaining"},
+		MaxAge:         10 * time.Minute,
+	}
+	url := runServer(t, config) + "/api/v0/jobs"
+
+	t.Run("preflight of allowed origin", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodOptions, url, nil)
+		require.NoError(t, err)
+		req.Header.Set("Origin", "https://ui.test")
+		req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+
+		resp := do(t, req)
+		require.Equal(t, http.StatusNoContent, resp.StatusCode)
+		require.Equal(t, "https://ui.test", resp.Header.Get("Access-Control-Allow-Origin"))
+		require.Equal(t, "GET, POST", resp.Header.Get("Access-Control-Allow-Methods"))
+		require.Equal(t, "Content-Type", resp.Header.Get("Access-Control-Allow-Headers"))
+		require.Equal(t, "600", resp.Header.Get("Access-Control-Max-Age"))
+		require.Empty(t, resp.Header.Get("Access-Control-Allow-Credentials"))
+		require.Contains(t, resp.Header.Values("Vary"), "Origin")
+	})
+
+	t.Run("preflight of other origin", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodOptions, url, nil)
+		require.NoError(t, err)
+		req.Header.Set("Origin", "https://evil.test")
+		req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+
+		resp := do(t, req)
+		require.Equal(t, http.StatusNoContent, resp.StatusCode)
+		require.Empty(t, resp.Header.Get("Access-Control-Allow-Origin"))
+		require.Empty(t, resp.Header.Get("Access-Control-Allow-Methods"))
+	})
+
+	t.Run("request of allowed origin", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		require.NoError(t, err)
+		req.Header.Set("Origin", "https://ui.test")
+
+		resp := do(t, req)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "https://ui.test", resp.Header.Get("Access-Control-Allow-Origin"))
+		require.Equal(t, "RateLimit-Remaining", resp.Header.Get("Access-Control-Expose-Headers"))
+	})
+
+	t.Run("request without origin", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		require.NoError(t, err)
+
+		resp := do(t, req)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Empty(t, resp.Header.Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("credentials of any origin", func(t *testing.T) {
+		config := testConfig()
+		config.CORS.AllowedOrigins = []string{"*"}
+		config.CORS.AllowCredentials = true
+
+		_, err := newServer(config, nil)
+		require.Error(t, err)
+		require.True(t, consoleserver.Error.Has(err))
+	})
+}
+
+func TestSecurityHeaders(t *testing.T) {
+	url := runServer(t, testConfig())
+
+	for _, path := range []string{"/api/v0/jobs", "/unknown"} {
+		req, err := http.NewRequest(http.MethodGet, url+path, nil)
+		require.NoError(t, err)
+
+		resp := do(t, req)
+		require.Equal(t, "nosniff", resp.Header.Get("X-Content-Type-Options"), path)
+		require.Equal(t, "DENY", resp.Header.Get("X-Frame-Options"), path)
+		require.Equal(t, "default-src 'none'; frame-ancestors 'none'", resp.Header.Get("Content-Security-Policy"), path)
+		require.Empty(t, resp.Header.Get("Strict-Transport-Security"), "HSTS is sent only over TLS")
+	}
+}
+
+// testConfig returns the config with limits which don't get in the way of tests.
+func testConfig() consoleserver.Config {
+	return consoleserver.Config{
+		RequestTimeout: time.Minute,
+		MaxBodySize:    1 << 20,
+		MaxImportSize:  1 << 20,
+		Security: consoleserver.SecurityConfig{
+			HSTSMaxAge:   time.Hour,
+			FrameOptions: "DENY",
+		},
+	}
+}
+
+// newServer creates the server without dummies and webhooks, jobs are listed from an empty scheduler.
+func newServer(config consoleserver.Config, listener net.Listener) (*consoleserver.Server, error) {
+	log := zaplog.NewLog()
+
+	return consoleserver.NewServer(config, log, listener, consoleserver.Services{
+		Jobs:        jobs.NewScheduler(log, jobs.Config{}, nil),
+		RateLimiter: ratelimit.NewLimiter(log, ratelimit.Config{}, ratelimit.NewMemoryDB()),
+	})
+}
+
+// runServer runs the server until the test ends and returns its url.
+func runServer(t *testing.T, config consoleserver.Config) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server, err := newServer(config, listener)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- server.Run(ctx) }()
+
+	t.Cleanup(func() {
+		cancel()
+		require.NoError(t, <-done)
+	})
+
+	return "http://" + listener.Addr().String()
+}
+
+// do sends the request and closes the response body.
+func do(t *testing.T, req *http.Request) *http.Response {
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	return resp
+}
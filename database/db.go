@@ -1,20 +1,26 @@
 package database
 
 import (
-	"context"
 	"database/sql"
+	"embed"
 	"fmt"
+	"io/fs"
 
-	"github.com/golang-migrate/migrate/v4"
-	"github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/source/file" // using golang migrate source.
 	_ "github.com/lib/pq"                                // using postgres driver.
 	"github.com/zeebo/errs"
 
 	"project_template"
 	"project_template/dummy"
+	"project_template/events"
+	"project_template/jobs"
+	"project_template/queue"
+	"project_template/ratelimit"
+	"project_template/webhooks"
 )
 
+//go:generate go run project_template/cmd/database generate --queries queries --output .
+
 // ensures that database implements project_template.DB.
 var _ project_template.DB = (*database)(nil)
 
@@ -23,23 +29,44 @@ var (
 	Error = errs.Class("db error")
 )
 
+// migrationsFS contains migrations embedded into the binary.
+//
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+// EmbeddedMigrations returns migration files embedded into the binary.
+func EmbeddedMigrations() fs.FS {
+	migrations, err := fs.Sub(migrationsFS, "migrations")
+	if err != nil {
+		panic(err)
+	}
+
+	return migrations
+}
+
+// MigrationsConfig contains configurable values for the migration mechanism.
+type MigrationsConfig struct {
+	MigrationsPath string `env:"DB_MIGRATIONS_PATH" desc:"path to the directory with sql migration files, overrides migrations embedded into the binary"`
+}
+
 // database combines access to different database tables with a record
 // of the db driver, db implementation, and db source URL.
 //
 // architecture: Master Database
 type database struct {
 	conn *sql.DB
+	// connStr is used to open dedicated connections, e.g. for LISTEN.
+	connStr string
+}
+
+// ConnStr returns postgresql connection string for the config.
+func ConnStr(config project_template.DBConfig) string {
+	return fmt.Sprintf("postgres://%s:%s@localhost/%s?sslmode=disable", config.User, config.Pass, config.Name)
 }
 
 // New returns project_template.DB postgresql implementation.
 func New(config project_template.DBConfig) (project_template.DB, error) {
-	connStr := fmt.Sprintf("postgres://%s:%s@localhost/%s?sslmode=disable", config.User, config.Pass, config.Name)
-	conn, err := sql.Open("postgres", connStr)
-	if err != nil {
-		return nil, Error.Wrap(err)
-	}
-
-	return &database{conn: conn}, nil
+	return NewByCoonStr(ConnStr(config))
 }
 
 // NewByCoonStr returns project_template.DB postgresql implementation.
@@ -49,33 +76,48 @@ func NewByCoonStr(connStr string) (project_template.DB, error) {
 		return nil, Error.Wrap(err)
 	}
 
-	return &database{conn: conn}, nil
+	return &database{conn: conn, connStr: connStr}, nil
 }
 
 // Dummy provides access to dummy db.
 func (db *database) Dummy() dummy.DB {
-	return &dummyDB{conn: db.conn}
+	return &dummyDB{conn: db.conn, connStr: db.connStr}
 }
 
-// ExecuteMigrations executes migrations by path in database.
-func (db *database) ExecuteMigrations(ctx context.Context, migrationsPath string, isUp bool) error {
-	driver, err := postgres.WithInstance(db.conn, &postgres.Config{})
-	if err != nil {
-		return Error.Wrap(err)
-	}
+// Outbox provides access to the outbox of domain events.
+func (db *database) Outbox() events.DB {
+	return &outboxDB{conn: db.conn}
+}
 
-	m, err := migrate.NewWithDatabaseInstance("file://"+migrationsPath, "postgres", driver)
-	if err != nil {
-		return Error.Wrap(err)
-	}
+// Webhooks provides access to webhook subscriptions and deliveries.
+func (db *database) Webhooks() webhooks.DB {
+	return &webhooksDB{conn: db.conn}
+}
 
-	if isUp {
-		err = m.Up()
-	} else {
-		err = m.Down()
-	}
+// Jobs provides the leader election of jobs and the history of their runs.
+func (db *database) Jobs() jobs.DB {
+	return &jobsDB{conn: db.conn}
+}
+
+// Queue provides access to the queue of tasks.
+func (db *database) Queue() queue.DB {
+	return &queueDB{conn: db.conn}
+}
+
+// RateLimits provides access to token buckets of the rate limiter.
+func (db *database) RateLimits() ratelimit.DB {
+	return &rateLimitDB{conn: db.conn}
+}
+
+// Migrations provides management of schema migrations located by path,
+// migrations embedded into the binary are used if path is empty.
+func (db *database) Migrations(migrationsPath string) project_template.Migrations {
+	return &migrations{conn: db.conn, path: migrationsPath}
+}
 
-	return Error.Wrap(err)
+// Stats returns statistics of the connection pool.
+func (db *database) Stats() sql.DBStats {
+	return db.conn.Stats()
 }
 
 // Close closes underlying db connection.
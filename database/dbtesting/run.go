@@ -20,7 +20,7 @@ type tempMasterDB struct {
 
 // Config defines configuration for tests.
 type Config struct {
-	MigrationsPath string `env:"DB_MIGRATIONS_PATH" validate:"required"`
+	database.MigrationsConfig
 	project_template.DBConfig
 }
 
@@ -46,7 +46,7 @@ func Run(t *testing.T, test func(ctx context.Context, t *testing.T, db project_t
 			}
 		}()
 
-		err = db.ExecuteMigrations(ctx, runCfg.MigrationsPath, true)
+		err = db.Migrations(runCfg.MigrationsPath).Up(ctx)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -4,95 +4,370 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"time"
+
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/zeebo/errs"
 
 	"project_template/dummy"
+	"project_template/events"
 )
 
 // ErrDummy indicates that there was an error in the database.
 var ErrDummy = errs.Class("dummy repository error")
 
-// usersDB provides access to users db.
+// dummyDB provides access to dummy db, queries are generated from database/queries/dummy.sql.
 //
 // architecture: Database
 type dummyDB struct {
-	conn *sql.DB
+	conn    *sql.DB
+	connStr string
 }
 
 func (dummyDB *dummyDB) List(ctx context.Context) ([]dummy.Dummy, error) {
-	query := `SELECT id, title, status, created_at FROM dummy`
-
-	rows, err := dummyDB.conn.QueryContext(ctx, query)
+	rows, err := listDummies(ctx, dummyDB.conn)
 	if err != nil {
 		return nil, ErrDummy.Wrap(err)
 	}
-	defer func() {
-		err = errs.Combine(err, rows.Close())
-	}()
 
 	var result []dummy.Dummy
+	for _, row := range rows {
+		result = append(result, row.toDummy())
+	}
 
-	for rows.Next() {
-		var item dummy.Dummy
-
-		err = rows.Scan(&item.ID, &item.Title, &item.Status, &item.CreatedAt)
-		if err != nil {
-			return nil, ErrDummy.Wrap(err)
-		}
+	return result, nil
+}
 
-		result = append(result, item)
+// ListPage returns a page of dummies ordered by creation time and id.
+func (dummyDB *dummyDB) ListPage(ctx context.Context, opts dummy.ListOptions) ([]dummy.Dummy, error) {
+	rows, err := listDummiesPage(ctx, dummyDB.conn, int64(opts.Limit), int64(opts.Offset))
+	if err != nil {
+		return nil, ErrDummy.Wrap(err)
 	}
 
-	if err = rows.Err(); err != nil {
-		return nil, ErrDummy.Wrap(err)
+	var result []dummy.Dummy
+	for _, row := range rows {
+		result = append(result, row.toDummy())
 	}
 
 	return result, nil
 }
 
 func (dummyDB *dummyDB) Get(ctx context.Context, id uuid.UUID) (dummy.Dummy, error) {
-	var result dummy.Dummy
-	query := `SELECT id, title, status, created_at FROM dummy WHERE id = $1 LIMIT 1`
-
-	err := dummyDB.conn.QueryRowContext(ctx, query, id).Scan(&result.ID, &result.Title, &result.Status, &result.CreatedAt)
+	row, err := getDummy(ctx, dummyDB.conn, id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return dummy.Dummy{}, dummy.ErrNoDummy.Wrap(err)
 		}
+
+		return dummy.Dummy{}, ErrDummy.Wrap(err)
 	}
 
-	return result, nil
+	return row.toDummy(), nil
 }
 
-func (dummyDB *dummyDB) Create(ctx context.Context, d dummy.Dummy) error {
-	query := `INSERT INTO dummy(id, title, status, created_at)
-	          VALUES ($1, $2, $3, $4)`
-
-	_, err := dummyDB.conn.ExecContext(ctx, query, d.ID, d.Title, d.Status, d.CreatedAt)
+func (dummyDB *dummyDB) Create(ctx context.Context, d dummy.Dummy, event events.Event) error {
+	err := withTx(ctx, dummyDB.conn, func(tx *sql.Tx) error {
+		if err := createDummy(ctx, tx, d.ID, d.Title, int32(d.Status), d.CreatedAt); err != nil {
+			return err
+		}
+		return insertEvents(ctx, tx, []events.Event{event})
+	})
 	return ErrDummy.Wrap(err)
 }
 
-func (dummyDB *dummyDB) Update(ctx context.Context, id uuid.UUID, title string, status dummy.Status) error {
-	query := "UPDATE dummy SET title = $1, status = $2 WHERE id = $3"
-
-	result, err := dummyDB.conn.ExecContext(ctx, query, title, status, id)
+func (dummyDB *dummyDB) Update(ctx context.Context, id uuid.UUID, title string, status dummy.Status, event events.Event) error {
+	var rowNum int64
+	err := withTx(ctx, dummyDB.conn, func(tx *sql.Tx) (err error) {
+		rowNum, err = updateDummy(ctx, tx, title, int32(status), id)
+		if err != nil || rowNum == 0 {
+			return err
+		}
+		return insertEvents(ctx, tx, []events.Event{event})
+	})
 	if err != nil {
 		return ErrDummy.Wrap(err)
 	}
 
-	rowNum, err := result.RowsAffected()
 	if rowNum == 0 {
 		return dummy.ErrNoDummy.New("dummy does not exist")
 	}
 
+	return nil
+}
+
+func (dummyDB *dummyDB) Delete(ctx context.Context, id uuid.UUID, event events.Event) error {
+	err := withTx(ctx, dummyDB.conn, func(tx *sql.Tx) error {
+		rowNum, err := deleteDummy(ctx, tx, id)
+		if err != nil || rowNum == 0 {
+			return err
+		}
+		return insertEvents(ctx, tx, []events.Event{event})
+	})
 	return ErrDummy.Wrap(err)
+}
+
+func (dummyDB *dummyDB) Search(ctx context.Context, query string, opts dummy.SearchOptions) ([]dummy.SearchResult, error) {
+	rows, err := searchDummies(ctx, dummyDB.conn, query, int64(opts.Limit), int64(opts.Offset))
+	if err != nil {
+		return nil, ErrDummy.Wrap(err)
+	}
+
+	var result []dummy.SearchResult
+	for _, row := range rows {
+		result = append(result, dummy.SearchResult{
+			Dummy: dummyRow{
+				ID:        row.ID,
+				Title:     row.Title,
+				Status:    row.Status,
+				CreatedAt: row.CreatedAt,
+			}.toDummy(),
+			Rank:      row.Rank.Float64,
+			Highlight: row.Highlight.String,
+		})
+	}
 
+	return result, nil
 }
 
-func (dummyDB *dummyDB) Delete(ctx context.Context, id uuid.UUID) error {
-	query := `DELETE FROM dummy WHERE id = $1`
+// COPY and temporary tables can't be described by generated queries, so bulk queries are written by hand.
+const (
+	createDummyImportSQL = `CREATE TEMPORARY TABLE dummy_import (LIKE dummy) ON COMMIT DROP`
+	moveDummyImportSQL   = `
+		INSERT INTO dummy (id, title, status, created_at)
+		SELECT id, title, status, created_at FROM dummy_import
+		ON CONFLICT (id) DO NOTHING
+		RETURNING id`
+	exportDummiesSQL = `SELECT id, title, status, created_at FROM dummy ORDER BY created_at, id`
+)
+
+// Import copies the batch into a temporary table and moves rows with new ids into the dummy table in one transaction.
+func (dummyDB *dummyDB) Import(ctx context.Context, dummies []dummy.Dummy, created map[uuid.UUID]events.Event) (skipped []uuid.UUID, err error) {
+	tx, err := dummyDB.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, ErrDummy.Wrap(err)
+	}
+	defer func() {
+		if err != nil {
+			err = errs.Combine(err, ErrDummy.Wrap(tx.Rollback()))
+			return
+		}
+		err = ErrDummy.Wrap(tx.Commit())
+	}()
+
+	if _, err = tx.ExecContext(ctx, createDummyImportSQL); err != nil {
+		return nil, ErrDummy.Wrap(err)
+	}
+
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn("dummy_import", "id", "title", "status", "created_at"))
+	if err != nil {
+		return nil, ErrDummy.Wrap(err)
+	}
+
+	for _, d := range dummies {
+		if _, err = stmt.ExecContext(ctx, d.ID, d.Title, int32(d.Status), d.CreatedAt); err != nil {
+			return nil, ErrDummy.Wrap(errs.Combine(err, stmt.Close()))
+		}
+	}
+
+	if _, err = stmt.ExecContext(ctx); err != nil {
+		return nil, ErrDummy.Wrap(errs.Combine(err, stmt.Close()))
+	}
+	if err = stmt.Close(); err != nil {
+		return nil, ErrDummy.Wrap(err)
+	}
+
+	inserted, err := returnedIDs(ctx, tx, moveDummyImportSQL)
+	if err != nil {
+		return nil, ErrDummy.Wrap(err)
+	}
+
+	if err = insertEvents(ctx, tx, appliedEvents(created, inserted)); err != nil {
+		return nil, ErrDummy.Wrap(err)
+	}
+
+	ids := make([]uuid.UUID, 0, len(dummies))
+	for _, d := range dummies {
+		ids = append(ids, d.ID)
+	}
+
+	return missingIDs(ids, inserted), nil
+}
 
-	_, err := dummyDB.conn.ExecContext(ctx, query, id)
+// Export reads dummies row by row, so they are never loaded at once.
+func (dummyDB *dummyDB) Export(ctx context.Context, fn func(dummy.Dummy) error) error {
+	err := queryRows(ctx, dummyDB.conn, exportDummiesSQL, func(rows *sql.Rows) error {
+		var row dummyRow
+		if err := rows.Scan(&row.ID, &row.Title, &row.Status, &row.CreatedAt); err != nil {
+			return err
+		}
+		return fn(row.toDummy())
+	})
 	return ErrDummy.Wrap(err)
 }
+
+// Batch applies each kind of operation with one multi-row statement in a transaction.
+func (dummyDB *dummyDB) Batch(ctx context.Context, batch dummy.Batch, atomic bool) (conflicts []uuid.UUID, err error) {
+	tx, err := dummyDB.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, ErrDummy.Wrap(err)
+	}
+	defer func() {
+		if err != nil || (atomic && len(conflicts) > 0) {
+			err = errs.Combine(err, ErrDummy.Wrap(tx.Rollback()))
+			return
+		}
+		err = ErrDummy.Wrap(tx.Commit())
+	}()
+
+	applied := make(map[uuid.UUID]bool)
+	if len(batch.Create) > 0 {
+		var (
+			ids       = make([]uuid.UUID, 0, len(batch.Create))
+			titles    = make([]string, 0, len(batch.Create))
+			statuses  = make([]int32, 0, len(batch.Create))
+			createdAt = make([]time.Time, 0, len(batch.Create))
+		)
+		for _, d := range batch.Create {
+			ids = append(ids, d.ID)
+			titles = append(titles, d.Title)
+			statuses = append(statuses, int32(d.Status))
+			createdAt = append(createdAt, d.CreatedAt)
+		}
+
+		rows, err := createDummies(ctx, tx, ids, titles, statuses, createdAt)
+		if err != nil {
+			return nil, ErrDummy.Wrap(err)
+		}
+
+		created := make(map[uuid.UUID]bool, len(rows))
+		for _, row := range rows {
+			created[row.ID] = true
+		}
+		conflicts = append(conflicts, missingIDs(ids, created)...)
+		applied = mergeIDs(applied, created)
+	}
+
+	if len(batch.Update) > 0 {
+		var (
+			ids      = make([]uuid.UUID, 0, len(batch.Update))
+			titles   = make([]string, 0, len(batch.Update))
+			statuses = make([]int32, 0, len(batch.Update))
+		)
+		for _, d := range batch.Update {
+			ids = append(ids, d.ID)
+			titles = append(titles, d.Title)
+			statuses = append(statuses, int32(d.Status))
+		}
+
+		rows, err := updateDummies(ctx, tx, ids, titles, statuses)
+		if err != nil {
+			return nil, ErrDummy.Wrap(err)
+		}
+
+		updated := make(map[uuid.UUID]bool, len(rows))
+		for _, row := range rows {
+			updated[row.ID] = true
+		}
+		conflicts = append(conflicts, missingIDs(ids, updated)...)
+		applied = mergeIDs(applied, updated)
+	}
+
+	if len(batch.Delete) > 0 {
+		rows, err := deleteDummies(ctx, tx, batch.Delete)
+		if err != nil {
+			return nil, ErrDummy.Wrap(err)
+		}
+
+		deleted := make(map[uuid.UUID]bool, len(rows))
+		for _, row := range rows {
+			deleted[row.ID] = true
+		}
+		conflicts = append(conflicts, missingIDs(batch.Delete, deleted)...)
+		applied = mergeIDs(applied, deleted)
+	}
+
+	if atomic && len(conflicts) > 0 {
+		return conflicts, nil
+	}
+
+	if err = insertEvents(ctx, tx, appliedEvents(batch.Events, applied)); err != nil {
+		return nil, ErrDummy.Wrap(err)
+	}
+
+	return conflicts, nil
+}
+
+// returnedIDs executes query which returns ids of changed rows.
+func returnedIDs(ctx context.Context, db dbtx, query string, args ...interface{}) (map[uuid.UUID]bool, error) {
+	ids := make(map[uuid.UUID]bool)
+	err := queryRows(ctx, db, query, func(rows *sql.Rows) error {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return err
+		}
+		ids[id] = true
+		return nil
+	}, args...)
+	return ids, err
+}
+
+// missingIDs returns ids which are not in the set, in the original order.
+func missingIDs(ids []uuid.UUID, set map[uuid.UUID]bool) []uuid.UUID {
+	var missing []uuid.UUID
+	for _, id := range ids {
+		if !set[id] {
+			missing = append(missing, id)
+		}
+	}
+	return missing
+}
+
+// mergeIDs adds ids of the second set to the first one.
+func mergeIDs(set, ids map[uuid.UUID]bool) map[uuid.UUID]bool {
+	for id := range ids {
+		set[id] = true
+	}
+	return set
+}
+
+// appliedEvents returns events of the applied ids, events of skipped changes are not stored.
+func appliedEvents(byID map[uuid.UUID]events.Event, applied map[uuid.UUID]bool) []events.Event {
+	result := make([]events.Event, 0, len(applied))
+	for id := range applied {
+		if event, ok := byID[id]; ok {
+			result = append(result, event)
+		}
+	}
+	return result
+}
+
+// queryRows executes query and calls fn for every row.
+func queryRows(ctx context.Context, db dbtx, query string, fn func(rows *sql.Rows) error, args ...interface{}) (err error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errs.Combine(err, rows.Close())
+	}()
+
+	for rows.Next() {
+		if err = fn(rows); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// toDummy converts generated row to the domain entity.
+func (row dummyRow) toDummy() dummy.Dummy {
+	return dummy.Dummy{
+		ID:        row.ID,
+		Title:     row.Title.String,
+		Status:    dummy.Status(row.Status),
+		CreatedAt: row.CreatedAt,
+	}
+}
@@ -0,0 +1,102 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/lib/pq"
+
+	"project_template/dummy"
+)
+
+// dummyChangesChannel is the channel changes are announced on by the dummy_record_change trigger.
+const dummyChangesChannel = "dummy_changes"
+
+// listenerPingInterval is how often the listening connection is checked when there are no notifications.
+const listenerPingInterval = 90 * time.Second
+
+func (dummyDB *dummyDB) GetChange(ctx context.Context, id int64) (dummy.Change, error) {
+	row, err := getDummyChange(ctx, dummyDB.conn, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return dummy.Change{}, dummy.ErrNoChange.Wrap(err)
+		}
+
+		return dummy.Change{}, ErrDummy.Wrap(err)
+	}
+
+	return row.toChange(), nil
+}
+
+func (dummyDB *dummyDB) Changes(ctx context.Context, after int64, limit int) ([]dummy.Change, error) {
+	rows, err := listDummyChanges(ctx, dummyDB.conn, after, int64(limit))
+	if err != nil {
+		return nil, ErrDummy.Wrap(err)
+	}
+
+	result := make([]dummy.Change, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, row.toChange())
+	}
+
+	return result, nil
+}
+
+func (dummyDB *dummyDB) DeleteChanges(ctx context.Context, before time.Time) (int64, error) {
+	deleted, err := deleteDummyChanges(ctx, dummyDB.conn, before)
+	return deleted, ErrDummy.Wrap(err)
+}
+
+// ListenChanges listens on a dedicated connection, which is re-established by the listener when it breaks.
+func (dummyDB *dummyDB) ListenChanges(ctx context.Context, notify func(id int64)) (err error) {
+	listener := pq.NewListener(dummyDB.connStr, time.Second, time.Minute, nil)
+	defer func() {
+		if closeErr := listener.Close(); err == nil {
+			err = ErrDummy.Wrap(closeErr)
+		}
+	}()
+
+	if err = listener.Listen(dummyChangesChannel); err != nil {
+		return ErrDummy.Wrap(err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case notification := <-listener.Notify:
+			// nil is sent after the connection was re-established.
+			if notification == nil {
+				notify(0)
+				continue
+			}
+
+			id, err := strconv.ParseInt(notification.Extra, 10, 64)
+			if err != nil {
+				return ErrDummy.New("invalid change id %q", notification.Extra)
+			}
+			notify(id)
+		case <-time.After(listenerPingInterval):
+			// a failed ping makes the listener reconnect.
+			go func() { _ = listener.Ping() }()
+		}
+	}
+}
+
+// toChange converts generated row to the domain entity.
+func (row dummyChangesRow) toChange() dummy.Change {
+	return dummy.Change{
+		ID: row.ID,
+		Op: dummy.ChangeOp(row.Op),
+		Dummy: dummyRow{
+			ID:        row.DummyID,
+			Title:     row.Title,
+			Status:    row.Status,
+			CreatedAt: row.CreatedAt,
+		}.toDummy(),
+		ChangedAt: row.ChangedAt,
+	}
+}
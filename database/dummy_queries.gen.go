@@ -0,0 +1,282 @@
+// Code generated by `database generate`. DO NOT EDIT.
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/zeebo/errs"
+)
+
+const listDummiesSQL = `SELECT id, title, status, created_at FROM dummy`
+
+// listDummies executes the ListDummies query.
+func listDummies(ctx context.Context, db dbtx) (_ []dummyRow, err error) {
+	rows, err := db.QueryContext(ctx, listDummiesSQL)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err = errs.Combine(err, rows.Close())
+	}()
+
+	var result []dummyRow
+	for rows.Next() {
+		var row dummyRow
+		if err = rows.Scan(&row.ID, &row.Title, &row.Status, &row.CreatedAt); err != nil {
+			return nil, err
+		}
+		result = append(result, row)
+	}
+
+	return result, rows.Err()
+}
+
+const listDummiesPageSQL = `SELECT id, title, status, created_at FROM dummy ORDER BY created_at, id LIMIT $1 OFFSET $2`
+
+// listDummiesPage executes the ListDummiesPage query.
+func listDummiesPage(ctx context.Context, db dbtx, limit int64, offset int64) (_ []dummyRow, err error) {
+	rows, err := db.QueryContext(ctx, listDummiesPageSQL, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err = errs.Combine(err, rows.Close())
+	}()
+
+	var result []dummyRow
+	for rows.Next() {
+		var row dummyRow
+		if err = rows.Scan(&row.ID, &row.Title, &row.Status, &row.CreatedAt); err != nil {
+			return nil, err
+		}
+		result = append(result, row)
+	}
+
+	return result, rows.Err()
+}
+
+const getDummySQL = `SELECT id, title, status, created_at FROM dummy WHERE id = $1 LIMIT 1`
+
+// getDummy executes the GetDummy query.
+func getDummy(ctx context.Context, db dbtx, id uuid.UUID) (dummyRow, error) {
+	var row dummyRow
+	err := db.QueryRowContext(ctx, getDummySQL, id).Scan(&row.ID, &row.Title, &row.Status, &row.CreatedAt)
+	return row, err
+}
+
+const createDummySQL = `INSERT INTO dummy(id, title, status, created_at)
+VALUES ($1, $2, $3, $4)`
+
+// createDummy executes the CreateDummy query.
+func createDummy(ctx context.Context, db dbtx, id uuid.UUID, title string, status int32, createdAt time.Time) error {
+	_, err := db.ExecContext(ctx, createDummySQL, id, title, status, createdAt)
+	return err
+}
+
+const updateDummySQL = `UPDATE dummy SET title = $1, status = $2 WHERE id = $3`
+
+// updateDummy executes the UpdateDummy query.
+func updateDummy(ctx context.Context, db dbtx, title string, status int32, id uuid.UUID) (int64, error) {
+	result, err := db.ExecContext(ctx, updateDummySQL, title, status, id)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+const deleteDummySQL = `DELETE FROM dummy WHERE id = $1`
+
+// deleteDummy executes the DeleteDummy query.
+func deleteDummy(ctx context.Context, db dbtx, id uuid.UUID) (int64, error) {
+	result, err := db.ExecContext(ctx, deleteDummySQL, id)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+const searchDummiesSQL = `SELECT id, title, status, created_at,
+       ts_rank(title_search, query) + public.similarity(COALESCE(title, ''), $1) AS rank,
+       ts_headline('simple', COALESCE(title, ''), query, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true') AS highlight
+FROM dummy, websearch_to_tsquery('simple', $1) AS query
+WHERE title_search @@ query OR title OPERATOR(public.%) $1
+ORDER BY rank DESC, created_at DESC, id
+LIMIT $2 OFFSET $3`
+
+// searchDummiesRow is a row returned by the SearchDummies query.
+type searchDummiesRow struct {
+	ID        uuid.UUID
+	Title     sql.NullString
+	Status    int32
+	CreatedAt time.Time
+	Rank      sql.NullFloat64
+	Highlight sql.NullString
+}
+
+// searchDummies executes the SearchDummies query.
+func searchDummies(ctx context.Context, db dbtx, title string, limit int64, offset int64) (_ []searchDummiesRow, err error) {
+	rows, err := db.QueryContext(ctx, searchDummiesSQL, title, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err = errs.Combine(err, rows.Close())
+	}()
+
+	var result []searchDummiesRow
+	for rows.Next() {
+		var row searchDummiesRow
+		if err = rows.Scan(&row.ID, &row.Title, &row.Status, &row.CreatedAt, &row.Rank, &row.Highlight); err != nil {
+			return nil, err
+		}
+		result = append(result, row)
+	}
+
+	return result, rows.Err()
+}
+
+const getDummyChangeSQL = `SELECT id, op, dummy_id, title, status, created_at, changed_at FROM dummy_changes WHERE id = $1 LIMIT 1`
+
+// getDummyChange executes the GetDummyChange query.
+func getDummyChange(ctx context.Context, db dbtx, id int64) (dummyChangesRow, error) {
+	var row dummyChangesRow
+	err := db.QueryRowContext(ctx, getDummyChangeSQL, id).Scan(&row.ID, &row.Op, &row.DummyID, &row.Title, &row.Status, &row.CreatedAt, &row.ChangedAt)
+	return row, err
+}
+
+const listDummyChangesSQL = `SELECT id, op, dummy_id, title, status, created_at, changed_at FROM dummy_changes WHERE id > $1 ORDER BY id LIMIT $2`
+
+// listDummyChanges executes the ListDummyChanges query.
+func listDummyChanges(ctx context.Context, db dbtx, id int64, limit int64) (_ []dummyChangesRow, err error) {
+	rows, err := db.QueryContext(ctx, listDummyChangesSQL, id, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err = errs.Combine(err, rows.Close())
+	}()
+
+	var result []dummyChangesRow
+	for rows.Next() {
+		var row dummyChangesRow
+		if err = rows.Scan(&row.ID, &row.Op, &row.DummyID, &row.Title, &row.Status, &row.CreatedAt, &row.ChangedAt); err != nil {
+			return nil, err
+		}
+		result = append(result, row)
+	}
+
+	return result, rows.Err()
+}
+
+const deleteDummyChangesSQL = `DELETE FROM dummy_changes WHERE changed_at < $1`
+
+// deleteDummyChanges executes the DeleteDummyChanges query.
+func deleteDummyChanges(ctx context.Context, db dbtx, changedAt time.Time) (int64, error) {
+	result, err := db.ExecContext(ctx, deleteDummyChangesSQL, changedAt)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+const createDummiesSQL = `INSERT INTO dummy (id, title, status, created_at)
+SELECT id, title, status, created_at
+FROM unnest($1::uuid[], $2::varchar[], $3::integer[], $4::timestamptz[]) AS c(id, title, status, created_at)
+ON CONFLICT (id) DO NOTHING
+RETURNING id`
+
+// createDummiesRow is a row returned by the CreateDummies query.
+type createDummiesRow struct {
+	ID uuid.UUID
+}
+
+// createDummies executes the CreateDummies query.
+func createDummies(ctx context.Context, db dbtx, id []uuid.UUID, title []string, status []int32, createdAt []time.Time) (_ []createDummiesRow, err error) {
+	rows, err := db.QueryContext(ctx, createDummiesSQL, pq.Array(id), pq.Array(title), pq.Array(status), pq.Array(createdAt))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err = errs.Combine(err, rows.Close())
+	}()
+
+	var result []createDummiesRow
+	for rows.Next() {
+		var row createDummiesRow
+		if err = rows.Scan(&row.ID); err != nil {
+			return nil, err
+		}
+		result = append(result, row)
+	}
+
+	return result, rows.Err()
+}
+
+const updateDummiesSQL = `UPDATE dummy SET title = u.title, status = u.status
+FROM unnest($1::uuid[], $2::varchar[], $3::integer[]) AS u(id, title, status)
+WHERE dummy.id = u.id
+RETURNING dummy.id`
+
+// updateDummiesRow is a row returned by the UpdateDummies query.
+type updateDummiesRow struct {
+	ID uuid.UUID
+}
+
+// updateDummies executes the UpdateDummies query.
+func updateDummies(ctx context.Context, db dbtx, id []uuid.UUID, title []string, status []int32) (_ []updateDummiesRow, err error) {
+	rows, err := db.QueryContext(ctx, updateDummiesSQL, pq.Array(id), pq.Array(title), pq.Array(status))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err = errs.Combine(err, rows.Close())
+	}()
+
+	var result []updateDummiesRow
+	for rows.Next() {
+		var row updateDummiesRow
+		if err = rows.Scan(&row.ID); err != nil {
+			return nil, err
+		}
+		result = append(result, row)
+	}
+
+	return result, rows.Err()
+}
+
+const deleteDummiesSQL = `DELETE FROM dummy WHERE id = ANY($1::uuid[]) RETURNING id`
+
+// deleteDummiesRow is a row returned by the DeleteDummies query.
+type deleteDummiesRow struct {
+	ID uuid.UUID
+}
+
+// deleteDummies executes the DeleteDummies query.
+func deleteDummies(ctx context.Context, db dbtx, id []uuid.UUID) (_ []deleteDummiesRow, err error) {
+	rows, err := db.QueryContext(ctx, deleteDummiesSQL, pq.Array(id))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err = errs.Combine(err, rows.Close())
+	}()
+
+	var result []deleteDummiesRow
+	for rows.Next() {
+		var row deleteDummiesRow
+		if err = rows.Scan(&row.ID); err != nil {
+			return nil, err
+		}
+		result = append(result, row)
+	}
+
+	return result, rows.Err()
+}
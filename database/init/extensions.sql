@@ -0,0 +1,3 @@
+-- extensions shared by all schemas of the database, migrations rely on them, so they are created
+-- once per database by its owner before migrations are applied.
+CREATE EXTENSION IF NOT EXISTS pg_trgm SCHEMA public;
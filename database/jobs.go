@@ -0,0 +1,80 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zeebo/errs"
+
+	"project_template/jobs"
+	"project_template/pkg/postgres"
+)
+
+// ensures that jobsDB implements jobs.DB.
+var _ jobs.DB = (*jobsDB)(nil)
+
+// ErrJobs indicates that there was an error in the database.
+var ErrJobs = errs.Class("jobs repository error")
+
+// jobsDB provides the leader election of jobs and the history of their runs, queries are generated
+// from database/queries/jobs.sql.
+//
+// architecture: Database
+type jobsDB struct {
+	conn *sql.DB
+}
+
+// TryLead takes the advisory lock of the job, it's held on a dedicated connection until the leadership is released.
+func (jobsDB *jobsDB) TryLead(ctx context.Context, job string) (jobs.Leadership, error) {
+	lock, err := postgres.TryAdvisoryLock(ctx, jobsDB.conn, postgres.LockKey("project_template:job:"+job))
+	if err != nil {
+		return nil, ErrJobs.Wrap(err)
+	}
+
+	// a nil *Lock must not be returned as a non-nil interface.
+	if lock == nil {
+		return nil, nil
+	}
+	return lock, nil
+}
+
+func (jobsDB *jobsDB) StartRun(ctx context.Context, run jobs.Run) error {
+	return ErrJobs.Wrap(createJobRun(ctx, jobsDB.conn, run.ID, run.Job, string(run.Status), run.StartedAt))
+}
+
+func (jobsDB *jobsDB) FinishRun(ctx context.Context, id uuid.UUID, status jobs.RunStatus, finishedAt time.Time, runErr string) error {
+	return ErrJobs.Wrap(finishJobRun(ctx, jobsDB.conn, string(status), finishedAt, runErr, id))
+}
+
+func (jobsDB *jobsDB) ListRuns(ctx context.Context, job string, limit int) ([]jobs.Run, error) {
+	rows, err := listJobRuns(ctx, jobsDB.conn, job, int64(limit))
+	if err != nil {
+		return nil, ErrJobs.Wrap(err)
+	}
+
+	result := make([]jobs.Run, 0, len(rows))
+	for _, row := range rows {
+		run := jobs.Run{
+			ID:        row.ID,
+			Job:       row.Job,
+			Status:    jobs.RunStatus(row.Status),
+			StartedAt: row.StartedAt,
+			Error:     row.ErrorMessage.String,
+		}
+		// the time is copied, since the loop variable is reused.
+		if row.FinishedAt.Valid {
+			finishedAt := row.FinishedAt.Time
+			run.FinishedAt = &finishedAt
+		}
+		result = append(result, run)
+	}
+
+	return result, nil
+}
+
+func (jobsDB *jobsDB) DeleteRuns(ctx context.Context, before time.Time) (int64, error) {
+	deleted, err := deleteJobRuns(ctx, jobsDB.conn, before)
+	return deleted, ErrJobs.Wrap(err)
+}
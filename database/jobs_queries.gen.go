@@ -0,0 +1,68 @@
+// Code generated by `database generate`. DO NOT EDIT.
+
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zeebo/errs"
+)
+
+const createJobRunSQL = `INSERT INTO job_runs(id, job, status, started_at)
+VALUES ($1, $2, $3, $4)`
+
+// createJobRun executes the CreateJobRun query.
+func createJobRun(ctx context.Context, db dbtx, id uuid.UUID, job string, status string, startedAt time.Time) error {
+	_, err := db.ExecContext(ctx, createJobRunSQL, id, job, status, startedAt)
+	return err
+}
+
+const finishJobRunSQL = `UPDATE job_runs SET status = $1, finished_at = $2, error_message = $3 WHERE id = $4`
+
+// finishJobRun executes the FinishJobRun query.
+func finishJobRun(ctx context.Context, db dbtx, status string, finishedAt time.Time, errorMessage string, id uuid.UUID) error {
+	_, err := db.ExecContext(ctx, finishJobRunSQL, status, finishedAt, errorMessage, id)
+	return err
+}
+
+const listJobRunsSQL = `SELECT id, job, status, started_at, finished_at, error_message
+FROM job_runs
+WHERE job = $1
+ORDER BY started_at DESC, id
+LIMIT $2`
+
+// listJobRuns executes the ListJobRuns query.
+func listJobRuns(ctx context.Context, db dbtx, job string, limit int64) (_ []jobRunsRow, err error) {
+	rows, err := db.QueryContext(ctx, listJobRunsSQL, job, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err = errs.Combine(err, rows.Close())
+	}()
+
+	var result []jobRunsRow
+	for rows.Next() {
+		var row jobRunsRow
+		if err = rows.Scan(&row.ID, &row.Job, &row.Status, &row.StartedAt, &row.FinishedAt, &row.ErrorMessage); err != nil {
+			return nil, err
+		}
+		result = append(result, row)
+	}
+
+	return result, rows.Err()
+}
+
+const deleteJobRunsSQL = `DELETE FROM job_runs WHERE started_at < $1`
+
+// deleteJobRuns executes the DeleteJobRuns query.
+func deleteJobRuns(ctx context.Context, db dbtx, startedAt time.Time) (int64, error) {
+	result, err := db.ExecContext(ctx, deleteJobRunsSQL, startedAt)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
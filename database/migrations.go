@@ -0,0 +1,218 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io/ioutil"
+	"os"
+
+	"github.com/golang-migrate/migrate/v4"
+	migratepostgres "github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/zeebo/errs"
+
+	"project_template"
+	"project_template/pkg/postgres"
+)
+
+// ensures that migrations implements project_template.Migrations.
+var _ project_template.Migrations = (*migrations)(nil)
+
+// ErrMigrations indicates that there was an error during migrations execution.
+var ErrMigrations = errs.Class("migrations error")
+
+// migrationsLockKey is the advisory lock key which serializes applying of migrations.
+var migrationsLockKey = postgres.LockKey("project_template:migrations")
+
+// migrations manages schema migrations using golang-migrate. Migrations are read
+// from the path if it is set, or from the ones embedded into the binary otherwise.
+//
+// architecture: Database
+type migrations struct {
+	conn *sql.DB
+	path string
+}
+
+// openSource opens the migration files source.
+func (migrations *migrations) openSource() (source.Driver, error) {
+	if migrations.path == "" {
+		return iofs.New(migrationsFS, "migrations")
+	}
+
+	return source.Open("file://" + migrations.path)
+}
+
+// run opens a migrate instance on a dedicated connection, calls fn and closes the instance.
+// Cancellation of ctx stops execution gracefully after the current migration.
+func (migrations *migrations) run(ctx context.Context, fn func(m *migrate.Migrate) error) (err error) {
+	conn, err := migrations.conn.Conn(ctx)
+	if err != nil {
+		return ErrMigrations.Wrap(err)
+	}
+
+	driver, err := migratepostgres.WithConnection(ctx, conn, &migratepostgres.Config{})
+	if err != nil {
+		return ErrMigrations.Wrap(errs.Combine(err, conn.Close()))
+	}
+
+	src, err := migrations.openSource()
+	if err != nil {
+		return ErrMigrations.Wrap(errs.Combine(err, driver.Close()))
+	}
+
+	m, err := migrate.NewWithInstance("source", src, "postgres", driver)
+	if err != nil {
+		return ErrMigrations.Wrap(errs.Combine(err, src.Close(), driver.Close()))
+	}
+	defer func() {
+		srcErr, dbErr := m.Close()
+		err = errs.Combine(err, ErrMigrations.Wrap(srcErr), ErrMigrations.Wrap(dbErr))
+	}()
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			m.GracefulStop <- true
+		case <-done:
+		}
+	}()
+
+	err = fn(m)
+	if errors.Is(err, migrate.ErrNoChange) {
+		return nil
+	}
+
+	return ErrMigrations.Wrap(err)
+}
+
+// currentVersion returns current schema version, it is 0 if no migration is applied.
+func currentVersion(m *migrate.Migrate) (uint, bool, error) {
+	version, dirty, err := m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, false, nil
+	}
+
+	return version, dirty, err
+}
+
+// Status returns the current schema version and the list of pending migrations.
+func (migrations *migrations) Status(ctx context.Context) (status project_template.MigrationStatus, err error) {
+	err = migrations.run(ctx, func(m *migrate.Migrate) (err error) {
+		status.Version, status.Dirty, err = currentVersion(m)
+		return err
+	})
+	if err != nil {
+		return project_template.MigrationStatus{}, err
+	}
+
+	status.Pending, err = migrations.after(status.Version)
+	return status, err
+}
+
+// Pending returns migrations that are not applied yet, along with their sql.
+func (migrations *migrations) Pending(ctx context.Context) ([]project_template.Migration, error) {
+	var current uint
+	err := migrations.run(ctx, func(m *migrate.Migrate) (err error) {
+		current, _, err = currentVersion(m)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return migrations.after(current)
+}
+
+// after returns all up migrations with version greater than the given one.
+func (migrations *migrations) after(current uint) (_ []project_template.Migration, err error) {
+	src, err := migrations.openSource()
+	if err != nil {
+		return nil, ErrMigrations.Wrap(err)
+	}
+	defer func() {
+		err = errs.Combine(err, ErrMigrations.Wrap(src.Close()))
+	}()
+
+	var result []project_template.Migration
+
+	version, err := src.First()
+	for err == nil {
+		if version > current {
+			migration, err := readUp(src, version)
+			if err != nil {
+				return nil, ErrMigrations.Wrap(err)
+			}
+
+			result = append(result, migration)
+		}
+
+		version, err = src.Next(version)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, ErrMigrations.Wrap(err)
+	}
+
+	return result, nil
+}
+
+// readUp reads up migration of the given version from source.
+func readUp(src source.Driver, version uint) (_ project_template.Migration, err error) {
+	body, name, err := src.ReadUp(version)
+	if err != nil {
+		return project_template.Migration{}, err
+	}
+	defer func() {
+		err = errs.Combine(err, body.Close())
+	}()
+
+	sql, err := ioutil.ReadAll(body)
+	if err != nil {
+		return project_template.Migration{}, err
+	}
+
+	return project_template.Migration{Version: version, Name: name, SQL: string(sql)}, nil
+}
+
+// Up applies all pending migrations. Concurrent calls, e.g. from several replicas
+// starting at the same time, wait for each other under a postgres advisory lock.
+func (migrations *migrations) Up(ctx context.Context) error {
+	err := postgres.WithAdvisoryLock(ctx, migrations.conn, migrationsLockKey, func(ctx context.Context) error {
+		return migrations.run(ctx, func(m *migrate.Migrate) error {
+			return m.Up()
+		})
+	})
+
+	return ErrMigrations.Wrap(err)
+}
+
+// Down rolls back all applied migrations.
+func (migrations *migrations) Down(ctx context.Context) error {
+	return migrations.run(ctx, func(m *migrate.Migrate) error {
+		return m.Down()
+	})
+}
+
+// Goto migrates the schema up or down to the given version.
+func (migrations *migrations) Goto(ctx context.Context, version uint) error {
+	return migrations.run(ctx, func(m *migrate.Migrate) error {
+		return m.Migrate(version)
+	})
+}
+
+// Steps applies n next migrations if n is positive, or rolls back -n last migrations otherwise.
+func (migrations *migrations) Steps(ctx context.Context, n int) error {
+	return migrations.run(ctx, func(m *migrate.Migrate) error {
+		return m.Steps(n)
+	})
+}
+
+// Force sets the schema version without running migrations and resets the dirty flag.
+func (migrations *migrations) Force(ctx context.Context, version int) error {
+	return migrations.run(ctx, func(m *migrate.Migrate) error {
+		return m.Force(version)
+	})
+}
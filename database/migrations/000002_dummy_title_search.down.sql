@@ -0,0 +1,2 @@
+-- pg_trgm is kept, since other schemas of the database may use it.
+ALTER TABLE dummy DROP COLUMN IF EXISTS title_search;
@@ -0,0 +1,7 @@
+-- the search relies on the pg_trgm extension, which is shared by all schemas of the database, so it's
+-- a prerequisite installed in public once per database rather than by a migration of every schema,
+-- and its functions and operators are referenced as public.* everywhere.
+
+-- adding a stored generated column rewrites the table, which is fine while it's small.
+ALTER TABLE dummy ADD COLUMN IF NOT EXISTS title_search TSVECTOR
+    GENERATED ALWAYS AS (to_tsvector('simple', COALESCE(title, ''))) STORED;
@@ -0,0 +1 @@
+DROP INDEX CONCURRENTLY IF EXISTS dummy_title_search_idx;
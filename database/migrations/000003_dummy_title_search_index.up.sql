@@ -0,0 +1 @@
+CREATE INDEX CONCURRENTLY IF NOT EXISTS dummy_title_search_idx ON dummy USING GIN (title_search);
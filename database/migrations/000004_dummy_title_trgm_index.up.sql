@@ -0,0 +1 @@
+CREATE INDEX CONCURRENTLY IF NOT EXISTS dummy_title_trgm_idx ON dummy USING GIN (title public.gin_trgm_ops);
@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS dummy_sync_uuid_id ON dummy;
+
+DROP FUNCTION IF EXISTS dummy_sync_uuid_id();
+
+ALTER TABLE dummy DROP COLUMN IF EXISTS uuid_id;
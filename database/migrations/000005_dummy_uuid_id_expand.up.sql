@@ -0,0 +1,20 @@
+-- dummy ids are migrated from BYTEA with text representation of uuid to native UUID in three steps,
+-- so the table is never locked for the time of a full scan or rewrite: this one adds the new column,
+-- keeps it in sync with a trigger and backfills existing rows, the next ones index and swap columns.
+ALTER TABLE dummy ADD COLUMN IF NOT EXISTS uuid_id UUID;
+
+CREATE OR REPLACE FUNCTION dummy_sync_uuid_id() RETURNS TRIGGER AS
+$$
+BEGIN
+    NEW.uuid_id := encode(NEW.id, 'escape')::UUID;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS dummy_sync_uuid_id ON dummy;
+CREATE TRIGGER dummy_sync_uuid_id
+    BEFORE INSERT OR UPDATE OF id ON dummy
+    FOR EACH ROW
+EXECUTE FUNCTION dummy_sync_uuid_id();
+
+UPDATE dummy SET uuid_id = encode(id, 'escape')::UUID WHERE uuid_id IS NULL;
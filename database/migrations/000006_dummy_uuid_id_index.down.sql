@@ -0,0 +1 @@
+DROP INDEX CONCURRENTLY IF EXISTS dummy_uuid_id_idx;
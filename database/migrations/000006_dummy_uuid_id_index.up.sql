@@ -0,0 +1 @@
+CREATE UNIQUE INDEX CONCURRENTLY IF NOT EXISTS dummy_uuid_id_idx ON dummy (uuid_id);
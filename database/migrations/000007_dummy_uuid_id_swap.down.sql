@@ -0,0 +1,28 @@
+-- restores the state after 000006: BYTEA primary key, nullable uuid_id with unique index and sync trigger.
+ALTER TABLE dummy RENAME COLUMN id TO uuid_id;
+
+ALTER TABLE dummy ADD COLUMN id BYTEA;
+
+UPDATE dummy SET id = convert_to(uuid_id::TEXT, 'UTF8');
+
+ALTER TABLE dummy ALTER COLUMN id SET NOT NULL;
+
+ALTER TABLE dummy DROP CONSTRAINT dummy_pkey,
+    ADD CONSTRAINT dummy_pkey PRIMARY KEY (id);
+
+ALTER TABLE dummy ALTER COLUMN uuid_id DROP NOT NULL;
+
+CREATE UNIQUE INDEX dummy_uuid_id_idx ON dummy (uuid_id);
+
+CREATE OR REPLACE FUNCTION dummy_sync_uuid_id() RETURNS TRIGGER AS
+$$
+BEGIN
+    NEW.uuid_id := encode(NEW.id, 'escape')::UUID;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER dummy_sync_uuid_id
+    BEFORE INSERT OR UPDATE OF id ON dummy
+    FOR EACH ROW
+EXECUTE FUNCTION dummy_sync_uuid_id();
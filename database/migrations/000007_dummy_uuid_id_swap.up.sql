@@ -0,0 +1,22 @@
+-- the check constraint is validated without blocking writes, and SET NOT NULL relies on it instead of a full scan.
+ALTER TABLE dummy ADD CONSTRAINT dummy_uuid_id_not_null CHECK (uuid_id IS NOT NULL) NOT VALID;
+
+ALTER TABLE dummy VALIDATE CONSTRAINT dummy_uuid_id_not_null;
+
+-- lint:ignore set-not-null
+ALTER TABLE dummy ALTER COLUMN uuid_id SET NOT NULL;
+
+ALTER TABLE dummy DROP CONSTRAINT dummy_uuid_id_not_null;
+
+DROP TRIGGER dummy_sync_uuid_id ON dummy;
+
+DROP FUNCTION dummy_sync_uuid_id();
+
+-- the unique index is renamed to dummy_pkey, so the primary key is swapped without building an index.
+ALTER TABLE dummy DROP CONSTRAINT dummy_pkey,
+    ADD CONSTRAINT dummy_pkey PRIMARY KEY USING INDEX dummy_uuid_id_idx;
+
+-- lint:ignore drop-column
+ALTER TABLE dummy DROP COLUMN id;
+
+ALTER TABLE dummy RENAME COLUMN uuid_id TO id;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS outbox
+(
+    id              UUID PRIMARY KEY         NOT NULL,
+    type            VARCHAR                  NOT NULL,
+    payload         JSONB                    NOT NULL,
+    created_at      TIMESTAMP WITH TIME ZONE NOT NULL,
+    attempts        INTEGER                  NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    published_at    TIMESTAMP WITH TIME ZONE,
+    last_error      VARCHAR
+);
+
+CREATE INDEX IF NOT EXISTS outbox_pending_idx ON outbox (next_attempt_at) WHERE published_at IS NULL;
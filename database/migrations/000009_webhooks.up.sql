@@ -0,0 +1,30 @@
+CREATE TABLE IF NOT EXISTS webhook_subscriptions
+(
+    id          UUID PRIMARY KEY         NOT NULL,
+    url         VARCHAR                  NOT NULL,
+    event_types JSONB                    NOT NULL,
+    secret      VARCHAR                  NOT NULL,
+    active      BOOLEAN                  NOT NULL,
+    created_at  TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at  TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries
+(
+    id              UUID PRIMARY KEY         NOT NULL,
+    subscription_id UUID                     NOT NULL REFERENCES webhook_subscriptions (id) ON DELETE CASCADE,
+    event_id        UUID                     NOT NULL,
+    event_type      VARCHAR                  NOT NULL,
+    payload         JSONB                    NOT NULL,
+    status          VARCHAR                  NOT NULL DEFAULT 'pending',
+    attempts        INTEGER                  NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+    last_attempt_at TIMESTAMP WITH TIME ZONE,
+    response_status INTEGER                  NOT NULL DEFAULT 0,
+    last_error      VARCHAR,
+    created_at      TIMESTAMP WITH TIME ZONE NOT NULL,
+    delivered_at    TIMESTAMP WITH TIME ZONE,
+    UNIQUE (subscription_id, event_id)
+);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_pending_idx ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
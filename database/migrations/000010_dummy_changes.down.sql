@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS dummy_record_update ON dummy;
+DROP TRIGGER IF EXISTS dummy_record_change ON dummy;
+DROP FUNCTION IF EXISTS dummy_record_change();
+DROP TABLE IF EXISTS dummy_changes;
@@ -0,0 +1,51 @@
+-- every change of the dummy table is recorded by a trigger and announced with NOTIFY, so every
+-- replica of the app can stream changes to its clients, and clients can resume from the last change they saw.
+CREATE TABLE IF NOT EXISTS dummy_changes
+(
+    id         BIGSERIAL PRIMARY KEY    NOT NULL,
+    op         VARCHAR                  NOT NULL,
+    dummy_id   UUID                     NOT NULL,
+    title      VARCHAR,
+    status     INTEGER                  NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    changed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS dummy_changes_changed_at_idx ON dummy_changes (changed_at);
+
+-- the notification carries only the change id, since payloads are limited to 8000 bytes.
+CREATE OR REPLACE FUNCTION dummy_record_change() RETURNS TRIGGER AS
+$$
+DECLARE
+    changed   dummy%ROWTYPE;
+    change_id BIGINT;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        changed := OLD;
+    ELSE
+        changed := NEW;
+    END IF;
+
+    INSERT INTO dummy_changes (op, dummy_id, title, status, created_at)
+    VALUES (CASE TG_OP WHEN 'INSERT' THEN 'created' WHEN 'UPDATE' THEN 'updated' ELSE 'deleted' END,
+            changed.id, changed.title, changed.status, changed.created_at)
+    RETURNING id INTO change_id;
+
+    PERFORM pg_notify('dummy_changes', change_id::TEXT);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS dummy_record_change ON dummy;
+CREATE TRIGGER dummy_record_change
+    AFTER INSERT OR DELETE ON dummy
+    FOR EACH ROW
+EXECUTE FUNCTION dummy_record_change();
+
+-- updates which don't change the row are not recorded.
+DROP TRIGGER IF EXISTS dummy_record_update ON dummy;
+CREATE TRIGGER dummy_record_update
+    AFTER UPDATE ON dummy
+    FOR EACH ROW
+    WHEN (OLD.* IS DISTINCT FROM NEW.*)
+EXECUTE FUNCTION dummy_record_change();
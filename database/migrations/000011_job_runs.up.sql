@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS job_runs
+(
+    id            UUID PRIMARY KEY         NOT NULL,
+    job           VARCHAR                  NOT NULL,
+    status        VARCHAR                  NOT NULL,
+    started_at    TIMESTAMP WITH TIME ZONE NOT NULL,
+    finished_at   TIMESTAMP WITH TIME ZONE,
+    error_message VARCHAR
+);
+
+CREATE INDEX IF NOT EXISTS job_runs_job_started_at_idx ON job_runs (job, started_at DESC);
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS queue_tasks
+(
+    id           UUID PRIMARY KEY         NOT NULL,
+    kind         VARCHAR                  NOT NULL,
+    payload      JSONB                    NOT NULL,
+    dedup_key    VARCHAR                  NOT NULL DEFAULT '',
+    status       VARCHAR                  NOT NULL DEFAULT 'pending',
+    attempts     INTEGER                  NOT NULL DEFAULT 0,
+    max_attempts INTEGER                  NOT NULL,
+    run_at       TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_error   VARCHAR,
+    created_at   TIMESTAMP WITH TIME ZONE NOT NULL,
+    finished_at  TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS queue_tasks_pending_idx ON queue_tasks (run_at) WHERE status = 'pending';
+
+CREATE UNIQUE INDEX IF NOT EXISTS queue_tasks_dedup_key_idx ON queue_tasks (kind, dedup_key)
+    WHERE dedup_key <> '' AND status = 'pending';
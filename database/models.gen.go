@@ -0,0 +1,110 @@
+// Code generated by `database generate`. DO NOT EDIT.
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// dbtx is implemented by *sql.DB, *sql.Conn and *sql.Tx, so generated queries can run in transactions.
+type dbtx interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// dummyRow is a row of the dummy table.
+type dummyRow struct {
+	Title     sql.NullString
+	Status    int32
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+// dummyChangesRow is a row of the dummy_changes table.
+type dummyChangesRow struct {
+	ID        int64
+	Op        string
+	DummyID   uuid.UUID
+	Title     sql.NullString
+	Status    int32
+	CreatedAt time.Time
+	ChangedAt time.Time
+}
+
+// jobRunsRow is a row of the job_runs table.
+type jobRunsRow struct {
+	ID           uuid.UUID
+	Job          string
+	Status       string
+	StartedAt    time.Time
+	FinishedAt   sql.NullTime
+	ErrorMessage sql.NullString
+}
+
+// outboxRow is a row of the outbox table.
+type outboxRow struct {
+	ID            uuid.UUID
+	Type          string
+	Payload       json.RawMessage
+	CreatedAt     time.Time
+	Attempts      int32
+	NextAttemptAt time.Time
+	PublishedAt   sql.NullTime
+	LastError     sql.NullString
+}
+
+// queueTasksRow is a row of the queue_tasks table.
+type queueTasksRow struct {
+	ID          uuid.UUID
+	Kind        string
+	Payload     json.RawMessage
+	DedupKey    string
+	Status      string
+	Attempts    int32
+	MaxAttempts int32
+	RunAt       time.Time
+	LastError   sql.NullString
+	CreatedAt   time.Time
+	FinishedAt  sql.NullTime
+}
+
+// rateLimitBucketsRow is a row of the rate_limit_buckets table.
+type rateLimitBucketsRow struct {
+	Key       string
+	Tokens    float64
+	UpdatedAt time.Time
+}
+
+// webhookDeliveriesRow is a row of the webhook_deliveries table.
+type webhookDeliveriesRow struct {
+	ID             uuid.UUID
+	SubscriptionID uuid.UUID
+	EventID        uuid.UUID
+	EventType      string
+	Payload        json.RawMessage
+	Status         string
+	Attempts       int32
+	NextAttemptAt  time.Time
+	LastAttemptAt  sql.NullTime
+	ResponseStatus int32
+	LastError      sql.NullString
+	CreatedAt      time.Time
+	DeliveredAt    sql.NullTime
+}
+
+// webhookSubscriptionsRow is a row of the webhook_subscriptions table.
+type webhookSubscriptionsRow struct {
+	ID         uuid.UUID
+	URL        string
+	EventTypes json.RawMessage
+	Secret     string
+	Active     bool
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
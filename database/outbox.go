@@ -0,0 +1,97 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zeebo/errs"
+
+	"project_template/events"
+)
+
+// ensures that outboxDB implements events.DB.
+var _ events.DB = (*outboxDB)(nil)
+
+// ErrOutbox indicates that there was an error in the database.
+var ErrOutbox = errs.Class("outbox repository error")
+
+// outboxDB provides access to the outbox, queries are generated from database/queries/outbox.sql.
+//
+// architecture: Database
+type outboxDB struct {
+	conn *sql.DB
+}
+
+func (outboxDB *outboxDB) Claim(ctx context.Context, limit int, leaseUntil time.Time) ([]events.Event, error) {
+	rows, err := claimOutboxEvents(ctx, outboxDB.conn, leaseUntil, int64(limit))
+	if err != nil {
+		return nil, ErrOutbox.Wrap(err)
+	}
+
+	result := make([]events.Event, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, events.Event{
+			ID:        row.ID,
+			Type:      row.Type,
+			Payload:   row.Payload,
+			CreatedAt: row.CreatedAt,
+			Attempts:  int(row.Attempts),
+		})
+	}
+
+	return result, nil
+}
+
+func (outboxDB *outboxDB) MarkPublished(ctx context.Context, id uuid.UUID, publishedAt time.Time) error {
+	return ErrOutbox.Wrap(markOutboxEventPublished(ctx, outboxDB.conn, publishedAt, id))
+}
+
+func (outboxDB *outboxDB) Retry(ctx context.Context, id uuid.UUID, nextAttemptAt time.Time, lastError string) error {
+	return ErrOutbox.Wrap(retryOutboxEvent(ctx, outboxDB.conn, nextAttemptAt, lastError, id))
+}
+
+func (outboxDB *outboxDB) DeletePublished(ctx context.Context, before time.Time) (int64, error) {
+	deleted, err := deletePublishedOutboxEvents(ctx, outboxDB.conn, before)
+	return deleted, ErrOutbox.Wrap(err)
+}
+
+// insertEvents stores events in the outbox, it's called by repositories in the transaction of the change.
+func insertEvents(ctx context.Context, db dbtx, list []events.Event) error {
+	if len(list) == 0 {
+		return nil
+	}
+
+	var (
+		ids       = make([]uuid.UUID, 0, len(list))
+		types     = make([]string, 0, len(list))
+		payloads  = make([]string, 0, len(list))
+		createdAt = make([]time.Time, 0, len(list))
+	)
+	for _, event := range list {
+		ids = append(ids, event.ID)
+		types = append(types, event.Type)
+		payloads = append(payloads, string(event.Payload))
+		createdAt = append(createdAt, event.CreatedAt)
+	}
+
+	return createOutboxEvents(ctx, db, ids, types, payloads, createdAt)
+}
+
+// withTx runs fn in a transaction, which is committed if fn succeeds and rolled back otherwise.
+func withTx(ctx context.Context, conn *sql.DB, fn func(tx *sql.Tx) error) (err error) {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			err = errs.Combine(err, tx.Rollback())
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	return fn(tx)
+}
@@ -0,0 +1,95 @@
+// Code generated by `database generate`. DO NOT EDIT.
+
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/zeebo/errs"
+)
+
+const createOutboxEventsSQL = `INSERT INTO outbox (id, type, payload, created_at)
+SELECT id, type, payload, created_at
+FROM unnest($1::uuid[], $2::varchar[], $3::jsonb[], $4::timestamptz[]) AS e(id, type, payload, created_at)`
+
+// createOutboxEvents executes the CreateOutboxEvents query.
+func createOutboxEvents(ctx context.Context, db dbtx, id []uuid.UUID, typeParam []string, payload []string, createdAt []time.Time) error {
+	_, err := db.ExecContext(ctx, createOutboxEventsSQL, pq.Array(id), pq.Array(typeParam), pq.Array(payload), pq.Array(createdAt))
+	return err
+}
+
+const claimOutboxEventsSQL = `WITH claimed AS (
+    UPDATE outbox SET attempts = attempts + 1, next_attempt_at = $1
+    WHERE id IN (
+        SELECT id FROM outbox
+        WHERE published_at IS NULL AND next_attempt_at <= now()
+        ORDER BY next_attempt_at, created_at, id
+        LIMIT $2
+        FOR UPDATE SKIP LOCKED
+    )
+    RETURNING id, type, payload, created_at, attempts
+)
+SELECT id, type, payload, created_at, attempts FROM claimed ORDER BY created_at, id`
+
+// claimOutboxEventsRow is a row returned by the ClaimOutboxEvents query.
+type claimOutboxEventsRow struct {
+	ID        uuid.UUID
+	Type      string
+	Payload   json.RawMessage
+	CreatedAt time.Time
+	Attempts  int32
+}
+
+// claimOutboxEvents executes the ClaimOutboxEvents query.
+func claimOutboxEvents(ctx context.Context, db dbtx, nextAttemptAt time.Time, limit int64) (_ []claimOutboxEventsRow, err error) {
+	rows, err := db.QueryContext(ctx, claimOutboxEventsSQL, nextAttemptAt, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err = errs.Combine(err, rows.Close())
+	}()
+
+	var result []claimOutboxEventsRow
+	for rows.Next() {
+		var row claimOutboxEventsRow
+		if err = rows.Scan(&row.ID, &row.Type, &row.Payload, &row.CreatedAt, &row.Attempts); err != nil {
+			return nil, err
+		}
+		result = append(result, row)
+	}
+
+	return result, rows.Err()
+}
+
+const markOutboxEventPublishedSQL = `UPDATE outbox SET published_at = $1, last_error = NULL WHERE id = $2`
+
+// markOutboxEventPublished executes the MarkOutboxEventPublished query.
+func markOutboxEventPublished(ctx context.Context, db dbtx, publishedAt time.Time, id uuid.UUID) error {
+	_, err := db.ExecContext(ctx, markOutboxEventPublishedSQL, publishedAt, id)
+	return err
+}
+
+const retryOutboxEventSQL = `UPDATE outbox SET next_attempt_at = $1, last_error = $2 WHERE id = $3`
+
+// retryOutboxEvent executes the RetryOutboxEvent query.
+func retryOutboxEvent(ctx context.Context, db dbtx, nextAttemptAt time.Time, lastError string, id uuid.UUID) error {
+	_, err := db.ExecContext(ctx, retryOutboxEventSQL, nextAttemptAt, lastError, id)
+	return err
+}
+
+const deletePublishedOutboxEventsSQL = `DELETE FROM outbox WHERE published_at < $1`
+
+// deletePublishedOutboxEvents executes the DeletePublishedOutboxEvents query.
+func deletePublishedOutboxEvents(ctx context.Context, db dbtx, publishedAt time.Time) (int64, error) {
+	result, err := db.ExecContext(ctx, deletePublishedOutboxEventsSQL, publishedAt)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
@@ -0,0 +1,54 @@
+-- Queries of the dummy repository, run `database generate` after changing them.
+
+-- name: ListDummies :many
+SELECT id, title, status, created_at FROM dummy;
+
+-- name: ListDummiesPage :many
+SELECT id, title, status, created_at FROM dummy ORDER BY created_at, id LIMIT $1 OFFSET $2;
+
+-- name: GetDummy :one
+SELECT id, title, status, created_at FROM dummy WHERE id = $1 LIMIT 1;
+
+-- name: CreateDummy :exec
+INSERT INTO dummy(id, title, status, created_at)
+VALUES ($1, $2, $3, $4);
+
+-- name: UpdateDummy :execrows
+UPDATE dummy SET title = $1, status = $2 WHERE id = $3;
+
+-- name: DeleteDummy :execrows
+DELETE FROM dummy WHERE id = $1;
+
+-- name: SearchDummies :many
+SELECT id, title, status, created_at,
+       ts_rank(title_search, query) + public.similarity(COALESCE(title, ''), $1) AS rank,
+       ts_headline('simple', COALESCE(title, ''), query, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true') AS highlight
+FROM dummy, websearch_to_tsquery('simple', $1) AS query
+WHERE title_search @@ query OR title OPERATOR(public.%) $1
+ORDER BY rank DESC, created_at DESC, id
+LIMIT $2 OFFSET $3;
+
+-- name: GetDummyChange :one
+SELECT id, op, dummy_id, title, status, created_at, changed_at FROM dummy_changes WHERE id = $1 LIMIT 1;
+
+-- name: ListDummyChanges :many
+SELECT id, op, dummy_id, title, status, created_at, changed_at FROM dummy_changes WHERE id > $1 ORDER BY id LIMIT $2;
+
+-- name: DeleteDummyChanges :execrows
+DELETE FROM dummy_changes WHERE changed_at < $1;
+
+-- name: CreateDummies :many
+INSERT INTO dummy (id, title, status, created_at)
+SELECT id, title, status, created_at
+FROM unnest($1::uuid[], $2::varchar[], $3::integer[], $4::timestamptz[]) AS c(id, title, status, created_at)
+ON CONFLICT (id) DO NOTHING
+RETURNING id;
+
+-- name: UpdateDummies :many
+UPDATE dummy SET title = u.title, status = u.status
+FROM unnest($1::uuid[], $2::varchar[], $3::integer[]) AS u(id, title, status)
+WHERE dummy.id = u.id
+RETURNING dummy.id;
+
+-- name: DeleteDummies :many
+DELETE FROM dummy WHERE id = ANY($1::uuid[]) RETURNING id;
@@ -0,0 +1,19 @@
+-- Queries of the jobs repository, run `database generate` after changing them.
+-- The leadership of jobs is taken with advisory locks, see jobsDB.TryLead.
+
+-- name: CreateJobRun :exec
+INSERT INTO job_runs(id, job, status, started_at)
+VALUES ($1, $2, $3, $4);
+
+-- name: FinishJobRun :exec
+UPDATE job_runs SET status = $1, finished_at = $2, error_message = $3 WHERE id = $4;
+
+-- name: ListJobRuns :many
+SELECT id, job, status, started_at, finished_at, error_message
+FROM job_runs
+WHERE job = $1
+ORDER BY started_at DESC, id
+LIMIT $2;
+
+-- name: DeleteJobRuns :execrows
+DELETE FROM job_runs WHERE started_at < $1;
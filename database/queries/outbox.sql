@@ -0,0 +1,31 @@
+-- Queries of the outbox repository, run `database generate` after changing them.
+-- Events are inserted by repositories of changed entities, see insertEvents.
+
+-- name: CreateOutboxEvents :exec
+INSERT INTO outbox (id, type, payload, created_at)
+SELECT id, type, payload, created_at
+FROM unnest($1::uuid[], $2::varchar[], $3::jsonb[], $4::timestamptz[]) AS e(id, type, payload, created_at);
+
+-- order of rows returned by UPDATE is unspecified, so claimed events are sorted afterwards.
+-- name: ClaimOutboxEvents :many
+WITH claimed AS (
+    UPDATE outbox SET attempts = attempts + 1, next_attempt_at = $1
+    WHERE id IN (
+        SELECT id FROM outbox
+        WHERE published_at IS NULL AND next_attempt_at <= now()
+        ORDER BY next_attempt_at, created_at, id
+        LIMIT $2
+        FOR UPDATE SKIP LOCKED
+    )
+    RETURNING id, type, payload, created_at, attempts
+)
+SELECT id, type, payload, created_at, attempts FROM claimed ORDER BY created_at, id;
+
+-- name: MarkOutboxEventPublished :exec
+UPDATE outbox SET published_at = $1, last_error = NULL WHERE id = $2;
+
+-- name: RetryOutboxEvent :exec
+UPDATE outbox SET next_attempt_at = $1, last_error = $2 WHERE id = $3;
+
+-- name: DeletePublishedOutboxEvents :execrows
+DELETE FROM outbox WHERE published_at < $1;
@@ -0,0 +1,41 @@
+-- Queries of the queue repository, run `database generate` after changing them.
+
+-- name: CreateQueueTask :execrows
+INSERT INTO queue_tasks(id, kind, payload, dedup_key, max_attempts, run_at, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (kind, dedup_key) WHERE dedup_key <> '' AND status = 'pending' DO NOTHING;
+
+-- name: GetQueueTask :one
+SELECT id, kind, payload, dedup_key, status, attempts, max_attempts, run_at, last_error, created_at, finished_at
+FROM queue_tasks
+WHERE id = $1
+LIMIT 1;
+
+-- name: GetPendingQueueTask :one
+SELECT id, kind, payload, dedup_key, status, attempts, max_attempts, run_at, last_error, created_at, finished_at
+FROM queue_tasks
+WHERE kind = $1 AND dedup_key = $2 AND status = 'pending'
+LIMIT 1;
+
+-- name: ClaimQueueTasks :many
+UPDATE queue_tasks SET attempts = attempts + 1, run_at = $1
+WHERE id IN (
+    SELECT id FROM queue_tasks
+    WHERE status = 'pending' AND run_at <= now()
+    ORDER BY run_at, created_at
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, kind, payload, dedup_key, status, attempts, max_attempts, run_at, last_error, created_at, finished_at;
+
+-- name: CompleteQueueTask :exec
+UPDATE queue_tasks SET status = 'succeeded', finished_at = $1, last_error = NULL WHERE id = $2;
+
+-- name: RetryQueueTask :exec
+UPDATE queue_tasks SET run_at = $1, last_error = $2 WHERE id = $3;
+
+-- name: KillQueueTask :exec
+UPDATE queue_tasks SET status = 'dead', finished_at = $1, last_error = $2 WHERE id = $3;
+
+-- name: DeleteFinishedQueueTasks :execrows
+DELETE FROM queue_tasks WHERE status <> 'pending' AND finished_at < $1;
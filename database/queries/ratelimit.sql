@@ -0,0 +1,16 @@
+-- Queries of the rate limit repository, run `database generate` after changing them.
+-- A bucket is created empty and locked before it's updated, so concurrent requests take tokens one by one.
+
+-- name: CreateRateLimitBucket :exec
+INSERT INTO rate_limit_buckets(key, tokens, updated_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (key) DO NOTHING;
+
+-- name: LockRateLimitBucket :one
+SELECT key, tokens, updated_at FROM rate_limit_buckets WHERE key = $1 FOR UPDATE;
+
+-- name: UpdateRateLimitBucket :exec
+UPDATE rate_limit_buckets SET tokens = $1, updated_at = $2 WHERE key = $3;
+
+-- name: DeleteRateLimitBuckets :execrows
+DELETE FROM rate_limit_buckets WHERE updated_at < $1;
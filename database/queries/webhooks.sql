@@ -0,0 +1,57 @@
+-- Queries of the webhooks repository, run `database generate` after changing them.
+
+-- name: ListWebhookSubscriptions :many
+SELECT id, url, event_types, secret, active, created_at, updated_at FROM webhook_subscriptions ORDER BY created_at, id;
+
+-- name: GetWebhookSubscription :one
+SELECT id, url, event_types, secret, active, created_at, updated_at FROM webhook_subscriptions WHERE id = $1 LIMIT 1;
+
+-- name: CreateWebhookSubscription :exec
+INSERT INTO webhook_subscriptions(id, url, event_types, secret, active, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7);
+
+-- name: UpdateWebhookSubscription :execrows
+UPDATE webhook_subscriptions SET url = $1, event_types = $2, secret = $3, active = $4, updated_at = $5 WHERE id = $6;
+
+-- name: DeleteWebhookSubscription :execrows
+DELETE FROM webhook_subscriptions WHERE id = $1;
+
+-- name: CreateWebhookDelivery :exec
+INSERT INTO webhook_deliveries(id, subscription_id, event_id, event_type, payload, created_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (subscription_id, event_id) DO NOTHING;
+
+-- name: ListWebhookDeliveries :many
+SELECT id, subscription_id, event_id, event_type, payload, status, attempts, next_attempt_at,
+       last_attempt_at, response_status, last_error, created_at, delivered_at
+FROM webhook_deliveries
+WHERE subscription_id = $1
+ORDER BY created_at DESC, id
+LIMIT $2 OFFSET $3;
+
+-- name: ClaimWebhookDeliveries :many
+UPDATE webhook_deliveries SET attempts = attempts + 1, next_attempt_at = $1
+WHERE id IN (
+    SELECT d.id FROM webhook_deliveries d
+    JOIN webhook_subscriptions s ON s.id = d.subscription_id
+    WHERE d.status = 'pending' AND d.next_attempt_at <= now() AND s.active
+    ORDER BY d.next_attempt_at, d.created_at
+    LIMIT $2
+    FOR UPDATE OF d SKIP LOCKED
+)
+RETURNING id, subscription_id, event_id, event_type, payload, status, attempts, next_attempt_at,
+          last_attempt_at, response_status, last_error, created_at, delivered_at;
+
+-- name: MarkWebhookDeliveryDelivered :exec
+UPDATE webhook_deliveries
+SET status = 'delivered', delivered_at = $1, last_attempt_at = $1, response_status = $2, last_error = NULL
+WHERE id = $3;
+
+-- name: FailWebhookDelivery :exec
+UPDATE webhook_deliveries
+SET status = $1, next_attempt_at = $2, last_attempt_at = $3, response_status = $4, last_error = $5
+WHERE id = $6;
+
+-- name: RedeliverWebhookDelivery :execrows
+UPDATE webhook_deliveries SET status = 'pending', attempts = 0, next_attempt_at = now()
+WHERE id = $1 AND subscription_id = $2;
@@ -0,0 +1,117 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zeebo/errs"
+
+	"project_template/queue"
+)
+
+// ensures that queueDB implements queue.DB.
+var _ queue.DB = (*queueDB)(nil)
+
+// ErrQueue indicates that there was an error in the database.
+var ErrQueue = errs.Class("queue repository error")
+
+// enqueueAttempts limits attempts to enqueue a deduplicated task, the conflicting task
+// can be finished between the insert and the select of it.
+const enqueueAttempts = 3
+
+// queueDB provides access to the queue of tasks, queries are generated from database/queries/queue.sql.
+//
+// architecture: Database
+type queueDB struct {
+	conn *sql.DB
+}
+
+func (queueDB *queueDB) Enqueue(ctx context.Context, task queue.Task) (queue.Task, error) {
+	for i := 0; i < enqueueAttempts; i++ {
+		inserted, err := createQueueTask(ctx, queueDB.conn, task.ID, task.Kind, task.Payload, task.DedupKey,
+			int32(task.MaxAttempts), task.RunAt, task.CreatedAt)
+		if err != nil {
+			return queue.Task{}, ErrQueue.Wrap(err)
+		}
+		if inserted > 0 {
+			return task, nil
+		}
+
+		row, err := getPendingQueueTask(ctx, queueDB.conn, task.Kind, task.DedupKey)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			continue
+		case err != nil:
+			return queue.Task{}, ErrQueue.Wrap(err)
+		}
+		return row.toTask(), nil
+	}
+
+	return queue.Task{}, ErrQueue.New("could not enqueue task %s with dedup key %q", task.Kind, task.DedupKey)
+}
+
+func (queueDB *queueDB) Get(ctx context.Context, id uuid.UUID) (queue.Task, error) {
+	row, err := getQueueTask(ctx, queueDB.conn, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return queue.Task{}, queue.ErrNoTask.Wrap(err)
+		}
+
+		return queue.Task{}, ErrQueue.Wrap(err)
+	}
+
+	return row.toTask(), nil
+}
+
+func (queueDB *queueDB) Claim(ctx context.Context, limit int, visibleAt time.Time) ([]queue.Task, error) {
+	rows, err := claimQueueTasks(ctx, queueDB.conn, visibleAt, int64(limit))
+	if err != nil {
+		return nil, ErrQueue.Wrap(err)
+	}
+
+	tasks := make([]queue.Task, 0, len(rows))
+	for _, row := range rows {
+		tasks = append(tasks, row.toTask())
+	}
+	return tasks, nil
+}
+
+func (queueDB *queueDB) Complete(ctx context.Context, id uuid.UUID, finishedAt time.Time) error {
+	return ErrQueue.Wrap(completeQueueTask(ctx, queueDB.conn, finishedAt, id))
+}
+
+func (queueDB *queueDB) Retry(ctx context.Context, id uuid.UUID, runAt time.Time, lastError string) error {
+	return ErrQueue.Wrap(retryQueueTask(ctx, queueDB.conn, runAt, lastError, id))
+}
+
+func (queueDB *queueDB) MarkDead(ctx context.Context, id uuid.UUID, finishedAt time.Time, lastError string) error {
+	return ErrQueue.Wrap(killQueueTask(ctx, queueDB.conn, finishedAt, lastError, id))
+}
+
+func (queueDB *queueDB) DeleteFinished(ctx context.Context, before time.Time) (int64, error) {
+	deleted, err := deleteFinishedQueueTasks(ctx, queueDB.conn, before)
+	return deleted, ErrQueue.Wrap(err)
+}
+
+// toTask converts generated row to domain entity.
+func (row queueTasksRow) toTask() queue.Task {
+	task := queue.Task{
+		ID:          row.ID,
+		Kind:        row.Kind,
+		Payload:     row.Payload,
+		DedupKey:    row.DedupKey,
+		Status:      queue.TaskStatus(row.Status),
+		Attempts:    int(row.Attempts),
+		MaxAttempts: int(row.MaxAttempts),
+		RunAt:       row.RunAt,
+		LastError:   row.LastError.String,
+		CreatedAt:   row.CreatedAt,
+	}
+	if row.FinishedAt.Valid {
+		task.FinishedAt = &row.FinishedAt.Time
+	}
+	return task
+}
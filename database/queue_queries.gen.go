@@ -0,0 +1,118 @@
+// Code generated by `database generate`. DO NOT EDIT.
+
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zeebo/errs"
+)
+
+const createQueueTaskSQL = `INSERT INTO queue_tasks(id, kind, payload, dedup_key, max_attempts, run_at, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (kind, dedup_key) WHERE dedup_key <> '' AND status = 'pending' DO NOTHING`
+
+// createQueueTask executes the CreateQueueTask query.
+func createQueueTask(ctx context.Context, db dbtx, id uuid.UUID, kind string, payload json.RawMessage, dedupKey string, maxAttempts int32, runAt time.Time, createdAt time.Time) (int64, error) {
+	result, err := db.ExecContext(ctx, createQueueTaskSQL, id, kind, payload, dedupKey, maxAttempts, runAt, createdAt)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+const getQueueTaskSQL = `SELECT id, kind, payload, dedup_key, status, attempts, max_attempts, run_at, last_error, created_at, finished_at
+FROM queue_tasks
+WHERE id = $1
+LIMIT 1`
+
+// getQueueTask executes the GetQueueTask query.
+func getQueueTask(ctx context.Context, db dbtx, id uuid.UUID) (queueTasksRow, error) {
+	var row queueTasksRow
+	err := db.QueryRowContext(ctx, getQueueTaskSQL, id).Scan(&row.ID, &row.Kind, &row.Payload, &row.DedupKey, &row.Status, &row.Attempts, &row.MaxAttempts, &row.RunAt, &row.LastError, &row.CreatedAt, &row.FinishedAt)
+	return row, err
+}
+
+const getPendingQueueTaskSQL = `SELECT id, kind, payload, dedup_key, status, attempts, max_attempts, run_at, last_error, created_at, finished_at
+FROM queue_tasks
+WHERE kind = $1 AND dedup_key = $2 AND status = 'pending'
+LIMIT 1`
+
+// getPendingQueueTask executes the GetPendingQueueTask query.
+func getPendingQueueTask(ctx context.Context, db dbtx, kind string, dedupKey string) (queueTasksRow, error) {
+	var row queueTasksRow
+	err := db.QueryRowContext(ctx, getPendingQueueTaskSQL, kind, dedupKey).Scan(&row.ID, &row.Kind, &row.Payload, &row.DedupKey, &row.Status, &row.Attempts, &row.MaxAttempts, &row.RunAt, &row.LastError, &row.CreatedAt, &row.FinishedAt)
+	return row, err
+}
+
+const claimQueueTasksSQL = `UPDATE queue_tasks SET attempts = attempts + 1, run_at = $1
+WHERE id IN (
+    SELECT id FROM queue_tasks
+    WHERE status = 'pending' AND run_at <= now()
+    ORDER BY run_at, created_at
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, kind, payload, dedup_key, status, attempts, max_attempts, run_at, last_error, created_at, finished_at`
+
+// claimQueueTasks executes the ClaimQueueTasks query.
+func claimQueueTasks(ctx context.Context, db dbtx, runAt time.Time, limit int64) (_ []queueTasksRow, err error) {
+	rows, err := db.QueryContext(ctx, claimQueueTasksSQL, runAt, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err = errs.Combine(err, rows.Close())
+	}()
+
+	var result []queueTasksRow
+	for rows.Next() {
+		var row queueTasksRow
+		if err = rows.Scan(&row.ID, &row.Kind, &row.Payload, &row.DedupKey, &row.Status, &row.Attempts, &row.MaxAttempts, &row.RunAt, &row.LastError, &row.CreatedAt, &row.FinishedAt); err != nil {
+			return nil, err
+		}
+		result = append(result, row)
+	}
+
+	return result, rows.Err()
+}
+
+const completeQueueTaskSQL = `UPDATE queue_tasks SET status = 'succeeded', finished_at = $1, last_error = NULL WHERE id = $2`
+
+// completeQueueTask executes the CompleteQueueTask query.
+func completeQueueTask(ctx context.Context, db dbtx, finishedAt time.Time, id uuid.UUID) error {
+	_, err := db.ExecContext(ctx, completeQueueTaskSQL, finishedAt, id)
+	return err
+}
+
+const retryQueueTaskSQL = `UPDATE queue_tasks SET run_at = $1, last_error = $2 WHERE id = $3`
+
+// retryQueueTask executes the RetryQueueTask query.
+func retryQueueTask(ctx context.Context, db dbtx, runAt time.Time, lastError string, id uuid.UUID) error {
+	_, err := db.ExecContext(ctx, retryQueueTaskSQL, runAt, lastError, id)
+	return err
+}
+
+const killQueueTaskSQL = `UPDATE queue_tasks SET status = 'dead', finished_at = $1, last_error = $2 WHERE id = $3`
+
+// killQueueTask executes the KillQueueTask query.
+func killQueueTask(ctx context.Context, db dbtx, finishedAt time.Time, lastError string, id uuid.UUID) error {
+	_, err := db.ExecContext(ctx, killQueueTaskSQL, finishedAt, lastError, id)
+	return err
+}
+
+const deleteFinishedQueueTasksSQL = `DELETE FROM queue_tasks WHERE status <> 'pending' AND finished_at < $1`
+
+// deleteFinishedQueueTasks executes the DeleteFinishedQueueTasks query.
+func deleteFinishedQueueTasks(ctx context.Context, db dbtx, finishedAt time.Time) (int64, error) {
+	result, err := db.ExecContext(ctx, deleteFinishedQueueTasksSQL, finishedAt)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
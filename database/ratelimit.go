@@ -0,0 +1,47 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/zeebo/errs"
+
+	"project_template/ratelimit"
+)
+
+// ensures that rateLimitDB implements ratelimit.DB.
+var _ ratelimit.DB = (*rateLimitDB)(nil)
+
+// ErrRateLimit indicates that there was an error in the database.
+var ErrRateLimit = errs.Class("rate limit repository error")
+
+// rateLimitDB keeps token buckets shared by replicas, queries are generated from database/queries/ratelimit.sql.
+//
+// architecture: Database
+type rateLimitDB struct {
+	conn *sql.DB
+}
+
+// Update locks the bucket of the key, it's created as the zero Bucket first if the key has none.
+func (rateLimitDB *rateLimitDB) Update(ctx context.Context, key string, update func(bucket ratelimit.Bucket) ratelimit.Bucket) error {
+	err := withTx(ctx, rateLimitDB.conn, func(tx *sql.Tx) error {
+		if err := createRateLimitBucket(ctx, tx, key, 0, time.Time{}); err != nil {
+			return err
+		}
+
+		row, err := lockRateLimitBucket(ctx, tx, key)
+		if err != nil {
+			return err
+		}
+
+		bucket := update(ratelimit.Bucket{Tokens: row.Tokens, UpdatedAt: row.UpdatedAt})
+		return updateRateLimitBucket(ctx, tx, bucket.Tokens, bucket.UpdatedAt, key)
+	})
+	return ErrRateLimit.Wrap(err)
+}
+
+func (rateLimitDB *rateLimitDB) DeleteBuckets(ctx context.Context, before time.Time) (int64, error) {
+	deleted, err := deleteRateLimitBuckets(ctx, rateLimitDB.conn, before)
+	return deleted, ErrRateLimit.Wrap(err)
+}
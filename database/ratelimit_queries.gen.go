@@ -0,0 +1,47 @@
+// Code generated by `database generate`. DO NOT EDIT.
+
+package database
+
+import (
+	"context"
+	"time"
+)
+
+const createRateLimitBucketSQL = `INSERT INTO rate_limit_buckets(key, tokens, updated_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (key) DO NOTHING`
+
+// createRateLimitBucket executes the CreateRateLimitBucket query.
+func createRateLimitBucket(ctx context.Context, db dbtx, key string, tokens float64, updatedAt time.Time) error {
+	_, err := db.ExecContext(ctx, createRateLimitBucketSQL, key, tokens, updatedAt)
+	return err
+}
+
+const lockRateLimitBucketSQL = `SELECT key, tokens, updated_at FROM rate_limit_buckets WHERE key = $1 FOR UPDATE`
+
+// lockRateLimitBucket executes the LockRateLimitBucket query.
+func lockRateLimitBucket(ctx context.Context, db dbtx, key string) (rateLimitBucketsRow, error) {
+	var row rateLimitBucketsRow
+	err := db.QueryRowContext(ctx, lockRateLimitBucketSQL, key).Scan(&row.Key, &row.Tokens, &row.UpdatedAt)
+	return row, err
+}
+
+const updateRateLimitBucketSQL = `UPDATE rate_limit_buckets SET tokens = $1, updated_at = $2 WHERE key = $3`
+
+// updateRateLimitBucket executes the UpdateRateLimitBucket query.
+func updateRateLimitBucket(ctx context.Context, db dbtx, tokens float64, updatedAt time.Time, key string) error {
+	_, err := db.ExecContext(ctx, updateRateLimitBucketSQL, tokens, updatedAt, key)
+	return err
+}
+
+const deleteRateLimitBucketsSQL = `DELETE FROM rate_limit_buckets WHERE updated_at < $1`
+
+// deleteRateLimitBuckets executes the DeleteRateLimitBuckets query.
+func deleteRateLimitBuckets(ctx context.Context, db dbtx, updatedAt time.Time) (int64, error) {
+	result, err := db.ExecContext(ctx, deleteRateLimitBucketsSQL, updatedAt)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
@@ -0,0 +1,208 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zeebo/errs"
+
+	"project_template/webhooks"
+)
+
+// ensures that webhooksDB implements webhooks.DB.
+var _ webhooks.DB = (*webhooksDB)(nil)
+
+// ErrWebhooks indicates that there was an error in the database.
+var ErrWebhooks = errs.Class("webhooks repository error")
+
+// webhooksDB provides access to webhook subscriptions and deliveries, queries are generated
+// from database/queries/webhooks.sql.
+//
+// architecture: Database
+type webhooksDB struct {
+	conn *sql.DB
+}
+
+func (webhooksDB *webhooksDB) ListSubscriptions(ctx context.Context) ([]webhooks.Subscription, error) {
+	rows, err := listWebhookSubscriptions(ctx, webhooksDB.conn)
+	if err != nil {
+		return nil, ErrWebhooks.Wrap(err)
+	}
+
+	result := make([]webhooks.Subscription, 0, len(rows))
+	for _, row := range rows {
+		subscription, err := row.toSubscription()
+		if err != nil {
+			return nil, ErrWebhooks.Wrap(err)
+		}
+		result = append(result, subscription)
+	}
+
+	return result, nil
+}
+
+func (webhooksDB *webhooksDB) GetSubscription(ctx context.Context, id uuid.UUID) (webhooks.Subscription, error) {
+	row, err := getWebhookSubscription(ctx, webhooksDB.conn, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return webhooks.Subscription{}, webhooks.ErrNoSubscription.Wrap(err)
+		}
+
+		return webhooks.Subscription{}, ErrWebhooks.Wrap(err)
+	}
+
+	subscription, err := row.toSubscription()
+	return subscription, ErrWebhooks.Wrap(err)
+}
+
+func (webhooksDB *webhooksDB) CreateSubscription(ctx context.Context, s webhooks.Subscription) error {
+	eventTypes, err := json.Marshal(s.EventTypes)
+	if err != nil {
+		return ErrWebhooks.Wrap(err)
+	}
+
+	err = createWebhookSubscription(ctx, webhooksDB.conn, s.ID, s.URL, eventTypes, s.Secret, s.Active, s.CreatedAt, s.UpdatedAt)
+	return ErrWebhooks.Wrap(err)
+}
+
+func (webhooksDB *webhooksDB) UpdateSubscription(ctx context.Context, s webhooks.Subscription) error {
+	eventTypes, err := json.Marshal(s.EventTypes)
+	if err != nil {
+		return ErrWebhooks.Wrap(err)
+	}
+
+	rowNum, err := updateWebhookSubscription(ctx, webhooksDB.conn, s.URL, eventTypes, s.Secret, s.Active, s.UpdatedAt, s.ID)
+	if err != nil {
+		return ErrWebhooks.Wrap(err)
+	}
+
+	if rowNum == 0 {
+		return webhooks.ErrNoSubscription.New("%s", s.ID)
+	}
+
+	return nil
+}
+
+func (webhooksDB *webhooksDB) DeleteSubscription(ctx context.Context, id uuid.UUID) error {
+	rowNum, err := deleteWebhookSubscription(ctx, webhooksDB.conn, id)
+	if err != nil {
+		return ErrWebhooks.Wrap(err)
+	}
+
+	if rowNum == 0 {
+		return webhooks.ErrNoSubscription.New("%s", id)
+	}
+
+	return nil
+}
+
+func (webhooksDB *webhooksDB) CreateDeliveries(ctx context.Context, deliveries []webhooks.Delivery) error {
+	err := withTx(ctx, webhooksDB.conn, func(tx *sql.Tx) error {
+		for _, d := range deliveries {
+			err := createWebhookDelivery(ctx, tx, d.ID, d.SubscriptionID, d.EventID, d.EventType, d.Payload, d.CreatedAt)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return ErrWebhooks.Wrap(err)
+}
+
+func (webhooksDB *webhooksDB) ListDeliveries(ctx context.Context, subscriptionID uuid.UUID, limit, offset int) ([]webhooks.Delivery, error) {
+	rows, err := listWebhookDeliveries(ctx, webhooksDB.conn, subscriptionID, int64(limit), int64(offset))
+	if err != nil {
+		return nil, ErrWebhooks.Wrap(err)
+	}
+
+	return toDeliveries(rows), nil
+}
+
+func (webhooksDB *webhooksDB) ClaimDeliveries(ctx context.Context, limit int, leaseUntil time.Time) ([]webhooks.Delivery, error) {
+	rows, err := claimWebhookDeliveries(ctx, webhooksDB.conn, leaseUntil, int64(limit))
+	if err != nil {
+		return nil, ErrWebhooks.Wrap(err)
+	}
+
+	return toDeliveries(rows), nil
+}
+
+func (webhooksDB *webhooksDB) MarkDelivered(ctx context.Context, id uuid.UUID, attempt webhooks.Attempt) error {
+	err := markWebhookDeliveryDelivered(ctx, webhooksDB.conn, attempt.At, int32(attempt.ResponseStatus), id)
+	return ErrWebhooks.Wrap(err)
+}
+
+func (webhooksDB *webhooksDB) Retry(ctx context.Context, id uuid.UUID, attempt webhooks.Attempt, nextAttemptAt time.Time) error {
+	err := failWebhookDelivery(ctx, webhooksDB.conn, string(webhooks.DeliveryPending), nextAttemptAt,
+		attempt.At, int32(attempt.ResponseStatus), attempt.Error, id)
+	return ErrWebhooks.Wrap(err)
+}
+
+func (webhooksDB *webhooksDB) MarkDead(ctx context.Context, id uuid.UUID, attempt webhooks.Attempt) error {
+	err := failWebhookDelivery(ctx, webhooksDB.conn, string(webhooks.DeliveryDead), attempt.At,
+		attempt.At, int32(attempt.ResponseStatus), attempt.Error, id)
+	return ErrWebhooks.Wrap(err)
+}
+
+func (webhooksDB *webhooksDB) Redeliver(ctx context.Context, subscriptionID, id uuid.UUID) error {
+	rowNum, err := redeliverWebhookDelivery(ctx, webhooksDB.conn, id, subscriptionID)
+	if err != nil {
+		return ErrWebhooks.Wrap(err)
+	}
+
+	if rowNum == 0 {
+		return webhooks.ErrNoDelivery.New("%s", id)
+	}
+
+	return nil
+}
+
+// toSubscription converts generated row to the domain entity.
+func (row webhookSubscriptionsRow) toSubscription() (webhooks.Subscription, error) {
+	subscription := webhooks.Subscription{
+		ID:        row.ID,
+		URL:       row.URL,
+		Secret:    row.Secret,
+		Active:    row.Active,
+		CreatedAt: row.CreatedAt,
+		UpdatedAt: row.UpdatedAt,
+	}
+
+	err := json.Unmarshal(row.EventTypes, &subscription.EventTypes)
+	return subscription, err
+}
+
+// toDeliveries converts generated rows to domain entities.
+func toDeliveries(rows []webhookDeliveriesRow) []webhooks.Delivery {
+	result := make([]webhooks.Delivery, 0, len(rows))
+	for _, row := range rows {
+		delivery := webhooks.Delivery{
+			ID:             row.ID,
+			SubscriptionID: row.SubscriptionID,
+			EventID:        row.EventID,
+			EventType:      row.EventType,
+			Payload:        row.Payload,
+			Status:         webhooks.DeliveryStatus(row.Status),
+			Attempts:       int(row.Attempts),
+			NextAttemptAt:  row.NextAttemptAt,
+			ResponseStatus: int(row.ResponseStatus),
+			LastError:      row.LastError.String,
+			CreatedAt:      row.CreatedAt,
+		}
+		// times are copied, since the loop variable is reused.
+		if row.LastAttemptAt.Valid {
+			lastAttemptAt := row.LastAttemptAt.Time
+			delivery.LastAttemptAt = &lastAttemptAt
+		}
+		if row.DeliveredAt.Valid {
+			deliveredAt := row.DeliveredAt.Time
+			delivery.DeliveredAt = &deliveredAt
+		}
+		result = append(result, delivery)
+	}
+	return result
+}
@@ -0,0 +1,184 @@
+// Code generated by `database generate`. DO NOT EDIT.
+
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zeebo/errs"
+)
+
+const listWebhookSubscriptionsSQL = `SELECT id, url, event_types, secret, active, created_at, updated_at FROM webhook_subscriptions ORDER BY created_at, id`
+
+// listWebhookSubscriptions executes the ListWebhookSubscriptions query.
+func listWebhookSubscriptions(ctx context.Context, db dbtx) (_ []webhookSubscriptionsRow, err error) {
+	rows, err := db.QueryContext(ctx, listWebhookSubscriptionsSQL)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err = errs.Combine(err, rows.Close())
+	}()
+
+	var result []webhookSubscriptionsRow
+	for rows.Next() {
+		var row webhookSubscriptionsRow
+		if err = rows.Scan(&row.ID, &row.URL, &row.EventTypes, &row.Secret, &row.Active, &row.CreatedAt, &row.UpdatedAt); err != nil {
+			return nil, err
+		}
+		result = append(result, row)
+	}
+
+	return result, rows.Err()
+}
+
+const getWebhookSubscriptionSQL = `SELECT id, url, event_types, secret, active, created_at, updated_at FROM webhook_subscriptions WHERE id = $1 LIMIT 1`
+
+// getWebhookSubscription executes the GetWebhookSubscription query.
+func getWebhookSubscription(ctx context.Context, db dbtx, id uuid.UUID) (webhookSubscriptionsRow, error) {
+	var row webhookSubscriptionsRow
+	err := db.QueryRowContext(ctx, getWebhookSubscriptionSQL, id).Scan(&row.ID, &row.URL, &row.EventTypes, &row.Secret, &row.Active, &row.CreatedAt, &row.UpdatedAt)
+	return row, err
+}
+
+const createWebhookSubscriptionSQL = `INSERT INTO webhook_subscriptions(id, url, event_types, secret, active, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+// createWebhookSubscription executes the CreateWebhookSubscription query.
+func createWebhookSubscription(ctx context.Context, db dbtx, id uuid.UUID, url string, eventTypes json.RawMessage, secret string, active bool, createdAt time.Time, updatedAt time.Time) error {
+	_, err := db.ExecContext(ctx, createWebhookSubscriptionSQL, id, url, eventTypes, secret, active, createdAt, updatedAt)
+	return err
+}
+
+const updateWebhookSubscriptionSQL = `UPDATE webhook_subscriptions SET url = $1, event_types = $2, secret = $3, active = $4, updated_at = $5 WHERE id = $6`
+
+// updateWebhookSubscription executes the UpdateWebhookSubscription query.
+func updateWebhookSubscription(ctx context.Context, db dbtx, url string, eventTypes json.RawMessage, secret string, active bool, updatedAt time.Time, id uuid.UUID) (int64, error) {
+	result, err := db.ExecContext(ctx, updateWebhookSubscriptionSQL, url, eventTypes, secret, active, updatedAt, id)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+const deleteWebhookSubscriptionSQL = `DELETE FROM webhook_subscriptions WHERE id = $1`
+
+// deleteWebhookSubscription executes the DeleteWebhookSubscription query.
+func deleteWebhookSubscription(ctx context.Context, db dbtx, id uuid.UUID) (int64, error) {
+	result, err := db.ExecContext(ctx, deleteWebhookSubscriptionSQL, id)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+const createWebhookDeliverySQL = `INSERT INTO webhook_deliveries(id, subscription_id, event_id, event_type, payload, created_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (subscription_id, event_id) DO NOTHING`
+
+// createWebhookDelivery executes the CreateWebhookDelivery query.
+func createWebhookDelivery(ctx context.Context, db dbtx, id uuid.UUID, subscriptionID uuid.UUID, eventID uuid.UUID, eventType string, payload json.RawMessage, createdAt time.Time) error {
+	_, err := db.ExecContext(ctx, createWebhookDeliverySQL, id, subscriptionID, eventID, eventType, payload, createdAt)
+	return err
+}
+
+const listWebhookDeliveriesSQL = `SELECT id, subscription_id, event_id, event_type, payload, status, attempts, next_attempt_at,
+       last_attempt_at, response_status, last_error, created_at, delivered_at
+FROM webhook_deliveries
+WHERE subscription_id = $1
+ORDER BY created_at DESC, id
+LIMIT $2 OFFSET $3`
+
+// listWebhookDeliveries executes the ListWebhookDeliveries query.
+func listWebhookDeliveries(ctx context.Context, db dbtx, subscriptionID uuid.UUID, limit int64, offset int64) (_ []webhookDeliveriesRow, err error) {
+	rows, err := db.QueryContext(ctx, listWebhookDeliveriesSQL, subscriptionID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err = errs.Combine(err, rows.Close())
+	}()
+
+	var result []webhookDeliveriesRow
+	for rows.Next() {
+		var row webhookDeliveriesRow
+		if err = rows.Scan(&row.ID, &row.SubscriptionID, &row.EventID, &row.EventType, &row.Payload, &row.Status, &row.Attempts, &row.NextAttemptAt, &row.LastAttemptAt, &row.ResponseStatus, &row.LastError, &row.CreatedAt, &row.DeliveredAt); err != nil {
+			return nil, err
+		}
+		result = append(result, row)
+	}
+
+	return result, rows.Err()
+}
+
+const claimWebhookDeliveriesSQL = `UPDATE webhook_deliveries SET attempts = attempts + 1, next_attempt_at = $1
+WHERE id IN (
+    SELECT d.id FROM webhook_deliveries d
+    JOIN webhook_subscriptions s ON s.id = d.subscription_id
+    WHERE d.status = 'pending' AND d.next_attempt_at <= now() AND s.active
+    ORDER BY d.next_attempt_at, d.created_at
+    LIMIT $2
+    FOR UPDATE OF d SKIP LOCKED
+)
+RETURNING id, subscription_id, event_id, event_type, payload, status, attempts, next_attempt_at,
+          last_attempt_at, response_status, last_error, created_at, delivered_at`
+
+// claimWebhookDeliveries executes the ClaimWebhookDeliveries query.
+func claimWebhookDeliveries(ctx context.Context, db dbtx, nextAttemptAt time.Time, limit int64) (_ []webhookDeliveriesRow, err error) {
+	rows, err := db.QueryContext(ctx, claimWebhookDeliveriesSQL, nextAttemptAt, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err = errs.Combine(err, rows.Close())
+	}()
+
+	var result []webhookDeliveriesRow
+	for rows.Next() {
+		var row webhookDeliveriesRow
+		if err = rows.Scan(&row.ID, &row.SubscriptionID, &row.EventID, &row.EventType, &row.Payload, &row.Status, &row.Attempts, &row.NextAttemptAt, &row.LastAttemptAt, &row.ResponseStatus, &row.LastError, &row.CreatedAt, &row.DeliveredAt); err != nil {
+			return nil, err
+		}
+		result = append(result, row)
+	}
+
+	return result, rows.Err()
+}
+
+const markWebhookDeliveryDeliveredSQL = `UPDATE webhook_deliveries
+SET status = 'delivered', delivered_at = $1, last_attempt_at = $1, response_status = $2, last_error = NULL
+WHERE id = $3`
+
+// markWebhookDeliveryDelivered executes the MarkWebhookDeliveryDelivered query.
+func markWebhookDeliveryDelivered(ctx context.Context, db dbtx, deliveredAt time.Time, responseStatus int32, id uuid.UUID) error {
+	_, err := db.ExecContext(ctx, markWebhookDeliveryDeliveredSQL, deliveredAt, responseStatus, id)
+	return err
+}
+
+const failWebhookDeliverySQL = `UPDATE webhook_deliveries
+SET status = $1, next_attempt_at = $2, last_attempt_at = $3, response_status = $4, last_error = $5
+WHERE id = $6`
+
+// failWebhookDelivery executes the FailWebhookDelivery query.
+func failWebhookDelivery(ctx context.Context, db dbtx, status string, nextAttemptAt time.Time, lastAttemptAt time.Time, responseStatus int32, lastError string, id uuid.UUID) error {
+	_, err := db.ExecContext(ctx, failWebhookDeliverySQL, status, nextAttemptAt, lastAttemptAt, responseStatus, lastError, id)
+	return err
+}
+
+const redeliverWebhookDeliverySQL = `UPDATE webhook_deliveries SET status = 'pending', attempts = 0, next_attempt_at = now()
+WHERE id = $1 AND subscription_id = $2`
+
+// redeliverWebhookDelivery executes the RedeliverWebhookDelivery query.
+func redeliverWebhookDelivery(ctx context.Context, db dbtx, id uuid.UUID, subscriptionID uuid.UUID) (int64, error) {
+	result, err := db.ExecContext(ctx, redeliverWebhookDeliverySQL, id, subscriptionID)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
@@ -0,0 +1,179 @@
+package dummy
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"project_template/events"
+)
+
+// MaxBatchSize is the maximum number of operations in a batch.
+const MaxBatchSize = 1000
+
+// Operation is a kind of batch operation.
+type Operation string
+
+const (
+	// OperationCreate creates a dummy, id is generated if it's not set.
+	OperationCreate Operation = "create"
+	// OperationUpdate updates title and status of an existing dummy.
+	OperationUpdate Operation = "update"
+	// OperationDelete deletes an existing dummy.
+	OperationDelete Operation = "delete"
+)
+
+// BatchMode defines how a batch is applied.
+type BatchMode string
+
+const (
+	// BatchAtomic applies all operations in one transaction, nothing is applied if any of them fails.
+	BatchAtomic BatchMode = "atomic"
+	// BatchBestEffort applies all valid operations and reports failures of the rest.
+	BatchBestEffort BatchMode = "best_effort"
+)
+
+// BatchItem is a single operation of a batch.
+type BatchItem struct {
+	Op     Operation `json:"op"`
+	ID     uuid.UUID `json:"id"`
+	Title  string    `json:"title"`
+	Status Status    `json:"status"`
+}
+
+// BatchItemResult is a result of a single operation of a batch, Error is empty if it was applied.
+type BatchItemResult struct {
+	Index int       `json:"index"`
+	Op    Operation `json:"op"`
+	ID    uuid.UUID `json:"id"`
+	Error string    `json:"error,omitempty"`
+}
+
+// BatchResult is a result of a batch.
+type BatchResult struct {
+	// Applied is false if an atomic batch was rolled back.
+	Applied bool              `json:"applied"`
+	Items   []BatchItemResult `json:"items"`
+}
+
+// Batch groups dummies by operation, so each group can be applied with one statement.
+type Batch struct {
+	Create []Dummy
+	Update []Dummy
+	Delete []uuid.UUID
+	// Events are events of the operations keyed by dummy id.
+	Events map[uuid.UUID]events.Event
+}
+
+// Batch validates operations and applies them with one statement per kind of operation.
+// Operations are applied grouped by kind, so an operation on an id which is already changed
+// by a previous operation of the batch is rejected, which makes the order of operations irrelevant.
+func (service *Service) Batch(ctx context.Context, items []BatchItem, mode BatchMode) (BatchResult, error) {
+	if mode != BatchAtomic && mode != BatchBestEffort {
+		return BatchResult{}, ErrInvalidDummy.New("unknown batch mode %q, expected atomic or best_effort", mode)
+	}
+	if len(items) > MaxBatchSize {
+		return BatchResult{}, ErrInvalidDummy.New("batch has %d operations, at most %d are allowed", len(items), MaxBatchSize)
+	}
+
+	result := BatchResult{Items: make([]BatchItemResult, len(items))}
+	indexes := make(map[uuid.UUID]int, len(items))
+	createdAt := time.Now()
+
+	var (
+		batch   = Batch{Events: make(map[uuid.UUID]events.Event, len(items))}
+		invalid int
+	)
+	for i, item := range items {
+		if item.Op == OperationCreate && item.ID == uuid.Nil {
+			item.ID = uuid.New()
+		}
+
+		result.Items[i] = BatchItemResult{Index: i, Op: item.Op, ID: item.ID}
+
+		err := validateBatchItem(item)
+		if err == nil {
+			if previous, ok := indexes[item.ID]; ok {
+				err = ErrInvalidDummy.New("dummy %s is already changed by operation %d", item.ID, previous)
+			}
+		}
+		if err != nil {
+			result.Items[i].Error = err.Error()
+			invalid++
+			continue
+		}
+		indexes[item.ID] = i
+
+		var payload events.Payload
+		switch item.Op {
+		case OperationCreate:
+			dummy := Dummy{ID: item.ID, Title: item.Title, Status: item.Status, CreatedAt: createdAt}
+			batch.Create = append(batch.Create, dummy)
+			payload = DummyCreated{Dummy: dummy}
+		case OperationUpdate:
+			batch.Update = append(batch.Update, Dummy{ID: item.ID, Title: item.Title, Status: item.Status})
+			payload = DummyUpdated{ID: item.ID, Title: item.Title, Status: item.Status}
+		case OperationDelete:
+			batch.Delete = append(batch.Delete, item.ID)
+			payload = DummyDeleted{ID: item.ID}
+		}
+
+		if batch.Events[item.ID], err = events.New(payload); err != nil {
+			return BatchResult{}, ErrDummy.Wrap(err)
+		}
+	}
+
+	atomic := mode == BatchAtomic
+	if atomic && invalid > 0 {
+		result.markNotApplied()
+		return result, nil
+	}
+
+	conflicts, err := service.dummy.Batch(ctx, batch, atomic)
+	if err != nil {
+		return BatchResult{}, ErrDummy.Wrap(err)
+	}
+
+	for _, id := range conflicts {
+		item := &result.Items[indexes[id]]
+		if item.Op == OperationCreate {
+			item.Error = ErrInvalidDummy.New("dummy %s already exists", id).Error()
+		} else {
+			item.Error = ErrNoDummy.New("dummy %s does not exist", id).Error()
+		}
+	}
+
+	if atomic && len(conflicts) > 0 {
+		result.markNotApplied()
+		return result, nil
+	}
+
+	result.Applied = true
+	return result, nil
+}
+
+// markNotApplied reports operations without errors as rolled back.
+func (result *BatchResult) markNotApplied() {
+	result.Applied = false
+	for i := range result.Items {
+		if result.Items[i].Error == "" {
+			result.Items[i].Error = "not applied, the batch was rolled back"
+		}
+	}
+}
+
+// validateBatchItem checks if the operation can be applied.
+func validateBatchItem(item BatchItem) error {
+	switch item.Op {
+	case OperationCreate, OperationUpdate:
+		return Dummy{ID: item.ID, Title: item.Title, Status: item.Status}.Validate()
+	case OperationDelete:
+		if item.ID == uuid.Nil {
+			return ErrInvalidDummy.New("id is required")
+		}
+		return nil
+	default:
+		return ErrInvalidDummy.New("unknown operation %q", item.Op)
+	}
+}
@@ -0,0 +1,125 @@
+package dummy_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"project_template"
+	"project_template/database/dbtesting"
+	"project_template/dummy"
+)
+
+func TestBatch(t *testing.T) {
+	dbtesting.Run(t, func(ctx context.Context, t *testing.T, db project_template.DB) {
+		testBatch(ctx, t, dummy.NewService(db.Dummy()))
+	})
+}
+
+func testBatch(ctx context.Context, t *testing.T, service *dummy.Service) {
+	existing := dummy.Dummy{ID: uuid.New(), Title: "existing", Status: dummy.StatusActive, CreatedAt: time.Now()}
+	removed := dummy.Dummy{ID: uuid.New(), Title: "removed", Status: dummy.StatusActive, CreatedAt: time.Now()}
+	missing := uuid.New()
+
+	items := []dummy.BatchItem{
+		{Op: dummy.OperationCreate, Title: "new", Status: dummy.StatusActive},
+		{Op: dummy.OperationUpdate, ID: existing.ID, Title: "updated", Status: dummy.StatusInactive},
+		{Op: dummy.OperationDelete, ID: removed.ID},
+		{Op: dummy.OperationUpdate, ID: missing, Title: "missing", Status: dummy.StatusActive},
+		{Op: dummy.OperationCreate, ID: existing.ID, Title: "conflict", Status: dummy.StatusActive},
+		{Op: dummy.OperationCreate, Title: "", Status: dummy.StatusActive},
+	}
+
+	reset := func(t *testing.T) {
+		resetDummies(ctx, t, service, existing, removed)
+	}
+
+	errorsOf := func(result dummy.BatchResult) []bool {
+		var failed []bool
+		for _, item := range result.Items {
+			failed = append(failed, item.Error != "")
+		}
+		return failed
+	}
+
+	t.Run("best effort", func(t *testing.T) {
+		reset(t)
+
+		result, err := service.Batch(ctx, items, dummy.BatchBestEffort)
+		require.NoError(t, err)
+		require.True(t, result.Applied)
+		require.Equal(t, []bool{false, false, false, true, true, true}, errorsOf(result))
+
+		require.NotEqual(t, uuid.Nil, result.Items[0].ID)
+		_, err = service.Get(ctx, result.Items[0].ID)
+		require.NoError(t, err)
+
+		updated, err := service.Get(ctx, existing.ID)
+		require.NoError(t, err)
+		require.Equal(t, "updated", updated.Title)
+
+		_, err = service.Get(ctx, removed.ID)
+		require.True(t, dummy.ErrNoDummy.Has(err))
+	})
+
+	t.Run("atomic with invalid item", func(t *testing.T) {
+		reset(t)
+
+		result, err := service.Batch(ctx, items, dummy.BatchAtomic)
+		require.NoError(t, err)
+		require.False(t, result.Applied)
+		require.Equal(t, []bool{true, true, true, true, true, true}, errorsOf(result))
+		require.Equal(t, 2, countDummies(ctx, t, service))
+	})
+
+	t.Run("atomic with conflict", func(t *testing.T) {
+		reset(t)
+
+		result, err := service.Batch(ctx, items[:4], dummy.BatchAtomic)
+		require.NoError(t, err)
+		require.False(t, result.Applied)
+		require.Contains(t, result.Items[3].Error, "does not exist")
+		require.Equal(t, 2, countDummies(ctx, t, service))
+
+		result, err = service.Batch(ctx, items[:3], dummy.BatchAtomic)
+		require.NoError(t, err)
+		require.True(t, result.Applied)
+		require.Equal(t, []bool{false, false, false}, errorsOf(result))
+		require.Equal(t, 2, countDummies(ctx, t, service))
+	})
+
+	t.Run("missing delete", func(t *testing.T) {
+		reset(t)
+
+		result, err := service.Batch(ctx, []dummy.BatchItem{
+			{Op: dummy.OperationUpdate, ID: existing.ID, Title: "updated", Status: dummy.StatusInactive},
+			{Op: dummy.OperationDelete, ID: missing},
+		}, dummy.BatchAtomic)
+		require.NoError(t, err)
+		require.False(t, result.Applied)
+		require.Contains(t, result.Items[1].Error, "does not exist")
+
+		unchanged, err := service.Get(ctx, existing.ID)
+		require.NoError(t, err)
+		require.Equal(t, "existing", unchanged.Title)
+	})
+
+	t.Run("repeated id", func(t *testing.T) {
+		reset(t)
+
+		result, err := service.Batch(ctx, []dummy.BatchItem{
+			{Op: dummy.OperationUpdate, ID: existing.ID, Title: "first", Status: dummy.StatusActive},
+			{Op: dummy.OperationDelete, ID: existing.ID},
+		}, dummy.BatchBestEffort)
+		require.NoError(t, err)
+		require.Equal(t, []bool{false, true}, errorsOf(result))
+	})
+
+	t.Run("unknown mode", func(t *testing.T) {
+		_, err := service.Batch(ctx, items, "eventual")
+		require.True(t, dummy.ErrInvalidDummy.Has(err))
+	})
+}
@@ -0,0 +1,367 @@
+package dummy
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zeebo/errs"
+
+	"project_template/events"
+)
+
+// Format is a format of bulk import and export data.
+type Format string
+
+const (
+	// FormatCSV is comma separated values with a header line.
+	FormatCSV Format = "csv"
+	// FormatNDJSON is one json object per line.
+	FormatNDJSON Format = "ndjson"
+)
+
+// ParseFormat returns format by its name.
+func ParseFormat(name string) (Format, error) {
+	switch format := Format(strings.ToLower(name)); format {
+	case FormatCSV, FormatNDJSON:
+		return format, nil
+	default:
+		return "", ErrDummy.New("unknown format %q, expected csv or ndjson", name)
+	}
+}
+
+// ContentType returns mime type of the format.
+func (format Format) ContentType() string {
+	if format == FormatCSV {
+		return "text/csv"
+	}
+	return "application/x-ndjson"
+}
+
+const (
+	// importBatchSize is a number of rows copied to the database at once.
+	importBatchSize = 1000
+	// maxImportErrors limits the number of row errors kept in the import result.
+	maxImportErrors = 1000
+)
+
+// csvHeader is a header of exported csv data, the order of columns in imported data may differ.
+var csvHeader = []string{"id", "title", "status", "created_at"}
+
+// csvRequired are columns imported csv data must have, id and created_at are generated if missing.
+var csvRequired = []string{"title", "status"}
+
+// RowError describes an imported row which was skipped.
+type RowError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// ImportResult describes the result of the import.
+type ImportResult struct {
+	Imported int `json:"imported"`
+	Failed   int `json:"failed"`
+	// Errors contains errors of the first failed rows.
+	Errors []RowError `json:"errors"`
+}
+
+// fail records the error of the row.
+func (result *ImportResult) fail(line int, err error) {
+	result.Failed++
+	if len(result.Errors) < maxImportErrors {
+		result.Errors = append(result.Errors, RowError{Line: line, Error: err.Error()})
+	}
+}
+
+// Import reads dummies in the given format and stores them in batches, so the input is never loaded at once.
+// Invalid rows and rows with already existing ids are skipped and reported in the result.
+// Missing ids and creation times are generated.
+func (service *Service) Import(ctx context.Context, r io.Reader, format Format) (ImportResult, error) {
+	result := ImportResult{Errors: []RowError{}}
+
+	decoder, err := newDecoder(r, format)
+	if err != nil {
+		return result, err
+	}
+
+	batch := make([]Dummy, 0, importBatchSize)
+	lines := make(map[uuid.UUID]int, importBatchSize)
+	created := make(map[uuid.UUID]events.Event, importBatchSize)
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+
+		skipped, err := service.dummy.Import(ctx, batch, created)
+		if err != nil {
+			return ErrDummy.Wrap(err)
+		}
+
+		for _, id := range skipped {
+			result.fail(lines[id], ErrInvalidDummy.New("dummy %s already exists", id))
+		}
+		result.Imported += len(batch) - len(skipped)
+
+		batch = batch[:0]
+		lines = make(map[uuid.UUID]int, importBatchSize)
+		created = make(map[uuid.UUID]events.Event, importBatchSize)
+		return nil
+	}
+
+	for {
+		dummy, line, err := decoder.decode()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err == nil {
+			err = prepareImported(&dummy)
+		}
+		if ErrInvalidDummy.Has(err) {
+			result.fail(line, err)
+			continue
+		}
+		if err != nil {
+			return result, ErrDummy.Wrap(err)
+		}
+
+		if _, ok := lines[dummy.ID]; ok {
+			result.fail(line, ErrInvalidDummy.New("dummy %s is repeated in the input", dummy.ID))
+			continue
+		}
+
+		event, err := events.New(DummyCreated{Dummy: dummy})
+		if err != nil {
+			return result, ErrDummy.Wrap(err)
+		}
+
+		batch = append(batch, dummy)
+		lines[dummy.ID] = line
+		created[dummy.ID] = event
+
+		if len(batch) == importBatchSize {
+			if err = flush(); err != nil {
+				return result, err
+			}
+		}
+	}
+
+	return result, flush()
+}
+
+// prepareImported fills missing fields of the imported dummy and validates it.
+func prepareImported(dummy *Dummy) error {
+	if dummy.ID == uuid.Nil {
+		dummy.ID = uuid.New()
+	}
+	if dummy.CreatedAt.IsZero() {
+		dummy.CreatedAt = time.Now()
+	}
+
+	return dummy.Validate()
+}
+
+// Export writes all dummies in the given format, rows are streamed from the database as they are written.
+func (service *Service) Export(ctx context.Context, w io.Writer, format Format) error {
+	encoder, err := newEncoder(w, format)
+	if err != nil {
+		return err
+	}
+
+	err = service.dummy.Export(ctx, encoder.encode)
+	return ErrDummy.Wrap(errs.Combine(err, encoder.flush()))
+}
+
+// decoder reads dummies one by one.
+type decoder interface {
+	// decode returns the next dummy and its line number, io.EOF at the end of the input.
+	// Malformed rows are reported with ErrInvalidDummy and can be skipped.
+	decode() (Dummy, int, error)
+}
+
+// newDecoder returns decoder of the format.
+func newDecoder(r io.Reader, format Format) (decoder, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVDecoder(r)
+	case FormatNDJSON:
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		return &ndjsonDecoder{scanner: scanner}, nil
+	default:
+		return nil, ErrDummy.New("unknown format %q", format)
+	}
+}
+
+// csvDecoder reads dummies from csv with a header line.
+type csvDecoder struct {
+	reader  *csv.Reader
+	columns map[string]int
+}
+
+// newCSVDecoder reads the header and checks that it has all required columns.
+func newCSVDecoder(r io.Reader) (*csvDecoder, error) {
+	reader := csv.NewReader(r)
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, ErrDummy.New("csv header is missing")
+		}
+		return nil, ErrDummy.Wrap(err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.TrimSpace(strings.ToLower(name))] = i
+	}
+
+	for _, name := range csvRequired {
+		if _, ok := columns[name]; !ok {
+			return nil, ErrDummy.New("csv header has no %q column", name)
+		}
+	}
+
+	return &csvDecoder{reader: reader, columns: columns}, nil
+}
+
+func (decoder *csvDecoder) decode() (Dummy, int, error) {
+	record, err := decoder.reader.Read()
+
+	// the reader continues from the next line after a parse error, so such rows can be skipped.
+	var parseErr *csv.ParseError
+	switch {
+	case errors.As(err, &parseErr):
+		return Dummy{}, parseErr.StartLine, ErrInvalidDummy.Wrap(err)
+	case err != nil:
+		return Dummy{}, 0, err
+	}
+
+	line, _ := decoder.reader.FieldPos(0)
+
+	field := func(name string) string {
+		if i, ok := decoder.columns[name]; ok {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var dummy Dummy
+	dummy.Title = field("title")
+
+	if id := field("id"); id != "" {
+		if dummy.ID, err = uuid.Parse(id); err != nil {
+			return Dummy{}, line, ErrInvalidDummy.New("invalid id: %v", err)
+		}
+	}
+
+	status, err := strconv.Atoi(field("status"))
+	if err != nil {
+		return Dummy{}, line, ErrInvalidDummy.New("invalid status: %v", err)
+	}
+	dummy.Status = Status(status)
+
+	if createdAt := field("created_at"); createdAt != "" {
+		if dummy.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAt); err != nil {
+			return Dummy{}, line, ErrInvalidDummy.New("invalid created_at: %v", err)
+		}
+	}
+
+	return dummy, line, nil
+}
+
+// ndjsonDecoder reads dummies from json objects separated by new lines, empty lines are ignored.
+type ndjsonDecoder struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func (decoder *ndjsonDecoder) decode() (Dummy, int, error) {
+	for decoder.scanner.Scan() {
+		decoder.line++
+
+		content := bytes.TrimSpace(decoder.scanner.Bytes())
+		if len(content) == 0 {
+			continue
+		}
+
+		var dummy Dummy
+		jsonDecoder := json.NewDecoder(bytes.NewReader(content))
+		jsonDecoder.DisallowUnknownFields()
+		if err := jsonDecoder.Decode(&dummy); err != nil {
+			return Dummy{}, decoder.line, ErrInvalidDummy.Wrap(err)
+		}
+
+		return dummy, decoder.line, nil
+	}
+
+	if err := decoder.scanner.Err(); err != nil {
+		return Dummy{}, decoder.line + 1, err
+	}
+
+	return Dummy{}, decoder.line, io.EOF
+}
+
+// encoder writes dummies one by one.
+type encoder interface {
+	encode(dummy Dummy) error
+	// flush writes buffered data.
+	flush() error
+}
+
+// newEncoder returns encoder of the format, csv header is written at once.
+func newEncoder(w io.Writer, format Format) (encoder, error) {
+	switch format {
+	case FormatCSV:
+		writer := csv.NewWriter(w)
+		if err := writer.Write(csvHeader); err != nil {
+			return nil, ErrDummy.Wrap(err)
+		}
+		return &csvEncoder{writer: writer}, nil
+	case FormatNDJSON:
+		return &ndjsonEncoder{encoder: json.NewEncoder(w)}, nil
+	default:
+		return nil, ErrDummy.New("unknown format %q", format)
+	}
+}
+
+// csvEncoder writes dummies as csv rows.
+type csvEncoder struct {
+	writer *csv.Writer
+}
+
+func (encoder *csvEncoder) encode(dummy Dummy) error {
+	return encoder.writer.Write([]string{
+		dummy.ID.String(),
+		dummy.Title,
+		strconv.Itoa(int(dummy.Status)),
+		dummy.CreatedAt.UTC().Format(time.RFC3339Nano),
+	})
+}
+
+func (encoder *csvEncoder) flush() error {
+	encoder.writer.Flush()
+	return encoder.writer.Error()
+}
+
+// ndjsonEncoder writes dummies as json objects separated by new lines.
+type ndjsonEncoder struct {
+	encoder *json.Encoder
+}
+
+func (encoder *ndjsonEncoder) encode(dummy Dummy) error {
+	return encoder.encoder.Encode(dummy)
+}
+
+func (encoder *ndjsonEncoder) flush() error {
+	return nil
+}
@@ -0,0 +1,87 @@
+package dummy_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"project_template"
+	"project_template/database/dbtesting"
+	"project_template/dummy"
+)
+
+func TestImportExport(t *testing.T) {
+	dbtesting.Run(t, func(ctx context.Context, t *testing.T, db project_template.DB) {
+		testImportExport(ctx, t, dummy.NewService(db.Dummy()))
+	})
+}
+
+func testImportExport(ctx context.Context, t *testing.T, service *dummy.Service) {
+	existing := dummy.Dummy{
+		ID:        uuid.MustParse("7c9e6679-7425-40de-944b-e07fc1f90ae7"),
+		Title:     "existing",
+		Status:    dummy.StatusActive,
+		CreatedAt: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	t.Run("csv", func(t *testing.T) {
+		resetDummies(ctx, t, service, existing)
+
+		input := strings.Join([]string{
+			"title,status,id,created_at",
+			"first,1,a1d4c3b2-5e6f-4a7b-8c9d-0e1f2a3b4c5d,2022-01-02T00:00:00Z",
+			"second,0,,",
+			",1,,",
+			"bad status,x,,",
+			"duplicate,1,7c9e6679-7425-40de-944b-e07fc1f90ae7,",
+			"too,many,fields,here,!",
+		}, "\n")
+
+		result, err := service.Import(ctx, strings.NewReader(input), dummy.FormatCSV)
+		require.NoError(t, err)
+		require.Equal(t, 2, result.Imported)
+		require.Equal(t, 4, result.Failed)
+
+		var lines []int
+		for _, rowErr := range result.Errors {
+			lines = append(lines, rowErr.Line)
+		}
+		require.Equal(t, []int{4, 5, 7, 6}, lines)
+		require.Equal(t, 3, countDummies(ctx, t, service))
+
+		var output bytes.Buffer
+		require.NoError(t, service.Export(ctx, &output, dummy.FormatCSV))
+
+		exported := strings.Split(strings.TrimSpace(output.String()), "\n")
+		require.Len(t, exported, 4)
+		require.Equal(t, "id,title,status,created_at", exported[0])
+		require.Equal(t, "7c9e6679-7425-40de-944b-e07fc1f90ae7,existing,1,2022-01-01T00:00:00Z", exported[1])
+		require.Equal(t, "a1d4c3b2-5e6f-4a7b-8c9d-0e1f2a3b4c5d,first,1,2022-01-02T00:00:00Z", exported[2])
+	})
+
+	t.Run("ndjson round trip", func(t *testing.T) {
+		resetDummies(ctx, t, service, existing)
+
+		var output bytes.Buffer
+		require.NoError(t, service.Export(ctx, &output, dummy.FormatNDJSON))
+
+		output.WriteString("\n{\"title\": \"unknown field\", \"status\": 1, \"extra\": true}\n")
+
+		resetDummies(ctx, t, service)
+		result, err := service.Import(ctx, &output, dummy.FormatNDJSON)
+		require.NoError(t, err)
+		require.Equal(t, 1, result.Imported)
+		require.Equal(t, 1, result.Failed)
+		require.Equal(t, 3, result.Errors[0].Line)
+
+		imported, err := service.Get(ctx, existing.ID)
+		require.NoError(t, err)
+		require.Equal(t, existing.Title, imported.Title)
+		require.True(t, existing.CreatedAt.Equal(imported.CreatedAt))
+	})
+}
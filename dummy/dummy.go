@@ -2,10 +2,13 @@ package dummy
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/zeebo/errs"
+
+	"project_template/events"
 )
 
 // ErrNoDummy indicated that user does not exist.
@@ -15,17 +18,50 @@ type DB interface {
 	// List returns all dummies from the database.
 	List(ctx context.Context) ([]Dummy, error)
 
+	// ListPage returns a page of dummies ordered by creation time and id.
+	ListPage(ctx context.Context, opts ListOptions) ([]Dummy, error)
+
 	// Get returns dummy by id from the database.
 	Get(ctx context.Context, id uuid.UUID) (Dummy, error)
 
-	// Create creates a dummy and writes to the database.
-	Create(ctx context.Context, dummy Dummy) error
+	// Create creates a dummy and writes to the database, the event is stored in the outbox in the same transaction.
+	Create(ctx context.Context, dummy Dummy, event events.Event) error
+
+	// Update updates a dummy in the database, the event is stored in the outbox in the same transaction.
+	Update(ctx context.Context, id uuid.UUID, title string, status Status, event events.Event) error
+
+	// Delete deletes a dummy in the database, the event is stored in the outbox in the same transaction
+	// only if the dummy existed.
+	Delete(ctx context.Context, id uuid.UUID, event events.Event) error
+
+	// Import stores a batch of dummies, those with already existing ids are skipped and returned.
+	// Events of created dummies, keyed by dummy id, are stored in the outbox in the same transaction.
+	Import(ctx context.Context, dummies []Dummy, created map[uuid.UUID]events.Event) (skipped []uuid.UUID, err error)
+
+	// Export calls fn for every dummy ordered by creation time, rows are streamed from the database.
+	Export(ctx context.Context, fn func(dummy Dummy) error) error
+
+	// Batch applies creates, updates and deletes of the batch in one transaction with one statement per kind.
+	// It returns ids of created dummies which already exist and updated or deleted dummies which don't exist,
+	// if there are any and atomic is set, the transaction is rolled back. Events of applied operations
+	// are stored in the outbox in the same transaction.
+	Batch(ctx context.Context, batch Batch, atomic bool) (conflicts []uuid.UUID, err error)
+
+	// Search returns dummies which titles match the query, ordered by rank.
+	Search(ctx context.Context, query string, opts SearchOptions) ([]SearchResult, error)
 
-	// Update updates a dummy in the database.
-	Update(ctx context.Context, id uuid.UUID, title string, status Status) error
+	// GetChange returns a recorded change of a dummy by id.
+	GetChange(ctx context.Context, id int64) (Change, error)
 
-	// Delete deletes a dummy in the database.
-	Delete(ctx context.Context, id uuid.UUID) error
+	// Changes returns up to limit recorded changes with ids greater than after, ordered by id.
+	Changes(ctx context.Context, after int64, limit int) ([]Change, error)
+
+	// DeleteChanges deletes changes recorded before the given time and returns their number.
+	DeleteChanges(ctx context.Context, before time.Time) (int64, error)
+
+	// ListenChanges calls notify with ids of committed changes until the context is canceled. notify is called
+	// with 0 after the connection to the database was re-established, since announcements could be missed.
+	ListenChanges(ctx context.Context, notify func(id int64)) error
 }
 
 // Status defines the list of possible dummy statuses.
@@ -38,9 +74,30 @@ const (
 	StatusInactive = 0
 )
 
+// IsValid checks if status is one of the known statuses.
+func (status Status) IsValid() bool {
+	return status == StatusActive || status == StatusInactive
+}
+
+// Dummy is a dummy entity.
 type Dummy struct {
 	ID        uuid.UUID `json:"id"`
 	Title     string    `json:"title"`
 	Status    Status    `json:"status"`
 	CreatedAt time.Time `json:"createdAt"`
 }
+
+// Validate checks if dummy data is valid.
+func (dummy Dummy) Validate() error {
+	if dummy.ID == uuid.Nil {
+		return ErrInvalidDummy.New("id is required")
+	}
+	if strings.TrimSpace(dummy.Title) == "" {
+		return ErrInvalidDummy.New("title is required")
+	}
+	if !dummy.Status.IsValid() {
+		return ErrInvalidDummy.New("unknown status %d", dummy.Status)
+	}
+
+	return nil
+}
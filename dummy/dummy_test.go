@@ -5,6 +5,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 	"project_template/dummy"
+	"project_template/events"
 	"testing"
 	"time"
 
@@ -38,12 +39,18 @@ func TestDummy(t *testing.T) {
 		})
 
 		t.Run("create", func(t *testing.T) {
-			err := dummyRepo.Create(ctx, dummy1)
+			event, err := events.New(dummy.DummyCreated{Dummy: dummy1})
+			require.NoError(t, err)
+
+			err = dummyRepo.Create(ctx, dummy1, event)
 			require.NoError(t, err)
 		})
 
 		t.Run("update", func(t *testing.T) {
-			err := dummyRepo.Update(ctx, updDummy1.ID, updDummy1.Title, updDummy1.Status)
+			event, err := events.New(dummy.DummyUpdated{ID: updDummy1.ID, Title: updDummy1.Title, Status: updDummy1.Status})
+			require.NoError(t, err)
+
+			err = dummyRepo.Update(ctx, updDummy1.ID, updDummy1.Title, updDummy1.Status, event)
 			require.NoError(t, err)
 		})
 
@@ -54,5 +61,102 @@ func TestDummy(t *testing.T) {
 			require.Equal(t, res.Title, updDummy1.Title)
 			require.Equal(t, res.Status, updDummy1.Status)
 		})
+
+		t.Run("list page", func(t *testing.T) {
+			page, err := dummyRepo.ListPage(ctx, dummy.ListOptions{Limit: 10})
+			require.NoError(t, err)
+			require.Len(t, page, 1)
+			require.Equal(t, updDummy1.ID, page[0].ID)
+
+			page, err = dummyRepo.ListPage(ctx, dummy.ListOptions{Limit: 10, Offset: 1})
+			require.NoError(t, err)
+			require.Empty(t, page)
+		})
+
+		t.Run("ids round trip", func(t *testing.T) {
+			ids := []uuid.UUID{
+				uuid.MustParse("00000000-0000-0000-0000-000000000001"),
+				uuid.MustParse("ffffffff-ffff-ffff-ffff-ffffffffffff"),
+				uuid.New(),
+			}
+
+			err := dummyRepo.Create(ctx, dummy.Dummy{ID: ids[0], Title: "created", Status: dummy.StatusActive, CreatedAt: time.Now()}, events.Event{ID: uuid.New(), Type: dummy.EventCreated, Payload: []byte(`{}`), CreatedAt: time.Now()})
+			require.NoError(t, err)
+
+			skipped, err := dummyRepo.Import(ctx, []dummy.Dummy{{ID: ids[1], Title: "imported", Status: dummy.StatusActive, CreatedAt: time.Now()}}, nil)
+			require.NoError(t, err)
+			require.Empty(t, skipped)
+
+			conflicts, err := dummyRepo.Batch(ctx, dummy.Batch{
+				Create: []dummy.Dummy{{ID: ids[2], Title: "batched", Status: dummy.StatusActive, CreatedAt: time.Now()}},
+				Update: []dummy.Dummy{{ID: ids[0], Title: "created-upd", Status: dummy.StatusInactive}},
+			}, true)
+			require.NoError(t, err)
+			require.Empty(t, conflicts)
+
+			missing := uuid.New()
+			conflicts, err = dummyRepo.Batch(ctx, dummy.Batch{Delete: []uuid.UUID{ids[2], missing}}, true)
+			require.NoError(t, err)
+			require.Equal(t, []uuid.UUID{missing}, conflicts)
+
+			for _, id := range ids {
+				res, err := dummyRepo.Get(ctx, id)
+				require.NoError(t, err)
+				require.Equal(t, id, res.ID)
+			}
+
+			list, err := dummyRepo.List(ctx)
+			require.NoError(t, err)
+
+			listed := make(map[uuid.UUID]bool)
+			for _, d := range list {
+				listed[d.ID] = true
+			}
+			for _, id := range ids {
+				require.True(t, listed[id], id.String())
+			}
+
+			require.NoError(t, dummyRepo.Delete(ctx, ids[1], events.Event{ID: uuid.New(), Type: dummy.EventDeleted, Payload: []byte(`{}`), CreatedAt: time.Now()}))
+			_, err = dummyRepo.Get(ctx, ids[1])
+			require.True(t, dummy.ErrNoDummy.Has(err))
+		})
+
+		t.Run("search", func(t *testing.T) {
+			res, err := dummyRepo.Search(ctx, "123", dummy.SearchOptions{Limit: 10})
+			require.NoError(t, err)
+			require.NotEmpty(t, res)
+			require.Equal(t, updDummy1.ID, res[0].ID)
+			require.Contains(t, res[0].Highlight, "<mark>")
+
+			// "123-up" has no full-text match, it's found only by trigram similarity.
+			res, err = dummyRepo.Search(ctx, "123-up", dummy.SearchOptions{Limit: 10})
+			require.NoError(t, err)
+			require.NotEmpty(t, res)
+			require.Equal(t, updDummy1.ID, res[0].ID)
+		})
+
+		t.Run("changes", func(t *testing.T) {
+			changes, err := dummyRepo.Changes(ctx, 0, 1000)
+			require.NoError(t, err)
+			require.NotEmpty(t, changes)
+			require.Equal(t, dummy.ChangeCreated, changes[0].Op)
+			require.Equal(t, dummy1.ID, changes[0].Dummy.ID)
+			require.Equal(t, dummy.ChangeUpdated, changes[1].Op)
+			require.Equal(t, updDummy1.Title, changes[1].Dummy.Title)
+
+			last := changes[len(changes)-1]
+			require.Equal(t, dummy.ChangeDeleted, last.Op)
+
+			change, err := dummyRepo.GetChange(ctx, last.ID)
+			require.NoError(t, err)
+			require.Equal(t, last, change)
+
+			_, err = dummyRepo.GetChange(ctx, last.ID+1)
+			require.True(t, dummy.ErrNoChange.Has(err))
+
+			deleted, err := dummyRepo.DeleteChanges(ctx, time.Now().Add(time.Second))
+			require.NoError(t, err)
+			require.EqualValues(t, len(changes), deleted)
+		})
 	})
 }
@@ -0,0 +1,38 @@
+package dummy
+
+import (
+	"github.com/google/uuid"
+)
+
+// Event types of dummy changes.
+const (
+	EventCreated = "dummy.created"
+	EventUpdated = "dummy.updated"
+	EventDeleted = "dummy.deleted"
+)
+
+// DummyCreated is emitted when a dummy is created.
+type DummyCreated struct {
+	Dummy
+}
+
+// EventType implements events.Payload.
+func (DummyCreated) EventType() string { return EventCreated }
+
+// DummyUpdated is emitted when title or status of a dummy is changed.
+type DummyUpdated struct {
+	ID     uuid.UUID `json:"id"`
+	Title  string    `json:"title"`
+	Status Status    `json:"status"`
+}
+
+// EventType implements events.Payload.
+func (DummyUpdated) EventType() string { return EventUpdated }
+
+// DummyDeleted is emitted when a dummy is deleted.
+type DummyDeleted struct {
+	ID uuid.UUID `json:"id"`
+}
+
+// EventType implements events.Payload.
+func (DummyDeleted) EventType() string { return EventDeleted }
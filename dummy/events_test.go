@@ -0,0 +1,58 @@
+package dummy_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"project_template"
+	"project_template/database/dbtesting"
+	"project_template/dummy"
+)
+
+func TestEvents(t *testing.T) {
+	dbtesting.Run(t, func(ctx context.Context, t *testing.T, db project_template.DB) {
+		service := dummy.NewService(db.Dummy())
+
+		created, err := service.Create(ctx, "first", dummy.StatusActive)
+		require.NoError(t, err)
+		require.NoError(t, service.Update(ctx, created.ID, "first-upd", dummy.StatusInactive))
+		require.NoError(t, service.Delete(ctx, created.ID))
+		require.NoError(t, service.Delete(ctx, created.ID))
+
+		_, err = service.Create(ctx, " ", dummy.StatusActive)
+		require.True(t, dummy.ErrInvalidDummy.Has(err))
+
+		result, err := service.Import(ctx, strings.NewReader("title,status\nimported,1\n"), dummy.FormatCSV)
+		require.NoError(t, err)
+		require.Equal(t, 1, result.Imported)
+
+		outbox, err := db.Outbox().Claim(ctx, 100, time.Now().Add(time.Minute))
+		require.NoError(t, err)
+		require.Len(t, outbox, 4, "events are stored only for applied changes")
+		require.Equal(t, dummy.EventCreated, outbox[0].Type)
+		require.Equal(t, dummy.EventUpdated, outbox[1].Type)
+		require.Equal(t, dummy.EventDeleted, outbox[2].Type)
+		require.Equal(t, dummy.EventCreated, outbox[3].Type)
+
+		var updated dummy.DummyUpdated
+		require.NoError(t, json.Unmarshal(outbox[1].Payload, &updated))
+		require.Equal(t, dummy.DummyUpdated{ID: created.ID, Title: "first-upd", Status: dummy.StatusInactive}, updated)
+
+		batch, err := service.Batch(ctx, []dummy.BatchItem{
+			{Op: dummy.OperationCreate, Title: "batched", Status: dummy.StatusActive},
+			{Op: dummy.OperationUpdate, ID: created.ID, Title: "missing", Status: dummy.StatusActive},
+		}, dummy.BatchAtomic)
+		require.NoError(t, err)
+		require.False(t, batch.Applied)
+
+		// claimed events are leased, so only events stored after the first claim are returned.
+		outbox, err = db.Outbox().Claim(ctx, 100, time.Now().Add(time.Minute))
+		require.NoError(t, err)
+		require.Empty(t, outbox, "events of a rolled back batch are not stored")
+	})
+}
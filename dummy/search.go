@@ -0,0 +1,49 @@
+package dummy
+
+import (
+	"context"
+	"strings"
+)
+
+const (
+	// DefaultSearchLimit is the number of search results returned if the limit is not set.
+	DefaultSearchLimit = 20
+	// MaxSearchLimit is the maximum number of search results returned at once.
+	MaxSearchLimit = 100
+)
+
+// SearchOptions defines pagination of search results.
+type SearchOptions struct {
+	Limit  int
+	Offset int
+}
+
+// SearchResult is a dummy found by title.
+type SearchResult struct {
+	Dummy
+	// Rank is a sum of full-text rank and trigram similarity, results are ordered by it.
+	Rank float64 `json:"rank"`
+	// Highlight is the title with matched words wrapped into <mark> tags.
+	Highlight string `json:"highlight"`
+}
+
+// Search returns dummies which titles match the query by words or are similar to it, best matches first.
+// The query supports web search syntax, e.g. quoted phrases, "or" and "-" to exclude words.
+func (service *Service) Search(ctx context.Context, query string, opts SearchOptions) ([]SearchResult, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, ErrInvalidDummy.New("search query is required")
+	}
+
+	switch {
+	case opts.Limit < 0 || opts.Offset < 0:
+		return nil, ErrInvalidDummy.New("limit and offset should not be negative")
+	case opts.Limit == 0:
+		opts.Limit = DefaultSearchLimit
+	case opts.Limit > MaxSearchLimit:
+		opts.Limit = MaxSearchLimit
+	}
+
+	results, err := service.dummy.Search(ctx, query, opts)
+	return results, ErrDummy.Wrap(err)
+}
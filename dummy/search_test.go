@@ -0,0 +1,37 @@
+package dummy_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"project_template"
+	"project_template/database/dbtesting"
+	"project_template/dummy"
+)
+
+func TestSearch(t *testing.T) {
+	dbtesting.Run(t, func(ctx context.Context, t *testing.T, db project_template.DB) {
+		service := dummy.NewService(db.Dummy())
+		for i := 0; i < dummy.MaxSearchLimit+10; i++ {
+			_, err := service.Create(ctx, fmt.Sprintf("searchable %d", i), dummy.StatusActive)
+			require.NoError(t, err)
+		}
+
+		results, err := service.Search(ctx, "searchable", dummy.SearchOptions{})
+		require.NoError(t, err)
+		require.Len(t, results, dummy.DefaultSearchLimit)
+
+		results, err = service.Search(ctx, "searchable", dummy.SearchOptions{Limit: dummy.MaxSearchLimit * 2})
+		require.NoError(t, err)
+		require.Len(t, results, dummy.MaxSearchLimit)
+
+		_, err = service.Search(ctx, "  ", dummy.SearchOptions{})
+		require.True(t, dummy.ErrInvalidDummy.Has(err))
+
+		_, err = service.Search(ctx, "searchable", dummy.SearchOptions{Offset: -1})
+		require.True(t, dummy.ErrInvalidDummy.Has(err))
+	})
+}
@@ -6,11 +6,29 @@ import (
 	"time"
 
 	"github.com/zeebo/errs"
+
+	"project_template/events"
 )
 
 // ErrDummy indicates that there was an error in the service.
 var ErrDummy = errs.Class("dummy service error")
 
+// ErrInvalidDummy indicates that dummy data is not valid.
+var ErrInvalidDummy = errs.Class("invalid dummy")
+
+const (
+	// DefaultListLimit is the number of dummies in a page if the limit is not set.
+	DefaultListLimit = 100
+	// MaxListLimit is the maximum number of dummies in a page.
+	MaxListLimit = 1000
+)
+
+// ListOptions defines a page of dummies.
+type ListOptions struct {
+	Limit  int
+	Offset int
+}
+
 // Service is handling users related logic.
 //
 // architecture: Service.
@@ -37,6 +55,21 @@ func (service *Service) List(ctx context.Context) ([]Dummy, error) {
 	return users, ErrDummy.Wrap(err)
 }
 
+// ListPage returns a page of dummies ordered by creation time, the order is stable between pages.
+func (service *Service) ListPage(ctx context.Context, opts ListOptions) ([]Dummy, error) {
+	switch {
+	case opts.Limit < 0 || opts.Offset < 0:
+		return nil, ErrInvalidDummy.New("limit and offset should not be negative")
+	case opts.Limit == 0:
+		opts.Limit = DefaultListLimit
+	case opts.Limit > MaxListLimit:
+		opts.Limit = MaxListLimit
+	}
+
+	dummies, err := service.dummy.ListPage(ctx, opts)
+	return dummies, ErrDummy.Wrap(err)
+}
+
 // Create creates a new dummy item.
 func (service *Service) Create(ctx context.Context, title string, status Status) (Dummy, error) {
 	dummy := Dummy{
@@ -46,22 +79,76 @@ func (service *Service) Create(ctx context.Context, title string, status Status)
 		CreatedAt: time.Now(),
 	}
 
-	err := service.dummy.Create(ctx, dummy)
-	if err != nil {
-		return Dummy{}, ErrDummy.Wrap(err)
+	if err := dummy.Validate(); err != nil {
+		return Dummy{}, err
+	}
+
+	if err := service.create(ctx, dummy); err != nil {
+		return Dummy{}, err
 	}
 
 	return dummy, nil
 }
 
+// create stores a valid dummy and its DummyCreated event.
+func (service *Service) create(ctx context.Context, dummy Dummy) error {
+	event, err := events.New(DummyCreated{Dummy: dummy})
+	if err != nil {
+		return ErrDummy.Wrap(err)
+	}
+
+	return ErrDummy.Wrap(service.dummy.Create(ctx, dummy, event))
+}
+
 // Update updates a dummy item data.
 func (service *Service) Update(ctx context.Context, id uuid.UUID, title string, status Status) error {
-	err := service.dummy.Update(ctx, id, title, status)
-	return ErrDummy.Wrap(err)
+	if err := (Dummy{ID: id, Title: title, Status: status}).Validate(); err != nil {
+		return err
+	}
+
+	return service.update(ctx, id, title, status)
+}
+
+// update stores valid title and status and the DummyUpdated event.
+func (service *Service) update(ctx context.Context, id uuid.UUID, title string, status Status) error {
+	event, err := events.New(DummyUpdated{ID: id, Title: title, Status: status})
+	if err != nil {
+		return ErrDummy.Wrap(err)
+	}
+
+	return ErrDummy.Wrap(service.dummy.Update(ctx, id, title, status, event))
+}
+
+// Upsert creates a dummy item with the given id, or updates its title and status if it already exists.
+// It returns true if the item was created.
+func (service *Service) Upsert(ctx context.Context, dummy Dummy) (bool, error) {
+	if dummy.CreatedAt.IsZero() {
+		dummy.CreatedAt = time.Now()
+	}
+
+	if err := dummy.Validate(); err != nil {
+		return false, err
+	}
+
+	existing, err := service.dummy.Get(ctx, dummy.ID)
+	switch {
+	case ErrNoDummy.Has(err):
+		return true, service.create(ctx, dummy)
+	case err != nil:
+		return false, ErrDummy.Wrap(err)
+	case existing.Title == dummy.Title && existing.Status == dummy.Status:
+		return false, nil
+	default:
+		return false, service.update(ctx, dummy.ID, dummy.Title, dummy.Status)
+	}
 }
 
 // Delete deletes a dummy item.
 func (service *Service) Delete(ctx context.Context, id uuid.UUID) error {
-	err := service.dummy.Delete(ctx, id)
-	return ErrDummy.Wrap(err)
+	event, err := events.New(DummyDeleted{ID: id})
+	if err != nil {
+		return ErrDummy.Wrap(err)
+	}
+
+	return ErrDummy.Wrap(service.dummy.Delete(ctx, id, event))
 }
@@ -0,0 +1,75 @@
+package dummy_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"project_template"
+	"project_template/database/dbtesting"
+	"project_template/dummy"
+)
+
+func TestListPage(t *testing.T) {
+	dbtesting.Run(t, func(ctx context.Context, t *testing.T, db project_template.DB) {
+		service := dummy.NewService(db.Dummy())
+
+		createdAt := time.Now()
+		for i := 0; i < dummy.MaxListLimit+10; i++ {
+			_, err := service.Upsert(ctx, dummy.Dummy{
+				ID:        uuid.New(),
+				Title:     fmt.Sprintf("listed %d", i),
+				Status:    dummy.StatusActive,
+				CreatedAt: createdAt.Add(time.Duration(i) * time.Second),
+			})
+			require.NoError(t, err)
+		}
+
+		page, err := service.ListPage(ctx, dummy.ListOptions{})
+		require.NoError(t, err)
+		require.Len(t, page, dummy.DefaultListLimit)
+		require.Equal(t, "listed 0", page[0].Title)
+
+		page, err = service.ListPage(ctx, dummy.ListOptions{Limit: 2, Offset: 2})
+		require.NoError(t, err)
+		require.Len(t, page, 2)
+		require.Equal(t, "listed 2", page[0].Title)
+		require.Equal(t, "listed 3", page[1].Title)
+
+		page, err = service.ListPage(ctx, dummy.ListOptions{Limit: dummy.MaxListLimit * 2})
+		require.NoError(t, err)
+		require.Len(t, page, dummy.MaxListLimit)
+
+		page, err = service.ListPage(ctx, dummy.ListOptions{Limit: 10, Offset: dummy.MaxListLimit + 10})
+		require.NoError(t, err)
+		require.Empty(t, page)
+
+		_, err = service.ListPage(ctx, dummy.ListOptions{Offset: -1})
+		require.True(t, dummy.ErrInvalidDummy.Has(err))
+	})
+}
+
+// resetDummies deletes all dummies and creates the seeded ones, so subtests sharing the database start from the same state.
+func resetDummies(ctx context.Context, t *testing.T, service *dummy.Service, seeded ...dummy.Dummy) {
+	list, err := service.List(ctx)
+	require.NoError(t, err)
+	for _, d := range list {
+		require.NoError(t, service.Delete(ctx, d.ID))
+	}
+
+	for _, d := range seeded {
+		_, err := service.Upsert(ctx, d)
+		require.NoError(t, err)
+	}
+}
+
+// countDummies returns the number of stored dummies.
+func countDummies(ctx context.Context, t *testing.T, service *dummy.Service) int {
+	list, err := service.List(ctx)
+	require.NoError(t, err)
+	return len(list)
+}
@@ -0,0 +1,306 @@
+package dummy
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/zeebo/errs"
+
+	"project_template/pkg/logger"
+)
+
+var (
+	// ErrStream indicates that there was an error in the stream of changes.
+	ErrStream = errs.Class("dummy stream error")
+	// ErrStreamClosed indicates that the subscription was closed by the stream, because it's stopped
+	// or the subscriber did not keep up with changes. Subscribers should resume from the last change they saw.
+	ErrStreamClosed = errs.Class("dummy stream closed")
+	// ErrNoChange indicates that a change does not exist, e.g. it was already pruned.
+	ErrNoChange = errs.Class("dummy change does not exist")
+	// ErrChangesPruned indicates that a subscriber can't resume, because the change it saw last was pruned
+	// and changes after it could be pruned too. Subscribers should fetch dummies again and subscribe without resuming.
+	ErrChangesPruned = errs.Class("dummy changes were pruned")
+)
+
+// ChangeOp is a kind of change of a dummy.
+type ChangeOp string
+
+const (
+	// ChangeCreated indicates that a dummy was created.
+	ChangeCreated ChangeOp = "created"
+	// ChangeUpdated indicates that a dummy was updated.
+	ChangeUpdated ChangeOp = "updated"
+	// ChangeDeleted indicates that a dummy was deleted, the change contains its last state.
+	ChangeDeleted ChangeOp = "deleted"
+)
+
+// Change is a change of the dummy table recorded by the database, ids grow with every change.
+type Change struct {
+	ID        int64     `json:"id"`
+	Op        ChangeOp  `json:"op"`
+	Dummy     Dummy     `json:"dummy"`
+	ChangedAt time.Time `json:"changedAt"`
+}
+
+// EventType returns the type of the change, e.g. "dummy.created".
+func (change Change) EventType() string {
+	return "dummy." + string(change.Op)
+}
+
+// StreamConfig contains configuration of the stream of dummy changes.
+type StreamConfig struct {
+	Retention   time.Duration `env:"DUMMY_STREAM_RETENTION" envDefault:"24h" desc:"how long changes are kept for clients resuming the stream"`
+	ReplayLimit int           `env:"DUMMY_STREAM_REPLAY_LIMIT" validate:"min=1" envDefault:"1000" desc:"number of missed changes read at once for a resuming client"`
+	BufferSize  int           `env:"DUMMY_STREAM_BUFFER_SIZE" validate:"min=1" envDefault:"256" desc:"number of changes buffered per client, clients which fall behind are disconnected"`
+}
+
+// Stream broadcasts changes committed by any replica of the app to subscribers. Changes are recorded
+// and announced by the database, so every replica receives all of them. Change ids are assigned before
+// commit, so concurrent changes may be announced out of order, and a subscriber resuming after the
+// greatest id it saw may miss a change which was committed later with a smaller id.
+//
+// architecture: Worker
+type Stream struct {
+	log    logger.Logger
+	config StreamConfig
+	db     DB
+
+	// cursor is the greatest broadcast change id, it's used only by the listening goroutine.
+	cursor int64
+
+	mu          sync.Mutex
+	subscribers map[*Subscription]struct{}
+	closed      bool
+}
+
+// NewStream is a constructor for the stream of dummy changes.
+func NewStream(log logger.Logger, config StreamConfig, db DB) *Stream {
+	return &Stream{
+		log:         log,
+		config:      config,
+		db:          db,
+		subscribers: make(map[*Subscription]struct{}),
+	}
+}
+
+// Run listens for changes and broadcasts them until the context is canceled,
+// all subscriptions are closed when it returns.
+func (stream *Stream) Run(ctx context.Context) error {
+	defer stream.closeSubscriptions()
+
+	return ErrStream.Wrap(stream.db.ListenChanges(ctx, func(id int64) {
+		stream.notify(ctx, id)
+	}))
+}
+
+// notify broadcasts the announced change, or changes after the cursor if notifications could be missed.
+func (stream *Stream) notify(ctx context.Context, id int64) {
+	if id == 0 {
+		stream.catchUp(ctx)
+		return
+	}
+
+	change, err := stream.db.GetChange(ctx, id)
+	if err != nil {
+		// changes of other schemas sharing the database are announced on the same channel.
+		if !ErrNoChange.Has(err) && ctx.Err() == nil {
+			stream.log.Error("could not get dummy change", ErrStream.Wrap(err))
+		}
+		return
+	}
+
+	stream.broadcast(change)
+}
+
+// catchUp broadcasts changes after the cursor, it's called when the connection was re-established.
+func (stream *Stream) catchUp(ctx context.Context) {
+	// subscribers connected before the first change resume by themselves.
+	if stream.cursor == 0 {
+		return
+	}
+
+	for {
+		changes, err := stream.db.Changes(ctx, stream.cursor, stream.config.ReplayLimit)
+		if err != nil {
+			if ctx.Err() == nil {
+				stream.log.Error("could not get missed dummy changes", ErrStream.Wrap(err))
+			}
+			return
+		}
+
+		for _, change := range changes {
+			stream.broadcast(change)
+		}
+
+		if len(changes) < stream.config.ReplayLimit {
+			return
+		}
+	}
+}
+
+// broadcast sends the change to all subscribers, subscribers with a full buffer are closed.
+func (stream *Stream) broadcast(change Change) {
+	if change.ID > stream.cursor {
+		stream.cursor = change.ID
+	}
+
+	stream.mu.Lock()
+	defer stream.mu.Unlock()
+
+	for subscription := range stream.subscribers {
+		select {
+		case subscription.live <- change:
+		default:
+			stream.unsubscribe(subscription)
+		}
+	}
+}
+
+// Prune deletes changes older than the retention, it's run as a scheduled job.
+func (stream *Stream) Prune(ctx context.Context) error {
+	deleted, err := stream.db.DeleteChanges(ctx, time.Now().Add(-stream.config.Retention))
+	if err != nil {
+		return ErrStream.Wrap(err)
+	}
+
+	if deleted > 0 {
+		stream.log.Debug(fmt.Sprintf("deleted %d old dummy changes", deleted))
+	}
+	return nil
+}
+
+// Subscribe returns a subscription to changes. If after is not 0, changes with greater ids which were
+// already recorded are replayed first, they are read in pages of ReplayLimit until the subscription is
+// caught up. ErrChangesPruned is returned if the change after was pruned, since missed changes could be lost.
+func (stream *Stream) Subscribe(ctx context.Context, after int64) (*Subscription, error) {
+	subscription := &Subscription{
+		stream: stream,
+		live:   make(chan Change, stream.config.BufferSize),
+	}
+
+	// the subscription receives live changes before the replay is read, so no change
+	// is lost in between, and changes received twice are skipped by Next.
+	stream.mu.Lock()
+	if stream.closed {
+		stream.mu.Unlock()
+		return nil, ErrStreamClosed.New("stream is stopped")
+	}
+	stream.subscribers[subscription] = struct{}{}
+	stream.mu.Unlock()
+
+	if after > 0 {
+		if _, err := stream.db.GetChange(ctx, after); err != nil {
+			subscription.Close()
+			if ErrNoChange.Has(err) {
+				return nil, ErrChangesPruned.New("change %d is not kept", after)
+			}
+			return nil, ErrStream.Wrap(err)
+		}
+
+		subscription.cursor, subscription.replaying = after, true
+		subscription.replayed = make(map[int64]bool)
+		if err := subscription.readReplay(ctx); err != nil {
+			subscription.Close()
+			return nil, err
+		}
+	}
+
+	return subscription, nil
+}
+
+// unsubscribe removes the subscription and closes its channel, it must be called with the lock held.
+func (stream *Stream) unsubscribe(subscription *Subscription) {
+	if _, ok := stream.subscribers[subscription]; ok {
+		delete(stream.subscribers, subscription)
+		close(subscription.live)
+	}
+}
+
+// closeSubscriptions closes all subscriptions and rejects new ones.
+func (stream *Stream) closeSubscriptions() {
+	stream.mu.Lock()
+	defer stream.mu.Unlock()
+
+	stream.closed = true
+	for subscription := range stream.subscribers {
+		stream.unsubscribe(subscription)
+	}
+}
+
+// Close closes all subscriptions.
+func (stream *Stream) Close() error {
+	stream.closeSubscriptions()
+	return nil
+}
+
+// Subscription receives changes of the stream.
+type Subscription struct {
+	stream *Stream
+	live   chan Change
+
+	// replay is the page of recorded changes which is replayed, cursor is the id of its last change.
+	// replaying is false when the last page was read.
+	replay    []Change
+	cursor    int64
+	replaying bool
+	replayed  map[int64]bool
+}
+
+// Next returns the next change, replayed changes first. It returns ErrStreamClosed if the stream closed the subscription,
+// a subscription which fell behind during a long replay is closed after the replay, so no change is skipped.
+func (subscription *Subscription) Next(ctx context.Context) (Change, error) {
+	for len(subscription.replay) == 0 && subscription.replaying {
+		if err := subscription.readReplay(ctx); err != nil {
+			return Change{}, err
+		}
+	}
+
+	if len(subscription.replay) > 0 {
+		change := subscription.replay[0]
+		subscription.replay = subscription.replay[1:]
+		return change, nil
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return Change{}, ctx.Err()
+		case change, ok := <-subscription.live:
+			if !ok {
+				return Change{}, ErrStreamClosed.New("subscription is closed")
+			}
+			if subscription.replayed[change.ID] {
+				continue
+			}
+			return change, nil
+		}
+	}
+}
+
+// readReplay reads the next page of recorded changes after the cursor.
+func (subscription *Subscription) readReplay(ctx context.Context) error {
+	limit := subscription.stream.config.ReplayLimit
+
+	changes, err := subscription.stream.db.Changes(ctx, subscription.cursor, limit)
+	if err != nil {
+		return ErrStream.Wrap(err)
+	}
+
+	for _, change := range changes {
+		subscription.replayed[change.ID] = true
+		subscription.cursor = change.ID
+	}
+	subscription.replay = changes
+	subscription.replaying = len(changes) == limit
+	return nil
+}
+
+// Close unsubscribes from the stream.
+func (subscription *Subscription) Close() {
+	subscription.stream.mu.Lock()
+	defer subscription.stream.mu.Unlock()
+
+	subscription.stream.unsubscribe(subscription)
+}
@@ -0,0 +1,191 @@
+package dummy_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"project_template"
+	"project_template/database/dbtesting"
+	"project_template/dummy"
+	"project_template/pkg/logger/zaplog"
+)
+
+func TestStream(t *testing.T) {
+	dbtesting.Run(t, func(ctx context.Context, t *testing.T, db project_template.DB) {
+		testStream(ctx, t, &announcingDB{DB: db.Dummy(), notifications: make(chan int64, 100)})
+	})
+}
+
+func testStream(ctx context.Context, t *testing.T, db *announcingDB) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	service := dummy.NewService(db)
+	stream := dummy.NewStream(zaplog.NewLog(), dummy.StreamConfig{Retention: time.Hour, ReplayLimit: 10, BufferSize: 2}, db)
+
+	runCtx, stop := context.WithCancel(ctx)
+	stopped := make(chan error, 1)
+	go func() { stopped <- stream.Run(runCtx) }()
+
+	d := dummy.Dummy{ID: uuid.New(), Title: "streamed", Status: dummy.StatusActive, CreatedAt: time.Now()}
+
+	// last returns the change recorded by the database for the latest change of the dummy,
+	// it's announced to the stream only if notify is set.
+	var cursor int64
+	last := func(notify bool) dummy.Change {
+		changes, err := db.Changes(ctx, cursor, 2)
+		require.NoError(t, err)
+		require.Len(t, changes, 1)
+		cursor = changes[0].ID
+
+		if notify {
+			db.notifications <- cursor
+		}
+		return changes[0]
+	}
+
+	// record updates the title of the dummy, so the database records a change.
+	var updates int
+	record := func(notify bool) dummy.Change {
+		updates++
+		require.NoError(t, service.Update(ctx, d.ID, fmt.Sprintf("streamed %d", updates), d.Status))
+		return last(notify)
+	}
+
+	live, err := stream.Subscribe(ctx, 0)
+	require.NoError(t, err)
+
+	_, err = service.Upsert(ctx, d)
+	require.NoError(t, err)
+	created := last(true)
+	change, err := live.Next(ctx)
+	require.NoError(t, err)
+	require.Equal(t, created.ID, change.ID)
+	require.Equal(t, "dummy.created", change.EventType())
+	require.Equal(t, d.ID, change.Dummy.ID)
+
+	t.Run("resume", func(t *testing.T) {
+		// changes recorded while the client was disconnected.
+		missed := record(false)
+
+		resumed, err := stream.Subscribe(ctx, created.ID)
+		require.NoError(t, err)
+		defer resumed.Close()
+
+		recorded := record(true)
+
+		var ids []int64
+		for len(ids) < 2 {
+			change, err := resumed.Next(ctx)
+			require.NoError(t, err)
+			ids = append(ids, change.ID)
+		}
+		require.Equal(t, []int64{missed.ID, recorded.ID}, ids)
+
+		// the live subscriber keeps up, so its buffer is empty for the next test.
+		for change, err := live.Next(ctx); change.ID != recorded.ID; change, err = live.Next(ctx) {
+			require.NoError(t, err)
+		}
+	})
+
+	t.Run("resume more than replay limit behind", func(t *testing.T) {
+		seen := record(false)
+		var missed []int64
+		for i := 0; i < 25; i++ {
+			missed = append(missed, record(false).ID)
+		}
+
+		resumed, err := stream.Subscribe(ctx, seen.ID)
+		require.NoError(t, err)
+		defer resumed.Close()
+
+		recorded := record(true)
+
+		var ids []int64
+		for len(ids) < len(missed)+1 {
+			change, err := resumed.Next(ctx)
+			require.NoError(t, err)
+			ids = append(ids, change.ID)
+		}
+		require.Equal(t, append(missed, recorded.ID), ids, "all missed changes are replayed before live ones")
+
+		// the live subscriber keeps up, so its buffer is empty for the next test.
+		for change, err := live.Next(ctx); change.ID != recorded.ID; change, err = live.Next(ctx) {
+			require.NoError(t, err)
+		}
+	})
+
+	t.Run("resume after pruned change", func(t *testing.T) {
+		pruned := record(false)
+		kept := record(false)
+
+		_, err := db.DeleteChanges(ctx, kept.ChangedAt)
+		require.NoError(t, err)
+
+		_, err = stream.Subscribe(ctx, pruned.ID)
+		require.True(t, dummy.ErrChangesPruned.Has(err), "changes after a pruned one could be lost")
+
+		resumed, err := stream.Subscribe(ctx, kept.ID)
+		require.NoError(t, err)
+		resumed.Close()
+	})
+
+	t.Run("slow subscriber is closed", func(t *testing.T) {
+		slow, err := stream.Subscribe(ctx, 0)
+		require.NoError(t, err)
+
+		var last dummy.Change
+		for i := 0; i < 3; i++ {
+			last = record(true)
+		}
+		// the live subscriber keeps up, so all changes are broadcast when it receives the last one.
+		for {
+			change, err := live.Next(ctx)
+			require.NoError(t, err)
+			if change.ID == last.ID {
+				break
+			}
+		}
+
+		for {
+			_, err = slow.Next(ctx)
+			if err != nil {
+				break
+			}
+		}
+		require.True(t, dummy.ErrStreamClosed.Has(err))
+	})
+
+	stop()
+	require.ErrorIs(t, <-stopped, context.Canceled)
+
+	_, err = live.Next(ctx)
+	require.True(t, dummy.ErrStreamClosed.Has(err), "subscriptions are closed when the stream stops")
+
+	_, err = stream.Subscribe(ctx, 0)
+	require.True(t, dummy.ErrStreamClosed.Has(err))
+}
+
+// announcingDB is the dummy repository whose changes are announced by tests instead of the database,
+// so tests decide which changes subscribers miss.
+type announcingDB struct {
+	dummy.DB
+
+	notifications chan int64
+}
+
+func (db *announcingDB) ListenChanges(ctx context.Context, notify func(id int64)) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case id := <-db.notifications:
+			notify(id)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zeebo/errs"
+)
+
+// ErrEvents indicates that there was an error in the events relay.
+var ErrEvents = errs.Class("events error")
+
+// Payload is a typed domain event.
+type Payload interface {
+	// EventType returns type of the event, e.g. "dummy.created".
+	EventType() string
+}
+
+// Event is a domain event stored in the outbox in the same transaction as the change it describes.
+type Event struct {
+	ID        uuid.UUID       `json:"id"`
+	Type      string          `json:"type"`
+	Payload   json.RawMessage `json:"payload"`
+	CreatedAt time.Time       `json:"createdAt"`
+	// Attempts is the number of publishing attempts, including the current one.
+	Attempts int `json:"-"`
+}
+
+// New creates an event with the payload encoded as json.
+func New(payload Payload) (Event, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return Event{}, ErrEvents.Wrap(err)
+	}
+
+	return Event{
+		ID:        uuid.New(),
+		Type:      payload.EventType(),
+		Payload:   data,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// DB exposes access to the outbox, events are inserted by repositories of the changed entities.
+//
+// architecture: Database
+type DB interface {
+	// Claim returns up to limit unpublished events which are due, sorted by creation time and id, and hides
+	// them from other claims until leaseUntil, so events of a crashed relay are published again when the lease expires.
+	Claim(ctx context.Context, limit int, leaseUntil time.Time) ([]Event, error)
+
+	// MarkPublished marks the event as published.
+	MarkPublished(ctx context.Context, id uuid.UUID, publishedAt time.Time) error
+
+	// Retry schedules the next publishing attempt of the event and records the error of the failed one.
+	Retry(ctx context.Context, id uuid.UUID, nextAttemptAt time.Time, lastError string) error
+
+	// DeletePublished deletes events published before the given time and returns their number.
+	DeletePublished(ctx context.Context, before time.Time) (int64, error)
+}
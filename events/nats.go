@@ -0,0 +1,109 @@
+package events
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// natsPublisher publishes events with the NATS client. Every publish is followed by a flush, which
+// waits for the server to respond to PING, and the event is considered accepted if the server
+// hasn't reported an error for it, since the server handles messages of a connection in order.
+type natsPublisher struct {
+	address string
+	subject string
+	timeout time.Duration
+	options []nats.Option
+
+	mu   sync.Mutex
+	conn *nats.Conn
+}
+
+// NATSOptions returns client options for authentication and TLS configured in the config.
+// The connection fails with a clear error if the server requires authentication or TLS
+// which isn't configured.
+func NATSOptions(config Config) []nats.Option {
+	options := []nats.Option{nats.Name("template_project"), nats.Timeout(config.Timeout)}
+
+	switch {
+	case config.NATSCredentials != "":
+		options = append(options, nats.UserCredentials(config.NATSCredentials))
+	case config.NATSToken != "":
+		options = append(options, nats.Token(config.NATSToken))
+	case config.NATSUser != "":
+		options = append(options, nats.UserInfo(config.NATSUser, config.NATSPassword))
+	}
+
+	if config.NATSTLS {
+		options = append(options, nats.Secure())
+	}
+	if config.NATSCAFile != "" {
+		options = append(options, nats.RootCAs(config.NATSCAFile))
+	}
+	if config.NATSCertFile != "" {
+		options = append(options, nats.ClientCert(config.NATSCertFile, config.NATSKeyFile))
+	}
+
+	return options
+}
+
+// NewNATSPublisher creates a publisher which publishes events to "<subject>.<event type>" subjects.
+// The connection is established on the first publish and re-established after it's closed.
+func NewNATSPublisher(address, subject string, timeout time.Duration, options ...nats.Option) Publisher {
+	// asynchronous errors are returned by Publish, so the default handler printing them is replaced.
+	options = append([]nats.Option{nats.ErrorHandler(func(*nats.Conn, *nats.Subscription, error) {})}, options...)
+	return &natsPublisher{address: address, subject: subject, timeout: timeout, options: options}
+}
+
+func (publisher *natsPublisher) Publish(ctx context.Context, event Event) error {
+	data, err := marshalEvent(event)
+	if err != nil {
+		return err
+	}
+
+	publisher.mu.Lock()
+	defer publisher.mu.Unlock()
+
+	if publisher.conn == nil || publisher.conn.IsClosed() {
+		conn, err := nats.Connect(publisher.address, publisher.options...)
+		if err != nil {
+			return ErrEvents.Wrap(err)
+		}
+		publisher.conn = conn
+	}
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, publisher.timeout)
+		defer cancel()
+	}
+
+	// errors of published messages, e.g. permission violations, are reported asynchronously
+	// and are never reset, so a new error is the one reported before the PONG.
+	lastErr := publisher.conn.LastError()
+
+	if err = publisher.conn.Publish(publisher.subject+"."+event.Type, data); err != nil {
+		return ErrEvents.Wrap(err)
+	}
+	if err = publisher.conn.FlushWithContext(ctx); err != nil {
+		return ErrEvents.Wrap(err)
+	}
+	if err = publisher.conn.LastError(); err != nil && err != lastErr {
+		return ErrEvents.Wrap(err)
+	}
+
+	return nil
+}
+
+func (publisher *natsPublisher) Close() error {
+	publisher.mu.Lock()
+	defer publisher.mu.Unlock()
+
+	if publisher.conn != nil {
+		publisher.conn.Close()
+		publisher.conn = nil
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package events_test
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"project_template"
+	"project_template/database/dbtesting"
+	"project_template/dummy"
+	"project_template/events"
+)
+
+func TestOutbox(t *testing.T) {
+	dbtesting.Run(t, func(ctx context.Context, t *testing.T, db project_template.DB) {
+		outbox := db.Outbox()
+
+		// events are stored in the outbox by repositories of changed entities.
+		for i := 0; i < 3; i++ {
+			d := dummy.Dummy{ID: uuid.New(), Title: "outbox", Status: dummy.StatusActive, CreatedAt: time.Now()}
+			event, err := events.New(dummy.DummyCreated{Dummy: d})
+			require.NoError(t, err)
+			require.NoError(t, db.Dummy().Create(ctx, d, event))
+		}
+
+		claimed, err := outbox.Claim(ctx, 100, time.Now().Add(time.Minute))
+		require.NoError(t, err)
+		require.Len(t, claimed, 3)
+		require.True(t, sort.SliceIsSorted(claimed, func(i, j int) bool {
+			if !claimed[i].CreatedAt.Equal(claimed[j].CreatedAt) {
+				return claimed[i].CreatedAt.Before(claimed[j].CreatedAt)
+			}
+			return claimed[i].ID.String() < claimed[j].ID.String()
+		}), "claimed events are sorted by creation time and id")
+
+		again, err := outbox.Claim(ctx, 100, time.Now().Add(time.Minute))
+		require.NoError(t, err)
+		require.Empty(t, again, "claimed events are leased")
+
+		require.NoError(t, outbox.Retry(ctx, claimed[0].ID, time.Now().Add(-time.Second), "failure"))
+		retried, err := outbox.Claim(ctx, 100, time.Now().Add(time.Minute))
+		require.NoError(t, err)
+		require.Len(t, retried, 1)
+		require.Equal(t, claimed[0].ID, retried[0].ID)
+		require.Equal(t, 2, retried[0].Attempts)
+
+		for _, event := range claimed {
+			require.NoError(t, outbox.MarkPublished(ctx, event.ID, time.Now()))
+		}
+
+		deleted, err := outbox.DeletePublished(ctx, time.Now().Add(time.Second))
+		require.NoError(t, err)
+		require.EqualValues(t, len(claimed), deleted)
+	})
+}
@@ -0,0 +1,133 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/zeebo/errs"
+
+	"project_template/pkg/logger"
+)
+
+// Publisher delivers events to an external system.
+type Publisher interface {
+	// Publish returns nil only if the event was accepted by the receiver.
+	Publish(ctx context.Context, event Event) error
+	// Close releases resources of the publisher.
+	Close() error
+}
+
+// NewPublisher creates the publisher selected in the config.
+func NewPublisher(log logger.Logger, config Config) (Publisher, error) {
+	switch config.Publisher {
+	case "log":
+		return NewLogPublisher(log), nil
+	case "webhook":
+		return NewWebhookPublisher(config.WebhookURL), nil
+	case "nats":
+		return NewNATSPublisher(config.NATSAddress, config.NATSSubject, config.Timeout, NATSOptions(config)...), nil
+	default:
+		return nil, ErrEvents.New("unknown publisher %q", config.Publisher)
+	}
+}
+
+// logPublisher writes events to the log, it's useful for local development.
+type logPublisher struct {
+	log logger.Logger
+}
+
+// NewLogPublisher creates a publisher which writes events to the log.
+func NewLogPublisher(log logger.Logger) Publisher {
+	return &logPublisher{log: log}
+}
+
+func (publisher *logPublisher) Publish(ctx context.Context, event Event) error {
+	publisher.log.Debug(fmt.Sprintf("event %s %s: %s", event.Type, event.ID, event.Payload))
+	return nil
+}
+
+func (publisher *logPublisher) Close() error {
+	return nil
+}
+
+// webhookPublisher posts events as json to a single url.
+type webhookPublisher struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookPublisher creates a publisher which posts events to the url, any 2xx response
+// means the event was accepted. Event id and type are also sent in X-Event-ID and X-Event-Type headers.
+func NewWebhookPublisher(url string) Publisher {
+	return &webhookPublisher{url: url, client: &http.Client{}}
+}
+
+func (publisher *webhookPublisher) Publish(ctx context.Context, event Event) error {
+	body, err := marshalEvent(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, publisher.url, bytes.NewReader(body))
+	if err != nil {
+		return ErrEvents.Wrap(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-ID", event.ID.String())
+	req.Header.Set("X-Event-Type", event.Type)
+
+	resp, err := publisher.client.Do(req)
+	if err != nil {
+		return ErrEvents.Wrap(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return ErrEvents.New("webhook responded with %s", resp.Status)
+	}
+
+	return nil
+}
+
+func (publisher *webhookPublisher) Close() error {
+	publisher.client.CloseIdleConnections()
+	return nil
+}
+
+// multiPublisher publishes events to several publishers.
+type multiPublisher struct {
+	publishers []Publisher
+}
+
+// NewMultiPublisher creates a publisher which publishes every event to all publishers. It fails if
+// any of them fails, so the event is published again to all of them when it's retried.
+func NewMultiPublisher(publishers ...Publisher) Publisher {
+	return &multiPublisher{publishers: publishers}
+}
+
+func (publisher *multiPublisher) Publish(ctx context.Context, event Event) error {
+	var group errs.Group
+	for _, p := range publisher.publishers {
+		group.Add(p.Publish(ctx, event))
+	}
+	return group.Err()
+}
+
+func (publisher *multiPublisher) Close() error {
+	var group errs.Group
+	for _, p := range publisher.publishers {
+		group.Add(p.Close())
+	}
+	return group.Err()
+}
+
+// marshalEvent encodes the event with its id, type, payload and creation time.
+func marshalEvent(event Event) ([]byte, error) {
+	data, err := json.Marshal(event)
+	return data, ErrEvents.Wrap(err)
+}
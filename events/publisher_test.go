@@ -0,0 +1,264 @@
+package events_test
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"project_template/events"
+)
+
+func TestWebhookPublisher(t *testing.T) {
+	ctx := context.Background()
+
+	event, err := events.New(testPayload{Name: "webhook"})
+	require.NoError(t, err)
+
+	status := http.StatusNoContent
+	var received events.Event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, event.ID.String(), r.Header.Get("X-Event-ID"))
+		require.Equal(t, event.Type, r.Header.Get("X-Event-Type"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	publisher := events.NewWebhookPublisher(server.URL)
+	defer func() { require.NoError(t, publisher.Close()) }()
+
+	require.NoError(t, publisher.Publish(ctx, event))
+	require.Equal(t, event.ID, received.ID)
+	require.JSONEq(t, `{"name":"webhook"}`, string(received.Payload))
+
+	status = http.StatusServiceUnavailable
+	err = publisher.Publish(ctx, event)
+	require.Error(t, err)
+	require.True(t, events.ErrEvents.Has(err))
+}
+
+func TestNATSPublisher(t *testing.T) {
+	ctx := context.Background()
+
+	type message struct {
+		subject string
+		data    string
+	}
+	messages := make(chan message, 2)
+
+	address := listenNATS(t, natsServer{
+		info: `{"server_id":"test","max_payload":1048576}`,
+		publish: func(subject, data string) string {
+			messages <- message{subject: subject, data: data}
+			if strings.Contains(data, "rejected") {
+				return "-ERR 'Permissions Violation for Publish to " + subject + "'"
+			}
+			return ""
+		},
+	})
+
+	publisher := events.NewNATSPublisher(address, "app", time.Second)
+	defer func() { require.NoError(t, publisher.Close()) }()
+
+	event, err := events.New(testPayload{Name: "nats"})
+	require.NoError(t, err)
+	require.NoError(t, publisher.Publish(ctx, event))
+
+	received := <-messages
+	require.Equal(t, "app.test.event", received.subject)
+	var decoded events.Event
+	require.NoError(t, json.Unmarshal([]byte(received.data), &decoded))
+	require.Equal(t, event.ID, decoded.ID)
+
+	rejected, err := events.New(testPayload{Name: "rejected"})
+	require.NoError(t, err)
+	err = publisher.Publish(ctx, rejected)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Permissions Violation")
+	<-messages
+
+	// the error is reported only for the rejected event.
+	require.NoError(t, publisher.Publish(ctx, event))
+	<-messages
+}
+
+func TestNATSPublisherAuth(t *testing.T) {
+	ctx := context.Background()
+
+	address := listenNATS(t, natsServer{
+		info: `{"server_id":"test","max_payload":1048576,"auth_required":true}`,
+		connect: func(options string) bool {
+			return strings.Contains(options, `"user":"app"`) && strings.Contains(options, `"pass":"secret"`)
+		},
+	})
+
+	event, err := events.New(testPayload{Name: "nats"})
+	require.NoError(t, err)
+
+	anonymous := events.NewNATSPublisher(address, "app", time.Second)
+	defer func() { require.NoError(t, anonymous.Close()) }()
+
+	err = anonymous.Publish(ctx, event)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Authorization Violation")
+
+	config := events.Config{NATSUser: "app", NATSPassword: "secret", Timeout: time.Second}
+	publisher := events.NewNATSPublisher(address, "app", time.Second, events.NATSOptions(config)...)
+	defer func() { require.NoError(t, publisher.Close()) }()
+
+	require.NoError(t, publisher.Publish(ctx, event))
+}
+
+func TestNATSPublisherTLS(t *testing.T) {
+	ctx := context.Background()
+
+	// the test server provides a certificate valid for 127.0.0.1.
+	certServer := httptest.NewTLSServer(http.NotFoundHandler())
+	defer certServer.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certServer.Certificate().Raw})
+	require.NoError(t, os.WriteFile(caFile, ca, 0600))
+
+	address := listenNATS(t, natsServer{
+		info: `{"server_id":"test","max_payload":1048576,"tls_required":true}`,
+		tls:  &tls.Config{Certificates: certServer.TLS.Certificates},
+	})
+
+	event, err := events.New(testPayload{Name: "nats"})
+	require.NoError(t, err)
+
+	// the client switches to TLS required by the server, but doesn't trust its certificate.
+	untrusted := events.NewNATSPublisher(address, "app", time.Second)
+	defer func() { require.NoError(t, untrusted.Close()) }()
+	require.Error(t, untrusted.Publish(ctx, event))
+
+	config := events.Config{NATSTLS: true, NATSCAFile: caFile, Timeout: time.Second}
+	publisher := events.NewNATSPublisher(address, "app", time.Second, events.NATSOptions(config)...)
+	defer func() { require.NoError(t, publisher.Close()) }()
+
+	require.NoError(t, publisher.Publish(ctx, event))
+}
+
+// natsServer is a minimal NATS server.
+type natsServer struct {
+	// info is sent to every client.
+	info string
+	// tls is used to upgrade connections after INFO if it's set.
+	tls *tls.Config
+	// connect checks CONNECT options, the client is rejected if it returns false.
+	connect func(options string) bool
+	// publish is called for every published message, a returned error line
+	// is sent to the client before the PONG of the following PING.
+	publish func(subject, data string) string
+}
+
+// listenNATS serves NATS connections until the test ends and returns the server address.
+func listenNATS(t *testing.T, server natsServer) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+
+	return listener.Addr().String()
+}
+
+// serve handles a single client connection.
+func (server natsServer) serve(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+
+	if _, err := conn.Write([]byte("INFO " + server.info + "\r\n")); err != nil {
+		return
+	}
+	if server.tls != nil {
+		conn = tls.Server(conn, server.tls)
+	}
+
+	reader := bufio.NewReader(conn)
+	var errLine string
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch fields[0] {
+		case "CONNECT":
+			if server.connect != nil && !server.connect(line) {
+				_, _ = conn.Write([]byte("-ERR 'Authorization Violation'\r\n"))
+				return
+			}
+		case "PUB":
+			size, err := strconv.Atoi(fields[len(fields)-1])
+			if err != nil {
+				return
+			}
+			data := make([]byte, size+2)
+			if _, err = io.ReadFull(reader, data); err != nil {
+				return
+			}
+			if server.publish != nil {
+				errLine = server.publish(fields[1], string(data[:size]))
+			}
+		case "PING":
+			response := "PONG\r\n"
+			if errLine != "" {
+				response = errLine + "\r\n" + response
+				errLine = ""
+			}
+			if _, err = conn.Write([]byte(response)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func TestMultiPublisher(t *testing.T) {
+	ctx := context.Background()
+
+	event, err := events.New(testPayload{Name: "multi"})
+	require.NoError(t, err)
+
+	first := &fakePublisher{}
+	second := &fakePublisher{fail: map[uuid.UUID]bool{event.ID: true}}
+	publisher := events.NewMultiPublisher(first, second)
+
+	require.Error(t, publisher.Publish(ctx, event))
+	require.Equal(t, []uuid.UUID{event.ID}, first.published, "all publishers are called")
+
+	second.fail = nil
+	require.NoError(t, publisher.Publish(ctx, event))
+	require.Len(t, second.published, 1)
+
+	require.NoError(t, publisher.Close())
+	require.True(t, first.closed)
+	require.True(t, second.closed)
+}
@@ -0,0 +1,129 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"project_template/pkg/backoff"
+	"project_template/pkg/logger"
+)
+
+// Config contains configuration of the outbox relay and its publisher.
+type Config struct {
+	Publisher       string        `env:"EVENTS_PUBLISHER" validate:"required,oneof=log webhook nats" envDefault:"log" desc:"where events are published to: log, webhook or nats"`
+	WebhookURL      string        `env:"EVENTS_WEBHOOK_URL" validate:"required_if=Publisher webhook" secret:"true" desc:"url events are posted to by the webhook publisher"`
+	NATSAddress     string        `env:"EVENTS_NATS_ADDRESS" validate:"required_if=Publisher nats" desc:"address of the NATS server, e.g. localhost:4222 or tls://nats.example.com:4222"`
+	NATSSubject     string        `env:"EVENTS_NATS_SUBJECT" envDefault:"template_project" desc:"prefix of NATS subjects, the event type is appended to it"`
+	NATSUser        string        `env:"EVENTS_NATS_USER" desc:"user name for NATS authentication"`
+	NATSPassword    string        `env:"EVENTS_NATS_PASSWORD" secret:"true" desc:"password for NATS authentication"`
+	NATSToken       string        `env:"EVENTS_NATS_TOKEN" secret:"true" desc:"token for NATS authentication, used instead of user and password"`
+	NATSCredentials string        `env:"EVENTS_NATS_CREDENTIALS" desc:"path to a NATS credentials file with user JWT and nkey seed, used instead of token and user"`
+	NATSTLS         bool          `env:"EVENTS_NATS_TLS" desc:"connect to NATS over TLS, it's also enabled by tls:// address and TLS files"`
+	NATSCAFile      string        `env:"EVENTS_NATS_CA_FILE" desc:"path to PEM CA certificates the NATS server certificate is verified with, system ones by default"`
+	NATSCertFile    string        `env:"EVENTS_NATS_CERT_FILE" validate:"required_with=NATSKeyFile" desc:"path to PEM client certificate for NATS TLS authentication"`
+	NATSKeyFile     string        `env:"EVENTS_NATS_KEY_FILE" validate:"required_with=NATSCertFile" desc:"path to PEM key of the NATS client certificate"`
+	Timeout         time.Duration `env:"EVENTS_PUBLISH_TIMEOUT" envDefault:"10s" desc:"timeout of a single publishing attempt"`
+	PollInterval    time.Duration `env:"EVENTS_POLL_INTERVAL" envDefault:"1s" desc:"how often the outbox is checked for new events"`
+	BatchSize       int           `env:"EVENTS_BATCH_SIZE" validate:"min=1" envDefault:"100" desc:"number of events claimed from the outbox at once"`
+	Lease           time.Duration `env:"EVENTS_LEASE" envDefault:"1m" desc:"time claimed events are hidden from other relays, they are published again if it expires"`
+	MinRetryDelay   time.Duration `env:"EVENTS_MIN_RETRY_DELAY" envDefault:"1s" desc:"delay before the first retry, doubled after every failed attempt"`
+	MaxRetryDelay   time.Duration `env:"EVENTS_MAX_RETRY_DELAY" envDefault:"10m" desc:"maximum delay between retries"`
+	Retention       time.Duration `env:"EVENTS_RETENTION" envDefault:"168h" desc:"how long published events are kept in the outbox"`
+}
+
+// Relay publishes events from the outbox. Every event is published at least once: it's marked
+// as published only after the publisher succeeded, and failed attempts are retried with backoff.
+// Events of a claimed batch are published oldest first, but there is no order across batches:
+// several relays publish their batches concurrently, events of long transactions become visible
+// after newer ones, and failed or expired events are retried later. Consumers should rely on event
+// ids to detect duplicates and must not rely on the order of events.
+//
+// architecture: Worker
+type Relay struct {
+	log       logger.Logger
+	config    Config
+	db        DB
+	publisher Publisher
+}
+
+// NewRelay is a constructor for the outbox relay.
+func NewRelay(log logger.Logger, config Config, db DB, publisher Publisher) *Relay {
+	return &Relay{
+		log:       log,
+		config:    config,
+		db:        db,
+		publisher: publisher,
+	}
+}
+
+// Run publishes events until the context is canceled.
+func (relay *Relay) Run(ctx context.Context) error {
+	for {
+		claimed, err := relay.Process(ctx)
+		if err != nil && ctx.Err() == nil {
+			relay.log.Error("could not process outbox events", ErrEvents.Wrap(err))
+		}
+
+		// a full batch means there may be more due events.
+		if err == nil && claimed == relay.config.BatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(relay.config.PollInterval):
+		}
+	}
+}
+
+// Process claims a batch of due events and publishes them, it returns the number of claimed events.
+func (relay *Relay) Process(ctx context.Context) (int, error) {
+	events, err := relay.db.Claim(ctx, relay.config.BatchSize, time.Now().Add(relay.config.Lease))
+	if err != nil {
+		return 0, ErrEvents.Wrap(err)
+	}
+
+	for _, event := range events {
+		if err = relay.publish(ctx, event); err != nil {
+			return len(events), err
+		}
+	}
+
+	return len(events), nil
+}
+
+// publish publishes a single event, failed attempts are scheduled for retry.
+func (relay *Relay) publish(ctx context.Context, event Event) error {
+	publishCtx, cancel := context.WithTimeout(ctx, relay.config.Timeout)
+	publishErr := relay.publisher.Publish(publishCtx, event)
+	cancel()
+
+	if publishErr == nil {
+		return ErrEvents.Wrap(relay.db.MarkPublished(ctx, event.ID, time.Now()))
+	}
+
+	delay := backoff.Exponential(event.Attempts, relay.config.MinRetryDelay, relay.config.MaxRetryDelay)
+	relay.log.Error(fmt.Sprintf("could not publish event %s %s, attempt %d, retry in %s", event.Type, event.ID, event.Attempts, delay), ErrEvents.Wrap(publishErr))
+
+	return ErrEvents.Wrap(relay.db.Retry(ctx, event.ID, time.Now().Add(delay), publishErr.Error()))
+}
+
+// Prune deletes published events older than the retention, it's run as a scheduled job.
+func (relay *Relay) Prune(ctx context.Context) error {
+	deleted, err := relay.db.DeletePublished(ctx, time.Now().Add(-relay.config.Retention))
+	if err != nil {
+		return ErrEvents.Wrap(err)
+	}
+
+	if deleted > 0 {
+		relay.log.Debug(fmt.Sprintf("deleted %d published events", deleted))
+	}
+	return nil
+}
+
+// Close closes the publisher.
+func (relay *Relay) Close() error {
+	return ErrEvents.Wrap(relay.publisher.Close())
+}
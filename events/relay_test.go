@@ -0,0 +1,188 @@
+package events_test
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"project_template/events"
+	"project_template/pkg/logger/zaplog"
+)
+
+func TestRelay(t *testing.T) {
+	ctx := context.Background()
+
+	config := events.Config{
+		Timeout:       time.Second,
+		BatchSize:     10,
+		Lease:         time.Minute,
+		MinRetryDelay: time.Second,
+		MaxRetryDelay: time.Minute,
+	}
+
+	db := newMemoryOutbox()
+	first := db.add(t, testPayload{Name: "first"})
+	failing := db.add(t, testPayload{Name: "failing"})
+
+	publisher := &fakePublisher{fail: map[uuid.UUID]bool{failing: true}}
+	relay := events.NewRelay(zaplog.NewLog(), config, db, publisher)
+
+	claimed, err := relay.Process(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 2, claimed)
+	require.Equal(t, []uuid.UUID{first}, publisher.published)
+	require.False(t, db.events[first].publishedAt.IsZero())
+
+	retried := db.events[failing]
+	require.True(t, retried.publishedAt.IsZero())
+	require.Equal(t, "publisher is down", retried.lastError)
+	require.WithinDuration(t, time.Now().Add(config.MinRetryDelay), retried.nextAttemptAt, 500*time.Millisecond)
+
+	claimed, err = relay.Process(ctx)
+	require.NoError(t, err)
+	require.Zero(t, claimed, "failed event is not due yet")
+
+	// the delay is doubled after every failed attempt.
+	retried.nextAttemptAt = time.Now()
+	_, err = relay.Process(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 2, retried.attempts)
+	require.WithinDuration(t, time.Now().Add(2*config.MinRetryDelay), retried.nextAttemptAt, 500*time.Millisecond)
+
+	publisher.fail = nil
+	retried.nextAttemptAt = time.Now()
+	_, err = relay.Process(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []uuid.UUID{first, failing}, publisher.published)
+	require.False(t, retried.publishedAt.IsZero())
+
+	require.NoError(t, relay.Close())
+	require.True(t, publisher.closed)
+}
+
+func TestRelayRun(t *testing.T) {
+	config := events.Config{
+		Timeout:      time.Second,
+		PollInterval: 10 * time.Millisecond,
+		BatchSize:    1,
+		Lease:        time.Minute,
+		Retention:    time.Hour,
+	}
+
+	db := newMemoryOutbox()
+	for i := 0; i < 3; i++ {
+		db.add(t, testPayload{Name: "event"})
+	}
+	publisher := &fakePublisher{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	err := events.NewRelay(zaplog.NewLog(), config, db, publisher).Run(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Len(t, publisher.published, 3)
+}
+
+type testPayload struct {
+	Name string `json:"name"`
+}
+
+func (testPayload) EventType() string { return "test.event" }
+
+// outboxEvent is an event with its publishing state.
+type outboxEvent struct {
+	events.Event
+	attempts      int
+	nextAttemptAt time.Time
+	publishedAt   time.Time
+	lastError     string
+}
+
+// memoryOutbox is an in-memory events.DB.
+type memoryOutbox struct {
+	events map[uuid.UUID]*outboxEvent
+}
+
+func newMemoryOutbox() *memoryOutbox {
+	return &memoryOutbox{events: make(map[uuid.UUID]*outboxEvent)}
+}
+
+func (db *memoryOutbox) add(t *testing.T, payload events.Payload) uuid.UUID {
+	event, err := events.New(payload)
+	require.NoError(t, err)
+	// events created in the same instant are ordered by creation.
+	event.CreatedAt = event.CreatedAt.Add(time.Duration(len(db.events)))
+
+	db.events[event.ID] = &outboxEvent{Event: event, nextAttemptAt: event.CreatedAt}
+	return event.ID
+}
+
+func (db *memoryOutbox) Claim(ctx context.Context, limit int, leaseUntil time.Time) ([]events.Event, error) {
+	var due []*outboxEvent
+	for _, event := range db.events {
+		if event.publishedAt.IsZero() && !event.nextAttemptAt.After(time.Now()) {
+			due = append(due, event)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].CreatedAt.Before(due[j].CreatedAt) })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	result := make([]events.Event, 0, len(due))
+	for _, event := range due {
+		event.attempts++
+		event.nextAttemptAt = leaseUntil
+		claimed := event.Event
+		claimed.Attempts = event.attempts
+		result = append(result, claimed)
+	}
+	return result, nil
+}
+
+func (db *memoryOutbox) MarkPublished(ctx context.Context, id uuid.UUID, publishedAt time.Time) error {
+	db.events[id].publishedAt = publishedAt
+	db.events[id].lastError = ""
+	return nil
+}
+
+func (db *memoryOutbox) Retry(ctx context.Context, id uuid.UUID, nextAttemptAt time.Time, lastError string) error {
+	db.events[id].nextAttemptAt = nextAttemptAt
+	db.events[id].lastError = lastError
+	return nil
+}
+
+func (db *memoryOutbox) DeletePublished(ctx context.Context, before time.Time) (deleted int64, err error) {
+	for id, event := range db.events {
+		if !event.publishedAt.IsZero() && event.publishedAt.Before(before) {
+			delete(db.events, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// fakePublisher records published events and fails events from the fail set.
+type fakePublisher struct {
+	fail      map[uuid.UUID]bool
+	published []uuid.UUID
+	closed    bool
+}
+
+func (publisher *fakePublisher) Publish(ctx context.Context, event events.Event) error {
+	if publisher.fail[event.ID] {
+		return errors.New("publisher is down")
+	}
+	publisher.published = append(publisher.published, event.ID)
+	return nil
+}
+
+func (publisher *fakePublisher) Close() error {
+	publisher.closed = true
+	return nil
+}
@@ -0,0 +1,74 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zeebo/errs"
+)
+
+// ErrJobs indicates that there was an error in the scheduler.
+var ErrJobs = errs.Class("jobs error")
+
+// ErrNoJob indicates that a job is not registered.
+var ErrNoJob = errs.Class("job does not exist")
+
+// Job is a function run periodically by the scheduler.
+type Job struct {
+	// Name identifies the job in the history, metrics and leader election, it must be unique.
+	Name string
+	// Schedule describes when the job runs.
+	Schedule Schedule
+	// Timeout limits a single run, the scheduler's default timeout is used if it's zero.
+	Timeout time.Duration
+	// Run does the job, runs of a job never overlap.
+	Run func(ctx context.Context) error
+}
+
+// RunStatus is a status of a job run.
+type RunStatus string
+
+const (
+	// RunRunning means that the job is running, or the replica running it crashed.
+	RunRunning RunStatus = "running"
+	// RunSucceeded means that the job returned no error.
+	RunSucceeded RunStatus = "succeeded"
+	// RunFailed means that the job returned an error or panicked.
+	RunFailed RunStatus = "failed"
+)
+
+// Run is a record of a single job run.
+type Run struct {
+	ID         uuid.UUID  `json:"id"`
+	Job        string     `json:"job"`
+	Status     RunStatus  `json:"status"`
+	StartedAt  time.Time  `json:"startedAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
+
+// Leadership is held by a replica which runs a job.
+type Leadership interface {
+	// Check returns an error if the leadership could be lost.
+	Check(ctx context.Context) error
+	// Release gives up the leadership.
+	Release() error
+}
+
+// DB is exposing access to the leader election and the history of job runs.
+//
+// architecture: Database
+type DB interface {
+	// TryLead makes the caller the leader of the job, it returns nil if another replica leads it.
+	TryLead(ctx context.Context, job string) (Leadership, error)
+
+	// StartRun records a started run.
+	StartRun(ctx context.Context, run Run) error
+	// FinishRun records the status, finish time and error of a run.
+	FinishRun(ctx context.Context, id uuid.UUID, status RunStatus, finishedAt time.Time, runErr string) error
+	// ListRuns returns the latest runs of the job, the most recent first.
+	ListRuns(ctx context.Context, job string, limit int) ([]Run, error)
+	// DeleteRuns deletes runs started before the given time and returns their number.
+	DeleteRuns(ctx context.Context, before time.Time) (int64, error)
+}
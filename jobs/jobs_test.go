@@ -0,0 +1,54 @@
+package jobs_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"project_template"
+	"project_template/database/dbtesting"
+	"project_template/jobs"
+)
+
+func TestJobs(t *testing.T) {
+	dbtesting.Run(t, func(ctx context.Context, t *testing.T, db project_template.DB) {
+		jobsRepo := db.Jobs()
+
+		t.Run("leader lock", func(t *testing.T) {
+			leadership, err := jobsRepo.TryLead(ctx, "test")
+			require.NoError(t, err)
+			require.NotNil(t, leadership)
+			require.NoError(t, leadership.Check(ctx))
+
+			other, err := jobsRepo.TryLead(ctx, "test")
+			require.NoError(t, err)
+			require.Nil(t, other, "the job is led by another session")
+
+			require.NoError(t, leadership.Release())
+			other, err = jobsRepo.TryLead(ctx, "test")
+			require.NoError(t, err)
+			require.NotNil(t, other)
+			require.NoError(t, other.Release())
+		})
+
+		t.Run("history", func(t *testing.T) {
+			run := jobs.Run{ID: uuid.New(), Job: "test", Status: jobs.RunRunning, StartedAt: time.Now()}
+			require.NoError(t, jobsRepo.StartRun(ctx, run))
+			require.NoError(t, jobsRepo.FinishRun(ctx, run.ID, jobs.RunFailed, time.Now(), "failure"))
+
+			runs, err := jobsRepo.ListRuns(ctx, "test", 10)
+			require.NoError(t, err)
+			require.Len(t, runs, 1)
+			require.Equal(t, jobs.RunFailed, runs[0].Status)
+			require.Equal(t, "failure", runs[0].Error)
+			require.NotNil(t, runs[0].FinishedAt)
+
+			deleted, err := jobsRepo.DeleteRuns(ctx, time.Now().Add(time.Second))
+			require.NoError(t, err)
+			require.EqualValues(t, 1, deleted)
+		})
+	})
+}
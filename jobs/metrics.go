@@ -0,0 +1,29 @@
+package jobs
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	runsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "template_project_job_runs_total",
+		Help: "Number of finished job runs by status.",
+	}, []string{"job", "status"})
+
+	runDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "template_project_job_run_duration_seconds",
+		Help:    "Duration of job runs.",
+		Buckets: prometheus.ExponentialBuckets(0.01, 4, 10),
+	}, []string{"job"})
+
+	lastSuccess = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "template_project_job_last_success_timestamp_seconds",
+		Help: "Unix time of the last successful run of a job by this replica.",
+	}, []string{"job"})
+
+	leading = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "template_project_job_leader",
+		Help: "Whether this replica leads the job: 1 if it does, 0 otherwise.",
+	}, []string{"job"})
+)
@@ -0,0 +1,214 @@
+package jobs
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zeebo/errs"
+)
+
+// ErrSchedule indicates that a schedule could not be parsed.
+var ErrSchedule = errs.Class("invalid schedule")
+
+// Schedule describes when a job runs.
+type Schedule interface {
+	// Next returns the first time the job runs strictly after the given time.
+	Next(after time.Time) time.Time
+	// String returns the schedule in the form it's parsed from.
+	String() string
+}
+
+// interval runs a job every fixed duration.
+type interval time.Duration
+
+// Every returns a schedule which runs a job every given interval, it panics if the interval is not positive.
+func Every(every time.Duration) Schedule {
+	if every <= 0 {
+		panic("jobs: interval must be positive")
+	}
+	return interval(every)
+}
+
+func (every interval) Next(after time.Time) time.Time { return after.Add(time.Duration(every)) }
+
+func (every interval) String() string { return "@every " + time.Duration(every).String() }
+
+// cron is a schedule in the standard five field cron format, it's evaluated in UTC.
+type cron struct {
+	spec string
+
+	minute, hour, dom, month, dow uint64
+	// domAny and dowAny are set if the field is "*": the day matches when both fields match,
+	// otherwise when any of restricted fields matches.
+	domAny, dowAny bool
+}
+
+// descriptors are predefined schedules.
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// ParseCron parses a schedule in the standard cron format "minute hour day-of-month month day-of-week",
+// every field is "*", a number, a range "1-5" or a list of them "1,3-5", with an optional step "*/15".
+// Sunday is both 0 and 7 in the day of week. Descriptors like "@daily" and "@every 10m" are accepted too.
+// Cron schedules are evaluated in UTC.
+func ParseCron(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+
+	if every, ok := cutPrefix(spec, "@every "); ok {
+		duration, err := time.ParseDuration(strings.TrimSpace(every))
+		if err != nil {
+			return nil, ErrSchedule.New("%q: %v", spec, err)
+		}
+		if duration <= 0 {
+			return nil, ErrSchedule.New("%q: interval must be positive", spec)
+		}
+		return Every(duration), nil
+	}
+
+	fields := strings.Fields(spec)
+	if descriptor, ok := descriptors[spec]; ok {
+		fields = strings.Fields(descriptor)
+	}
+	if len(fields) != 5 {
+		return nil, ErrSchedule.New("%q: expected 5 fields, got %d", spec, len(fields))
+	}
+
+	schedule := &cron{spec: spec}
+
+	var err error
+	parsers := []struct {
+		field    *uint64
+		min, max int
+	}{
+		{&schedule.minute, 0, 59},
+		{&schedule.hour, 0, 23},
+		{&schedule.dom, 1, 31},
+		{&schedule.month, 1, 12},
+		{&schedule.dow, 0, 7},
+	}
+	for i, parser := range parsers {
+		if *parser.field, err = parseField(fields[i], parser.min, parser.max); err != nil {
+			return nil, ErrSchedule.New("%q: %v", spec, err)
+		}
+	}
+
+	// sunday is 7 as well as 0.
+	if schedule.dow&(1<<7) != 0 {
+		schedule.dow |= 1
+	}
+	schedule.domAny = fields[2] == "*"
+	schedule.dowAny = fields[4] == "*"
+
+	return schedule, nil
+}
+
+// MustParseCron is like ParseCron but panics if the spec can't be parsed.
+func MustParseCron(spec string) Schedule {
+	schedule, err := ParseCron(spec)
+	if err != nil {
+		panic(err)
+	}
+	return schedule
+}
+
+// parseField parses a single cron field into a bit set of matching values.
+func parseField(field string, min, max int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepStr); err != nil || step <= 0 {
+				return 0, errs.New("invalid step %q", part)
+			}
+		}
+
+		low, high := min, max
+		switch {
+		case rng == "*":
+		case strings.Contains(rng, "-"):
+			lowStr, highStr, _ := strings.Cut(rng, "-")
+			var errLow, errHigh error
+			low, errLow = strconv.Atoi(lowStr)
+			high, errHigh = strconv.Atoi(highStr)
+			if errLow != nil || errHigh != nil || low > high {
+				return 0, errs.New("invalid range %q", part)
+			}
+		default:
+			value, err := strconv.Atoi(rng)
+			if err != nil {
+				return 0, errs.New("invalid value %q", part)
+			}
+			low, high = value, value
+			// "5/10" means from 5 to the maximum with the step 10.
+			if hasStep {
+				high = max
+			}
+		}
+
+		if low < min || high > max {
+			return 0, errs.New("%q is out of range %d-%d", part, min, max)
+		}
+
+		for value := low; value <= high; value += step {
+			bits |= 1 << value
+		}
+	}
+	return bits, nil
+}
+
+// maxSearch limits the search of the next time, e.g. "0 0 30 2 *" never matches.
+const maxSearch = 5 * 366 * 24 * time.Hour
+
+func (schedule *cron) Next(after time.Time) time.Time {
+	t := after.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(maxSearch)
+
+	for t.Before(limit) {
+		switch {
+		case schedule.month&(1<<t.Month()) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		case !schedule.matchesDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+		case schedule.hour&(1<<t.Hour()) == 0:
+			t = t.Truncate(time.Hour).Add(time.Hour)
+		case schedule.minute&(1<<t.Minute()) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+// matchesDay checks both day of month and day of week fields.
+func (schedule *cron) matchesDay(t time.Time) bool {
+	dom := schedule.dom&(1<<t.Day()) != 0
+	dow := schedule.dow&(1<<t.Weekday()) != 0
+
+	if schedule.domAny || schedule.dowAny {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+func (schedule *cron) String() string { return schedule.spec }
+
+// cutPrefix is strings.CutPrefix, which is not available in go 1.18.
+func cutPrefix(s, prefix string) (string, bool) {
+	if !strings.HasPrefix(s, prefix) {
+		return s, false
+	}
+	return s[len(prefix):], true
+}
@@ -0,0 +1,66 @@
+package jobs_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"project_template/jobs"
+)
+
+func TestParseCron(t *testing.T) {
+	start := time.Date(2022, 3, 15, 10, 30, 45, 0, time.UTC) // tuesday.
+
+	for _, test := range []struct {
+		spec string
+		next []time.Time
+	}{
+		{"*/15 * * * *", []time.Time{
+			time.Date(2022, 3, 15, 10, 45, 0, 0, time.UTC),
+			time.Date(2022, 3, 15, 11, 0, 0, 0, time.UTC),
+		}},
+		{"0 9-17/4 * * 1-5", []time.Time{
+			time.Date(2022, 3, 15, 13, 0, 0, 0, time.UTC),
+			time.Date(2022, 3, 15, 17, 0, 0, 0, time.UTC),
+			time.Date(2022, 3, 16, 9, 0, 0, 0, time.UTC),
+		}},
+		{"30 4 1,15 * 7", []time.Time{
+			time.Date(2022, 3, 20, 4, 30, 0, 0, time.UTC),
+			time.Date(2022, 3, 27, 4, 30, 0, 0, time.UTC),
+			time.Date(2022, 4, 1, 4, 30, 0, 0, time.UTC),
+		}},
+		{"0 0 29 2 *", []time.Time{
+			time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC),
+		}},
+		{"@daily", []time.Time{
+			time.Date(2022, 3, 16, 0, 0, 0, 0, time.UTC),
+			time.Date(2022, 3, 17, 0, 0, 0, 0, time.UTC),
+		}},
+		{"@every 90m", []time.Time{
+			start.Add(90 * time.Minute),
+			start.Add(180 * time.Minute),
+		}},
+	} {
+		schedule, err := jobs.ParseCron(test.spec)
+		require.NoError(t, err, test.spec)
+
+		next := start
+		for _, expected := range test.next {
+			next = schedule.Next(next)
+			require.Equal(t, expected, next, test.spec)
+		}
+	}
+
+	require.Equal(t, "@every 1h30m0s", jobs.MustParseCron("@every 90m").String())
+	require.Equal(t, "*/15 * * * *", jobs.MustParseCron("*/15 * * * *").String())
+
+	never, err := jobs.ParseCron("0 0 31 2 *")
+	require.NoError(t, err)
+	require.True(t, never.Next(start).IsZero())
+
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* * 0 * *", "5-1 * * * *", "*/0 * * * *", "a * * * *", "@every -1s", "@sometimes"} {
+		_, err := jobs.ParseCron(spec)
+		require.True(t, jobs.ErrSchedule.Has(err), spec)
+	}
+}
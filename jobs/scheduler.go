@@ -0,0 +1,369 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zeebo/errs"
+	"golang.org/x/sync/errgroup"
+
+	"project_template/pkg/logger"
+)
+
+// ErrInvalidJob indicates that a job or a request of its history is not valid.
+var ErrInvalidJob = errs.Class("invalid job")
+
+const (
+	// DefaultRunsLimit is the number of runs returned if the limit is not specified.
+	DefaultRunsLimit = 20
+	// MaxRunsLimit is the maximum number of runs returned at once.
+	MaxRunsLimit = 200
+)
+
+// Config contains configuration of the job scheduler.
+type Config struct {
+	Timeout          time.Duration `env:"JOBS_TIMEOUT" envDefault:"10m" desc:"timeout of a job run if the job does not set its own"`
+	LeaderInterval   time.Duration `env:"JOBS_LEADER_INTERVAL" envDefault:"15s" desc:"how often replicas try to take the leadership of jobs, and leaders check they still hold it"`
+	HistoryRetention time.Duration `env:"JOBS_HISTORY_RETENTION" envDefault:"720h" desc:"how long the history of job runs is kept"`
+}
+
+// finishTimeout limits recording of the run result, which is done even if the scheduler is stopped.
+const finishTimeout = 10 * time.Second
+
+// Status describes a registered job.
+type Status struct {
+	Name     string `json:"name"`
+	Schedule string `json:"schedule"`
+	// Leader is set if the job is run by this replica.
+	Leader bool `json:"leader"`
+	// NextRun is known only by the leader.
+	NextRun *time.Time `json:"nextRun,omitempty"`
+	LastRun *Run       `json:"lastRun,omitempty"`
+}
+
+// Scheduler runs registered jobs on their schedules. Every job is run by a single replica at a time:
+// replicas compete for the leadership of every job, and the leader runs the job until it loses
+// the leadership or the scheduler is stopped. Runs are recorded to the history, so a new leader
+// continues the schedule from the last run, and a missed run is started right away.
+//
+// architecture: Worker
+type Scheduler struct {
+	log    logger.Logger
+	config Config
+	db     DB
+
+	mu   sync.Mutex
+	jobs map[string]*scheduled
+}
+
+// scheduled is a registered job and its state on this replica.
+type scheduled struct {
+	job Job
+
+	mu      sync.Mutex
+	leader  bool
+	nextRun time.Time
+}
+
+// NewScheduler is a constructor for the job scheduler.
+func NewScheduler(log logger.Logger, config Config, db DB) *Scheduler {
+	return &Scheduler{
+		log:    log,
+		config: config,
+		db:     db,
+		jobs:   make(map[string]*scheduled),
+	}
+}
+
+// Register adds a job, jobs should be registered before the scheduler is run.
+func (scheduler *Scheduler) Register(job Job) error {
+	switch {
+	case job.Name == "":
+		return ErrInvalidJob.New("name is required")
+	case job.Schedule == nil:
+		return ErrInvalidJob.New("%s: schedule is required", job.Name)
+	case job.Run == nil:
+		return ErrInvalidJob.New("%s: run function is required", job.Name)
+	}
+
+	scheduler.mu.Lock()
+	defer scheduler.mu.Unlock()
+
+	if _, ok := scheduler.jobs[job.Name]; ok {
+		return ErrInvalidJob.New("%s is already registered", job.Name)
+	}
+
+	scheduler.jobs[job.Name] = &scheduled{job: job}
+	return nil
+}
+
+// Run runs registered jobs until the context is canceled.
+func (scheduler *Scheduler) Run(ctx context.Context) error {
+	group, ctx := errgroup.WithContext(ctx)
+	for _, job := range scheduler.list() {
+		job := job
+		group.Go(func() error {
+			return scheduler.lead(ctx, job)
+		})
+	}
+	return group.Wait()
+}
+
+// list returns registered jobs sorted by name.
+func (scheduler *Scheduler) list() []*scheduled {
+	scheduler.mu.Lock()
+	defer scheduler.mu.Unlock()
+
+	list := make([]*scheduled, 0, len(scheduler.jobs))
+	for _, job := range scheduler.jobs {
+		list = append(list, job)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].job.Name < list[j].job.Name })
+	return list
+}
+
+// lead competes for the leadership of the job and runs it while leading.
+func (scheduler *Scheduler) lead(ctx context.Context, job *scheduled) error {
+	for {
+		leadership, err := scheduler.db.TryLead(ctx, job.job.Name)
+		if err != nil && ctx.Err() == nil {
+			scheduler.log.Error(fmt.Sprintf("could not take leadership of job %s", job.job.Name), ErrJobs.Wrap(err))
+		}
+
+		if leadership != nil {
+			scheduler.log.Debug(fmt.Sprintf("leading job %s", job.job.Name))
+			job.setLeader(true)
+
+			err = scheduler.schedule(ctx, job, leadership)
+			if ctx.Err() == nil {
+				scheduler.log.Warn(fmt.Sprintf("lost leadership of job %s: %v", job.job.Name, err))
+			}
+
+			job.setLeader(false)
+			if err = leadership.Release(); err != nil {
+				scheduler.log.Error(fmt.Sprintf("could not release leadership of job %s", job.job.Name), ErrJobs.Wrap(err))
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(scheduler.config.LeaderInterval):
+		}
+	}
+}
+
+// schedule runs the job on its schedule until the context is canceled or the leadership is lost.
+func (scheduler *Scheduler) schedule(ctx context.Context, job *scheduled, leadership Leadership) error {
+	next, err := scheduler.first(ctx, job.job)
+	if err != nil {
+		return err
+	}
+
+	check := time.NewTicker(scheduler.config.LeaderInterval)
+	defer check.Stop()
+
+	timer := time.NewTimer(time.Until(next))
+	defer timer.Stop()
+
+	due := timer.C
+	if next.IsZero() {
+		due = nil
+	}
+
+	for {
+		job.setNextRun(next)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-check.C:
+			if err = leadership.Check(ctx); err != nil {
+				return err
+			}
+		case <-due:
+			// the leadership is checked right before the run, so it's not run by two replicas.
+			if err = leadership.Check(ctx); err != nil {
+				return err
+			}
+			scheduler.execute(ctx, job.job)
+
+			if next = job.job.Schedule.Next(time.Now()); next.IsZero() {
+				due = nil
+			} else {
+				timer.Reset(time.Until(next))
+			}
+		}
+	}
+}
+
+// first returns the time of the first run after taking the leadership, it continues
+// the schedule from the last recorded run. A zero time means the job never runs.
+func (scheduler *Scheduler) first(ctx context.Context, job Job) (time.Time, error) {
+	runs, err := scheduler.db.ListRuns(ctx, job.Name, 1)
+	if err != nil {
+		return time.Time{}, ErrJobs.Wrap(err)
+	}
+
+	if len(runs) == 0 {
+		return job.Schedule.Next(time.Now()), nil
+	}
+	return job.Schedule.Next(runs[0].StartedAt), nil
+}
+
+// execute runs the job and records the run to the history and metrics.
+func (scheduler *Scheduler) execute(ctx context.Context, job Job) {
+	run := Run{
+		ID:        uuid.New(),
+		Job:       job.Name,
+		Status:    RunRunning,
+		StartedAt: time.Now().UTC(),
+	}
+
+	// the job is run even if the history is unavailable.
+	if err := scheduler.db.StartRun(ctx, run); err != nil {
+		scheduler.log.Error(fmt.Sprintf("could not record start of job %s", job.Name), ErrJobs.Wrap(err))
+	}
+
+	timeout := job.Timeout
+	if timeout == 0 {
+		timeout = scheduler.config.Timeout
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+	runErr := call(runCtx, job)
+	cancel()
+
+	finishedAt := time.Now().UTC()
+	run.FinishedAt = &finishedAt
+	run.Status = RunSucceeded
+	if runErr != nil {
+		run.Status, run.Error = RunFailed, runErr.Error()
+		scheduler.log.Error(fmt.Sprintf("job %s failed", job.Name), ErrJobs.Wrap(runErr))
+	}
+
+	runsTotal.WithLabelValues(job.Name, string(run.Status)).Inc()
+	runDuration.WithLabelValues(job.Name).Observe(finishedAt.Sub(run.StartedAt).Seconds())
+	if runErr == nil {
+		lastSuccess.WithLabelValues(job.Name).Set(float64(finishedAt.Unix()))
+	}
+
+	finishCtx, cancel := context.WithTimeout(context.Background(), finishTimeout)
+	defer cancel()
+
+	if err := scheduler.db.FinishRun(finishCtx, run.ID, run.Status, finishedAt, run.Error); err != nil {
+		scheduler.log.Error(fmt.Sprintf("could not record result of job %s", job.Name), ErrJobs.Wrap(err))
+	}
+}
+
+// call runs the job, a panic is returned as an error.
+func call(ctx context.Context, job Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errs.New("panic: %v\n%s", r, debug.Stack())
+		}
+	}()
+
+	return job.Run(ctx)
+}
+
+// Jobs returns the status of registered jobs sorted by name.
+func (scheduler *Scheduler) Jobs(ctx context.Context) ([]Status, error) {
+	list := scheduler.list()
+
+	statuses := make([]Status, 0, len(list))
+	for _, job := range list {
+		status := job.status()
+
+		runs, err := scheduler.db.ListRuns(ctx, job.job.Name, 1)
+		if err != nil {
+			return nil, ErrJobs.Wrap(err)
+		}
+		if len(runs) > 0 {
+			status.LastRun = &runs[0]
+		}
+
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// Runs returns the latest runs of the job, the most recent first.
+func (scheduler *Scheduler) Runs(ctx context.Context, name string, limit int) ([]Run, error) {
+	switch {
+	case limit == 0:
+		limit = DefaultRunsLimit
+	case limit < 0 || limit > MaxRunsLimit:
+		return nil, ErrInvalidJob.New("limit must be between 1 and %d", MaxRunsLimit)
+	}
+
+	scheduler.mu.Lock()
+	_, ok := scheduler.jobs[name]
+	scheduler.mu.Unlock()
+	if !ok {
+		return nil, ErrNoJob.New("%s", name)
+	}
+
+	runs, err := scheduler.db.ListRuns(ctx, name, limit)
+	return runs, ErrJobs.Wrap(err)
+}
+
+// Prune deletes runs older than the history retention, it's registered as a job itself.
+func (scheduler *Scheduler) Prune(ctx context.Context) error {
+	deleted, err := scheduler.db.DeleteRuns(ctx, time.Now().Add(-scheduler.config.HistoryRetention))
+	if err != nil {
+		return ErrJobs.Wrap(err)
+	}
+
+	if deleted > 0 {
+		scheduler.log.Debug(fmt.Sprintf("deleted %d old job runs", deleted))
+	}
+	return nil
+}
+
+// Close does nothing, jobs are stopped by canceling the context of Run.
+func (scheduler *Scheduler) Close() error {
+	return nil
+}
+
+func (job *scheduled) setLeader(isLeader bool) {
+	job.mu.Lock()
+	defer job.mu.Unlock()
+
+	job.leader = isLeader
+	job.nextRun = time.Time{}
+
+	value := 0.0
+	if isLeader {
+		value = 1
+	}
+	leading.WithLabelValues(job.job.Name).Set(value)
+}
+
+func (job *scheduled) setNextRun(next time.Time) {
+	job.mu.Lock()
+	defer job.mu.Unlock()
+
+	job.nextRun = next
+}
+
+func (job *scheduled) status() Status {
+	job.mu.Lock()
+	defer job.mu.Unlock()
+
+	status := Status{
+		Name:     job.job.Name,
+		Schedule: job.job.Schedule.String(),
+		Leader:   job.leader,
+	}
+	if !job.nextRun.IsZero() {
+		nextRun := job.nextRun
+		status.NextRun = &nextRun
+	}
+	return status
+}
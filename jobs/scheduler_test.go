@@ -0,0 +1,191 @@
+package jobs_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zeebo/errs"
+
+	"project_template"
+	"project_template/database/dbtesting"
+	"project_template/jobs"
+	"project_template/pkg/logger/zaplog"
+)
+
+func TestScheduler(t *testing.T) {
+	dbtesting.Run(t, func(ctx context.Context, t *testing.T, masterDB project_template.DB) {
+		testScheduler(ctx, t, &revocableDB{DB: masterDB.Jobs(), leaderships: make(map[string]*revocableLeadership)})
+	})
+}
+
+func testScheduler(ctx context.Context, t *testing.T, db *revocableDB) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	config := jobs.Config{Timeout: time.Second, LeaderInterval: 10 * time.Millisecond, HistoryRetention: time.Hour}
+
+	var mu sync.Mutex
+	counts := make(map[string]int)
+	count := func(name string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		return counts[name]
+	}
+
+	// two replicas run the same jobs, every run is counted once.
+	var replicas []*jobs.Scheduler
+	for i := 0; i < 2; i++ {
+		scheduler := jobs.NewScheduler(zaplog.NewLog(), config, db)
+		for _, job := range []jobs.Job{
+			{Name: "ok", Schedule: jobs.Every(20 * time.Millisecond), Run: func(ctx context.Context) error {
+				mu.Lock()
+				defer mu.Unlock()
+				counts["ok"]++
+				return nil
+			}},
+			{Name: "fails", Schedule: jobs.Every(20 * time.Millisecond), Run: func(ctx context.Context) error {
+				return errs.New("failure")
+			}},
+			{Name: "panics", Schedule: jobs.Every(20 * time.Millisecond), Run: func(ctx context.Context) error {
+				panic("boom")
+			}},
+		} {
+			require.NoError(t, scheduler.Register(job))
+		}
+		replicas = append(replicas, scheduler)
+	}
+
+	require.True(t, jobs.ErrInvalidJob.Has(replicas[0].Register(jobs.Job{Name: "ok", Schedule: jobs.Every(time.Second), Run: func(context.Context) error { return nil }})))
+	require.True(t, jobs.ErrInvalidJob.Has(replicas[0].Register(jobs.Job{Name: "no schedule", Run: func(context.Context) error { return nil }})))
+
+	var wg sync.WaitGroup
+	for _, scheduler := range replicas {
+		scheduler := scheduler
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			require.ErrorIs(t, scheduler.Run(ctx), context.Canceled)
+		}()
+	}
+
+	require.Eventually(t, func() bool { return count("ok") >= 3 }, 5*time.Second, 5*time.Millisecond)
+
+	statuses, err := replicas[0].Jobs(ctx)
+	require.NoError(t, err)
+	require.Len(t, statuses, 3)
+	require.Equal(t, "fails", statuses[0].Name)
+	require.Equal(t, "@every 20ms", statuses[0].Schedule)
+
+	others, err := replicas[1].Jobs(ctx)
+	require.NoError(t, err)
+	for i := range statuses {
+		// every job is led by a single replica.
+		require.NotEqual(t, statuses[i].Leader, others[i].Leader, statuses[i].Name)
+	}
+
+	t.Run("history", func(t *testing.T) {
+		runs, err := replicas[0].Runs(ctx, "ok", 2)
+		require.NoError(t, err)
+		require.Len(t, runs, 2)
+		require.True(t, runs[0].StartedAt.After(runs[1].StartedAt))
+
+		for _, name := range []string{"fails", "panics"} {
+			require.Eventually(t, func() bool {
+				runs, err := replicas[0].Runs(ctx, name, 1)
+				return err == nil && len(runs) == 1 && runs[0].Status == jobs.RunFailed
+			}, 5*time.Second, 5*time.Millisecond)
+		}
+
+		runs, err = replicas[0].Runs(ctx, "panics", 1)
+		require.NoError(t, err)
+		require.Contains(t, runs[0].Error, "panic: boom")
+
+		_, err = replicas[0].Runs(ctx, "unknown", 0)
+		require.True(t, jobs.ErrNoJob.Has(err))
+		_, err = replicas[0].Runs(ctx, "ok", jobs.MaxRunsLimit+1)
+		require.True(t, jobs.ErrInvalidJob.Has(err))
+	})
+
+	t.Run("leadership is taken over", func(t *testing.T) {
+		db.revoke("ok")
+
+		before := count("ok")
+		require.Eventually(t, func() bool { return count("ok") >= before+2 }, 5*time.Second, 5*time.Millisecond)
+	})
+
+	// replicas are stopped, so no runs are recorded after pruning.
+	cancel()
+	wg.Wait()
+
+	t.Run("prune", func(t *testing.T) {
+		ctx := context.WithoutCancel(ctx)
+		require.NoError(t, replicas[0].Prune(ctx))
+
+		runs, err := replicas[0].Runs(ctx, "ok", 1)
+		require.NoError(t, err)
+		require.Len(t, runs, 1)
+
+		pruning := jobs.NewScheduler(zaplog.NewLog(), jobs.Config{HistoryRetention: -time.Hour}, db)
+		require.NoError(t, pruning.Prune(ctx))
+
+		runs, err = replicas[0].Runs(ctx, "ok", 1)
+		require.NoError(t, err)
+		require.Empty(t, runs)
+	})
+}
+
+// revocableDB is the jobs repository which can revoke leaderships, as if connections holding their locks broke.
+type revocableDB struct {
+	jobs.DB
+
+	mu          sync.Mutex
+	leaderships map[string]*revocableLeadership
+}
+
+// revocableLeadership is lost when it's revoked.
+type revocableLeadership struct {
+	jobs.Leadership
+
+	mu   sync.Mutex
+	lost bool
+}
+
+func (db *revocableDB) TryLead(ctx context.Context, job string) (jobs.Leadership, error) {
+	leadership, err := db.DB.TryLead(ctx, job)
+	if err != nil || leadership == nil {
+		return nil, err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	revocable := &revocableLeadership{Leadership: leadership}
+	db.leaderships[job] = revocable
+	return revocable, nil
+}
+
+// revoke makes the current leader of the job lose the leadership.
+func (db *revocableDB) revoke(job string) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if leadership, ok := db.leaderships[job]; ok {
+		leadership.mu.Lock()
+		leadership.lost = true
+		leadership.mu.Unlock()
+	}
+}
+
+func (leadership *revocableLeadership) Check(ctx context.Context) error {
+	leadership.mu.Lock()
+	lost := leadership.lost
+	leadership.mu.Unlock()
+
+	if lost {
+		return errs.New("leadership is lost")
+	}
+	return leadership.Leadership.Check(ctx)
+}
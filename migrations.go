@@ -0,0 +1,47 @@
+package project_template
+
+import (
+	"context"
+)
+
+// Migrations exposes management of the database schema migrations.
+type Migrations interface {
+	// Status returns the current schema version and the list of pending migrations.
+	Status(ctx context.Context) (MigrationStatus, error)
+
+	// Pending returns migrations that are not applied yet, along with their sql.
+	Pending(ctx context.Context) ([]Migration, error)
+
+	// Up applies all pending migrations.
+	Up(ctx context.Context) error
+
+	// Down rolls back all applied migrations.
+	Down(ctx context.Context) error
+
+	// Goto migrates the schema up or down to the given version.
+	Goto(ctx context.Context, version uint) error
+
+	// Steps applies n next migrations if n is positive, or rolls back -n last migrations otherwise.
+	Steps(ctx context.Context, n int) error
+
+	// Force sets the schema version without running migrations and resets the dirty flag.
+	// Version -1 means that no migration is applied.
+	Force(ctx context.Context, version int) error
+}
+
+// Migration describes a single up migration.
+type Migration struct {
+	Version uint
+	Name    string
+	SQL     string
+}
+
+// MigrationStatus describes the state of the database schema.
+type MigrationStatus struct {
+	// Version is the last applied migration version, 0 if nothing is applied.
+	Version uint
+	// Dirty indicates that the last migration failed and the schema has to be fixed manually.
+	Dirty bool
+	// Pending lists migrations that are not applied yet.
+	Pending []Migration
+}
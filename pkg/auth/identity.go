@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"context"
+	"crypto/x509"
+)
+
+// Identity describes an authenticated client.
+type Identity struct {
+	// Subject is the distinguished name of the client certificate, e.g. "CN=billing,O=Example".
+	Subject    string
+	CommonName string
+	// DNSNames and URIs are subject alternative names of the client certificate.
+	DNSNames     []string
+	URIs         []string
+	SerialNumber string
+	// Issuer is the distinguished name of the CA which issued the client certificate.
+	Issuer string
+}
+
+// IdentityFromCertificate returns the identity of a verified client certificate.
+func IdentityFromCertificate(cert *x509.Certificate) Identity {
+	identity := Identity{
+		Subject:      cert.Subject.String(),
+		CommonName:   cert.Subject.CommonName,
+		DNSNames:     cert.DNSNames,
+		SerialNumber: cert.SerialNumber.String(),
+		Issuer:       cert.Issuer.String(),
+	}
+	for _, uri := range cert.URIs {
+		identity.URIs = append(identity.URIs, uri.String())
+	}
+	return identity
+}
+
+// identityKey is the context key of the identity.
+type identityKey struct{}
+
+// WithIdentity returns a copy of the context with the identity.
+func WithIdentity(ctx context.Context, identity Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// IdentityFromContext returns the identity stored in the context, ok is false for anonymous requests.
+func IdentityFromContext(ctx context.Context) (identity Identity, ok bool) {
+	identity, ok = ctx.Value(identityKey{}).(Identity)
+	return identity, ok
+}
@@ -0,0 +1,19 @@
+package backoff
+
+import (
+	"time"
+)
+
+// Exponential returns delay before the given attempt, starting from 1: min is doubled
+// after every attempt and is never greater than max.
+func Exponential(attempt int, min, max time.Duration) time.Duration {
+	delay := min
+	for i := 1; i < attempt && delay < max; i++ {
+		delay *= 2
+	}
+
+	if delay > max {
+		return max
+	}
+	return delay
+}
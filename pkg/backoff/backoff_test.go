@@ -0,0 +1,21 @@
+package backoff_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"project_template/pkg/backoff"
+)
+
+func TestExponential(t *testing.T) {
+	min, max := time.Second, time.Minute
+
+	require.Equal(t, time.Second, backoff.Exponential(0, min, max))
+	require.Equal(t, time.Second, backoff.Exponential(1, min, max))
+	require.Equal(t, 2*time.Second, backoff.Exponential(2, min, max))
+	require.Equal(t, 32*time.Second, backoff.Exponential(6, min, max))
+	require.Equal(t, time.Minute, backoff.Exponential(7, min, max))
+	require.Equal(t, time.Minute, backoff.Exponential(1000, min, max))
+}
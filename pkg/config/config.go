@@ -5,17 +5,9 @@ import (
 	"github.com/go-playground/validator/v10"
 )
 
-// Config contains configurable values for the migration mechanism.
-//type Config struct {
-//	// DB config
-//	Database string `env:"DB_CONNECTION_STRING" validate:"required"`
-//	MigrationsPath   string `env:"DB_MIGRATIONS_PATH" validate:"required"`
+// ReadConfig reads & validates config.
 //
-//	// Server config
-//	ConsoleServerAddress string `env:"CONSOLE_SERVER_ADDRESS" validate:"required"`
-//}
-
-// ReadConfig reads & validates config
+// Values of the `envDefault` tag are used for env. variables that are not set.
 func ReadConfig(v interface{}) error {
 	err := env.ParseWithFuncs(v, nil)
 	if err != nil {
@@ -0,0 +1,74 @@
+package config_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"project_template/pkg/config"
+)
+
+func TestReadConfig(t *testing.T) {
+	type nested struct {
+		Address string `env:"CONFIG_TEST_ADDRESS" validate:"required" envDefault:"localhost:80" desc:"address"`
+	}
+
+	var cfg struct {
+		Name   string `env:"CONFIG_TEST_NAME" validate:"required" desc:"name"`
+		Nested nested
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		t.Setenv("CONFIG_TEST_NAME", "name")
+
+		err := config.ReadConfig(&cfg)
+		require.NoError(t, err)
+		require.Equal(t, "name", cfg.Name)
+		require.Equal(t, "localhost:80", cfg.Nested.Address)
+	})
+
+	t.Run("override default", func(t *testing.T) {
+		t.Setenv("CONFIG_TEST_NAME", "name")
+		t.Setenv("CONFIG_TEST_ADDRESS", "localhost:90")
+
+		err := config.ReadConfig(&cfg)
+		require.NoError(t, err)
+		require.Equal(t, "localhost:90", cfg.Nested.Address)
+	})
+
+	t.Run("fields", func(t *testing.T) {
+		fields := config.Fields(cfg)
+		require.Equal(t, []config.Field{
+			{Env: "CONFIG_TEST_NAME", Type: "string", Required: true, Description: "name"},
+			{Env: "CONFIG_TEST_ADDRESS", Type: "string", Required: true, Default: "localhost:80", Description: "address"},
+		}, fields)
+	})
+}
+
+func TestValues(t *testing.T) {
+	type nested struct {
+		Timeout time.Duration `env:"CONFIG_TEST_TIMEOUT"`
+	}
+
+	cfg := struct {
+		Name     string   `env:"CONFIG_TEST_NAME"`
+		Password string   `env:"CONFIG_TEST_PASSWORD" secret:"true"`
+		Token    string   `env:"CONFIG_TEST_TOKEN" secret:"true"`
+		Origins  []string `env:"CONFIG_TEST_ORIGINS"`
+		Nested   nested
+	}{
+		Name:     "name",
+		Password: "hunter2",
+		Origins:  []string{"https://a.test", "https://b.test"},
+		Nested:   nested{Timeout: time.Minute},
+	}
+
+	require.Equal(t, []config.Value{
+		{Env: "CONFIG_TEST_NAME", Value: "name"},
+		{Env: "CONFIG_TEST_PASSWORD", Value: config.Redacted},
+		{Env: "CONFIG_TEST_TOKEN", Value: ""},
+		{Env: "CONFIG_TEST_ORIGINS", Value: "https://a.test,https://b.test"},
+		{Env: "CONFIG_TEST_TIMEOUT", Value: "1m0s"},
+	}, config.Values(&cfg))
+}
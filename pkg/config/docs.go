@@ -0,0 +1,164 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Field describes a single config value read from an env. variable.
+type Field struct {
+	Env         string
+	Type        string
+	Required    bool
+	Default     string
+	Description string
+}
+
+// Section is a titled group of config values, built from a config struct.
+type Section struct {
+	Title  string
+	Config interface{}
+}
+
+// Fields returns all the env. variables described by the `env`, `validate`,
+// `desc` and `envDefault` tags of the given config struct, including nested ones.
+func Fields(v interface{}) []Field {
+	typ := reflect.TypeOf(v)
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var fields []Field
+	collectFields(typ, &fields)
+
+	return fields
+}
+
+// collectFields walks struct type recursively and appends described fields.
+func collectFields(typ reflect.Type, fields *[]Field) {
+	for i := 0; i < typ.NumField(); i++ {
+		structField := typ.Field(i)
+		if !structField.IsExported() {
+			continue
+		}
+
+		key := strings.Split(structField.Tag.Get("env"), ",")[0]
+		if key == "" {
+			fieldType := structField.Type
+			if fieldType.Kind() == reflect.Ptr {
+				fieldType = fieldType.Elem()
+			}
+			if fieldType.Kind() == reflect.Struct && fieldType != reflect.TypeOf(time.Time{}) {
+				collectFields(fieldType, fields)
+			}
+			continue
+		}
+
+		*fields = append(*fields, Field{
+			Env:         key,
+			Type:        structField.Type.String(),
+			Required:    isRequired(structField.Tag.Get("validate")),
+			Default:     structField.Tag.Get("envDefault"),
+			Description: structField.Tag.Get("desc"),
+		})
+	}
+}
+
+// isRequired checks if validation rules contain the "required" rule.
+func isRequired(rules string) bool {
+	for _, rule := range strings.Split(rules, ",") {
+		if rule == "required" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WriteMarkdown writes a markdown table of config values for every section.
+// Env. variables that were already described in previous sections are skipped.
+func WriteMarkdown(w io.Writer, sections ...Section) error {
+	var b strings.Builder
+
+	b.WriteString("# Configuration\n\n")
+	b.WriteString("<!-- Code generated by `template_project config docs`. DO NOT EDIT. -->\n")
+
+	seen := make(map[string]bool)
+	for _, section := range sections {
+		fields := unseenFields(section, seen)
+		if len(fields) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(&b, "\n## %s\n\n", section.Title)
+		b.WriteString("| Variable | Type | Required | Default | Description |\n")
+		b.WriteString("|----------|------|----------|---------|-------------|\n")
+		for _, field := range fields {
+			required := "no"
+			if field.Required {
+				required = "yes"
+			}
+
+			def := ""
+			if field.Default != "" {
+				def = "`" + field.Default + "`"
+			}
+
+			fmt.Fprintf(&b, "| `%s` | %s | %s | %s | %s |\n", field.Env, field.Type, required, def, field.Description)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteEnvDist writes a sample .env file with default values for every section.
+// Env. variables that were already described in previous sections are skipped.
+func WriteEnvDist(w io.Writer, sections ...Section) error {
+	var b strings.Builder
+
+	b.WriteString("# Code generated by `template_project config docs`. DO NOT EDIT.\n")
+
+	seen := make(map[string]bool)
+	for _, section := range sections {
+		fields := unseenFields(section, seen)
+		if len(fields) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(&b, "\n# %s\n", section.Title)
+		for _, field := range fields {
+			if field.Description != "" {
+				fmt.Fprintf(&b, "# %s\n", field.Description)
+			}
+			if field.Required && field.Default == "" {
+				b.WriteString("# (required)\n")
+			}
+			fmt.Fprintf(&b, "%s=%s\n", field.Env, field.Default)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// unseenFields returns section fields which are not in seen, and marks them as seen.
+func unseenFields(section Section, seen map[string]bool) []Field {
+	var fields []Field
+	for _, field := range Fields(section.Config) {
+		if seen[field.Env] {
+			continue
+		}
+
+		seen[field.Env] = true
+		fields = append(fields, field)
+	}
+
+	return fields
+}
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Redacted replaces values of secret fields.
+const Redacted = "[redacted]"
+
+// Value is a value of a config env. variable.
+type Value struct {
+	Env   string `json:"env"`
+	Value string `json:"value"`
+}
+
+// Values returns values of all the env. variables of the given config struct, including nested ones.
+// Values of fields with the `secret:"true"` tag are replaced with Redacted, unless they are empty.
+func Values(v interface{}) []Value {
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var values []Value
+	collectValues(value, &values)
+
+	return values
+}
+
+// collectValues walks struct value recursively and appends values of described fields.
+func collectValues(value reflect.Value, values *[]Value) {
+	typ := value.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		structField := typ.Field(i)
+		if !structField.IsExported() {
+			continue
+		}
+
+		fieldValue := value.Field(i)
+		key := strings.Split(structField.Tag.Get("env"), ",")[0]
+		if key == "" {
+			if fieldValue.Kind() == reflect.Ptr {
+				if fieldValue.IsNil() {
+					continue
+				}
+				fieldValue = fieldValue.Elem()
+			}
+			if fieldValue.Kind() == reflect.Struct && fieldValue.Type() != reflect.TypeOf(time.Time{}) {
+				collectValues(fieldValue, values)
+			}
+			continue
+		}
+
+		formatted := formatValue(fieldValue)
+		if structField.Tag.Get("secret") == "true" && !fieldValue.IsZero() {
+			formatted = Redacted
+		}
+
+		*values = append(*values, Value{Env: key, Value: formatted})
+	}
+}
+
+// formatValue formats the value as it's set in env., slices are comma separated.
+func formatValue(value reflect.Value) string {
+	if value.Kind() != reflect.Slice {
+		return fmt.Sprint(value.Interface())
+	}
+
+	items := make([]string, 0, value.Len())
+	for i := 0; i < value.Len(); i++ {
+		items = append(items, fmt.Sprint(value.Index(i).Interface()))
+	}
+	return strings.Join(items, ",")
+}
@@ -119,3 +119,13 @@ func CreateFile(path, fName string) error {
 	_ = f.Close()
 	return nil
 }
+
+// WriteFile creates a new file in path with given content.
+func WriteFile(path, fName string, data []byte) error {
+	name := path
+	if name[len(name)-1:] != "/" {
+		name += "/"
+	}
+
+	return os.WriteFile(name+fName, data, 0644)
+}
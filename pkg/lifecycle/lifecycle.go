@@ -0,0 +1,171 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zeebo/errs"
+	"golang.org/x/sync/errgroup"
+
+	"project_template/pkg/logger"
+)
+
+// Error indicates that components can't be ordered, e.g. a dependency is missing or they depend on each other.
+var Error = errs.Class("lifecycle error")
+
+// Item is a component of the application.
+type Item struct {
+	// Name identifies the component in logs and dependencies of other components.
+	Name string
+	// Deps are names of components which are closed after this one. Components are started at once
+	// without waiting for dependencies to be ready, so Run must not rely on them running.
+	Deps []string
+	// Run runs the component until the context is canceled, it's optional.
+	Run func(ctx context.Context) error
+	// Close releases resources of the component, it's optional.
+	Close func() error
+}
+
+// Group runs components and closes them in reverse order of their dependencies.
+type Group struct {
+	log   logger.Logger
+	items []Item
+}
+
+// NewGroup is a constructor for the group of components.
+func NewGroup(log logger.Logger) *Group {
+	return &Group{log: log}
+}
+
+// Add adds a component, its dependencies can be added later.
+func (group *Group) Add(item Item) {
+	group.items = append(group.items, item)
+}
+
+// Run starts all components with the errgroup without waiting for them to be ready, it returns an error without
+// starting anything if components can't be ordered. Cancellation errors of components are ignored.
+func (group *Group) Run(ctx context.Context, g *errgroup.Group) error {
+	items, err := group.sorted()
+	if err != nil {
+		return err
+	}
+
+	started := time.Now()
+	for _, item := range items {
+		if item.Run == nil {
+			continue
+		}
+
+		item := item
+		group.log.Debug(fmt.Sprintf("starting %s", item.Name))
+
+		g.Go(func() error {
+			start := time.Now()
+			err := item.Run(ctx)
+			if errors.Is(err, context.Canceled) {
+				err = nil
+			}
+
+			if err != nil {
+				group.log.Error(fmt.Sprintf("%s failed after %s", item.Name, time.Since(start)), err)
+				return err
+			}
+
+			group.log.Debug(fmt.Sprintf("%s stopped after %s", item.Name, time.Since(start)))
+			return nil
+		})
+	}
+	group.log.Debug(fmt.Sprintf("started %d components in %s", len(items), time.Since(started)))
+
+	return nil
+}
+
+// Close closes components in reverse order of their dependencies, every component is closed even if others fail.
+// If components can't be ordered, they are closed in reverse order of adding.
+func (group *Group) Close() error {
+	items, err := group.sorted()
+	if err != nil {
+		items = group.items
+	}
+
+	var errlist errs.Group
+	errlist.Add(err)
+
+	started := time.Now()
+	for i := len(items) - 1; i >= 0; i-- {
+		item := items[i]
+		if item.Close == nil {
+			continue
+		}
+
+		start := time.Now()
+		if err := item.Close(); err != nil {
+			group.log.Error(fmt.Sprintf("could not close %s", item.Name), err)
+			errlist.Add(err)
+			continue
+		}
+		group.log.Debug(fmt.Sprintf("closed %s in %s", item.Name, time.Since(start)))
+	}
+	group.log.Debug(fmt.Sprintf("closed %d components in %s", len(items), time.Since(started)))
+
+	return errlist.Err()
+}
+
+// sorted returns items ordered so that dependencies go first, otherwise items keep the order of adding.
+func (group *Group) sorted() ([]Item, error) {
+	byName := make(map[string]int, len(group.items))
+	for i, item := range group.items {
+		if _, ok := byName[item.Name]; ok {
+			return nil, Error.New("%s is added twice", item.Name)
+		}
+		byName[item.Name] = i
+	}
+
+	for _, item := range group.items {
+		for _, dep := range item.Deps {
+			if _, ok := byName[dep]; !ok {
+				return nil, Error.New("%s depends on unknown %s", item.Name, dep)
+			}
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(group.items))
+	sorted := make([]Item, 0, len(group.items))
+
+	var visit func(i int, path []string) error
+	visit = func(i int, path []string) error {
+		item := group.items[i]
+		switch state[i] {
+		case visited:
+			return nil
+		case visiting:
+			return Error.New("dependency cycle: %s", strings.Join(append(path, item.Name), " -> "))
+		}
+
+		state[i] = visiting
+		for _, dep := range item.Deps {
+			if err := visit(byName[dep], append(path, item.Name)); err != nil {
+				return err
+			}
+		}
+		state[i] = visited
+
+		sorted = append(sorted, item)
+		return nil
+	}
+
+	for i := range group.items {
+		if err := visit(i, nil); err != nil {
+			return nil, err
+		}
+	}
+	return sorted, nil
+}
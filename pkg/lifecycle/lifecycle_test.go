@@ -0,0 +1,98 @@
+package lifecycle_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zeebo/errs"
+	"golang.org/x/sync/errgroup"
+
+	"project_template/pkg/lifecycle"
+	"project_template/pkg/logger/zaplog"
+)
+
+func TestGroup(t *testing.T) {
+	var mu sync.Mutex
+	var started, closed []string
+
+	item := func(name string, deps ...string) lifecycle.Item {
+		return lifecycle.Item{
+			Name: name,
+			Deps: deps,
+			Run: func(ctx context.Context) error {
+				mu.Lock()
+				started = append(started, name)
+				mu.Unlock()
+
+				<-ctx.Done()
+				return ctx.Err()
+			},
+			Close: func() error {
+				closed = append(closed, name)
+				return nil
+			},
+		}
+	}
+
+	group := lifecycle.NewGroup(zaplog.NewLog())
+	group.Add(item("server", "stream", "scheduler"))
+	group.Add(item("scheduler", "worker"))
+	group.Add(item("stream"))
+	group.Add(item("worker"))
+	group.Add(lifecycle.Item{Name: "no run", Close: func() error {
+		closed = append(closed, "no run")
+		return errs.New("close failed")
+	}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	g, ctx := errgroup.WithContext(ctx)
+	require.NoError(t, group.Run(ctx, g))
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(started) == 4
+	}, 5*time.Second, time.Millisecond)
+
+	cancel()
+	require.NoError(t, g.Wait(), "cancellation is not an error")
+
+	err := group.Close()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "close failed")
+	require.Equal(t, []string{"no run", "server", "scheduler", "worker", "stream"}, closed)
+}
+
+func TestGroupOrder(t *testing.T) {
+	var closed []string
+	item := func(name string, deps ...string) lifecycle.Item {
+		return lifecycle.Item{Name: name, Deps: deps, Close: func() error {
+			closed = append(closed, name)
+			return nil
+		}}
+	}
+
+	for _, test := range []struct {
+		name  string
+		items []lifecycle.Item
+	}{
+		{"unknown dependency", []lifecycle.Item{item("a", "b")}},
+		{"duplicate", []lifecycle.Item{item("a"), item("a")}},
+		{"cycle", []lifecycle.Item{item("a", "b"), item("b", "c"), item("c", "a")}},
+	} {
+		group := lifecycle.NewGroup(zaplog.NewLog())
+		for _, item := range test.items {
+			group.Add(item)
+		}
+
+		var g errgroup.Group
+		require.True(t, lifecycle.Error.Has(group.Run(context.Background(), &g)), test.name)
+
+		closed = nil
+		require.True(t, lifecycle.Error.Has(group.Close()), test.name)
+		require.Len(t, closed, len(test.items), "all items are closed anyway")
+	}
+}
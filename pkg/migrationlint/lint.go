@@ -0,0 +1,336 @@
+package migrationlint
+
+import (
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/zeebo/errs"
+)
+
+// Error is the default migrationlint error class.
+var Error = errs.Class("migration lint error")
+
+// Rules checked by the linter. Rules checking file content can be suppressed with
+// a comment "-- lint:ignore <rule>" right before the statement, or with
+// "-- lint:ignore-file <rule>" anywhere in the file.
+const (
+	// RuleNaming reports files that don't follow "{version}_{title}.{up|down}.sql" format.
+	RuleNaming = "naming"
+	// RulePairing reports up migrations without down ones and vice versa.
+	RulePairing = "pairing"
+	// RuleDuplicate reports different migrations with the same version.
+	RuleDuplicate = "duplicate"
+	// RuleGap reports missing versions in sequential numbering, timestamp versions are not checked.
+	RuleGap = "gap"
+	// RuleEmpty warns about files without sql statements, e.g. created by create-migration and not filled in yet,
+	// they are applied as no-ops.
+	RuleEmpty = "empty"
+	// RuleLockTable reports explicit table locks.
+	RuleLockTable = "lock-table"
+	// RuleIndexConcurrently reports index creation that blocks writes to the table. Indexes of tables
+	// created by the same migration are not reported, since they are built on empty tables.
+	RuleIndexConcurrently = "index-concurrently"
+	// RuleNotNullWithoutDefault reports adding of NOT NULL columns without a default value.
+	RuleNotNullWithoutDefault = "not-null-without-default"
+	// RuleSetNotNull reports adding of NOT NULL constraint, which scans the whole table under lock.
+	RuleSetNotNull = "set-not-null"
+	// RuleAlterColumnType reports column type changes, which rewrite the whole table under lock.
+	RuleAlterColumnType = "alter-column-type"
+	// RuleDropColumn reports dropping of columns, which breaks running application replicas.
+	RuleDropColumn = "drop-column"
+	// RuleDropTable reports dropping of tables in up migrations.
+	RuleDropTable = "drop-table"
+)
+
+// Issue is a single problem found in migrations.
+type Issue struct {
+	File    string
+	Line    int
+	Rule    string
+	Message string
+	// Warning issues are reported, but don't fail linting.
+	Warning bool
+}
+
+// String returns human readable representation of the issue.
+func (issue Issue) String() string {
+	location := issue.File
+	if issue.Line > 0 {
+		location += ":" + strconv.Itoa(issue.Line)
+	}
+
+	if issue.Warning {
+		return fmt.Sprintf("%s: warning: %s (%s)", location, issue.Message, issue.Rule)
+	}
+	return fmt.Sprintf("%s: %s (%s)", location, issue.Message, issue.Rule)
+}
+
+// Direction defines whether migration file applies or rolls back changes.
+type Direction string
+
+const (
+	// Up is the direction of migration that applies changes.
+	Up Direction = "up"
+	// Down is the direction of migration that rolls back changes.
+	Down Direction = "down"
+)
+
+// File describes a migration file name.
+type File struct {
+	Name      string
+	Version   uint64
+	Title     string
+	Direction Direction
+}
+
+// nameExpr matches migration file names.
+var nameExpr = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// titleExpr matches valid migration titles.
+var titleExpr = regexp.MustCompile(`^\w+$`)
+
+// TimestampFormat is the layout of timestamp based migration versions.
+const TimestampFormat = "20060102150405"
+
+// minTimestampVersion is the smallest version treated as timestamp based.
+const minTimestampVersion = 19700101000000
+
+// IsTimestamp checks if version is timestamp based rather than sequential.
+func IsTimestamp(version uint64) bool {
+	return version >= minTimestampVersion
+}
+
+// ValidTitle checks if title can be used in migration file name.
+func ValidTitle(title string) bool {
+	return titleExpr.MatchString(title)
+}
+
+// ParseName parses migration file name, returns false if name has invalid format.
+func ParseName(name string) (File, bool) {
+	match := nameExpr.FindStringSubmatch(name)
+	if match == nil {
+		return File{}, false
+	}
+
+	version, err := strconv.ParseUint(match[1], 10, 64)
+	if err != nil {
+		return File{}, false
+	}
+
+	return File{
+		Name:      name,
+		Version:   version,
+		Title:     match[2],
+		Direction: Direction(match[3]),
+	}, true
+}
+
+// Files returns all valid migration files in the root of fsys, sorted by version,
+// and issues for files with invalid names.
+func Files(fsys fs.FS) ([]File, []Issue, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, nil, Error.Wrap(err)
+	}
+
+	var (
+		files  []File
+		issues []Issue
+	)
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+
+		file, ok := ParseName(entry.Name())
+		if !ok {
+			issues = append(issues, Issue{
+				File:    entry.Name(),
+				Rule:    RuleNaming,
+				Message: `file name should have "{version}_{title}.up.sql" or "{version}_{title}.down.sql" format`,
+			})
+			continue
+		}
+
+		files = append(files, file)
+	}
+
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].Version < files[j].Version
+	})
+
+	return files, issues, nil
+}
+
+// Lint checks migration files in the root of fsys.
+func Lint(fsys fs.FS) ([]Issue, error) {
+	files, issues, err := Files(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	issues = append(issues, lintVersions(files)...)
+
+	for _, file := range files {
+		content, err := fs.ReadFile(fsys, file.Name)
+		if err != nil {
+			return nil, Error.Wrap(err)
+		}
+
+		issues = append(issues, LintFile(file, string(content))...)
+	}
+
+	return issues, nil
+}
+
+// lintVersions checks pairing, duplicates and gaps of migration versions.
+func lintVersions(files []File) []Issue {
+	var issues []Issue
+
+	type pair struct {
+		up, down []File
+	}
+
+	pairs := make(map[uint64]*pair)
+	var versions []uint64
+	for _, file := range files {
+		p, ok := pairs[file.Version]
+		if !ok {
+			p = &pair{}
+			pairs[file.Version] = p
+			versions = append(versions, file.Version)
+		}
+
+		if file.Direction == Up {
+			p.up = append(p.up, file)
+		} else {
+			p.down = append(p.down, file)
+		}
+	}
+
+	for _, version := range versions {
+		p := pairs[version]
+
+		if len(p.up) > 1 || len(p.down) > 1 || (len(p.up) == 1 && len(p.down) == 1 && p.up[0].Title != p.down[0].Title) {
+			for _, file := range append(p.up, p.down...) {
+				issues = append(issues, Issue{
+					File:    file.Name,
+					Rule:    RuleDuplicate,
+					Message: fmt.Sprintf("version %d is used by several migrations", version),
+				})
+			}
+			continue
+		}
+
+		switch {
+		case len(p.down) == 0:
+			issues = append(issues, Issue{File: p.up[0].Name, Rule: RulePairing, Message: "down migration is missing"})
+		case len(p.up) == 0:
+			issues = append(issues, Issue{File: p.down[0].Name, Rule: RulePairing, Message: "up migration is missing"})
+		}
+	}
+
+	for i := 1; i < len(versions); i++ {
+		if IsTimestamp(versions[i]) {
+			break
+		}
+
+		if versions[i] != versions[i-1]+1 {
+			p := pairs[versions[i]]
+			issues = append(issues, Issue{
+				File:    append(p.up, p.down...)[0].Name,
+				Rule:    RuleGap,
+				Message: fmt.Sprintf("versions between %d and %d are missing", versions[i-1], versions[i]),
+			})
+		}
+	}
+
+	return issues
+}
+
+// alterTableExpr matches ALTER TABLE statement and captures the list of its actions.
+var alterTableExpr = regexp.MustCompile(`^ALTER TABLE (?:IF EXISTS )?(?:ONLY )?(?:"[^"]+"|[^ ]+) (.*)$`)
+
+// ddl rules applied to every statement of up migrations.
+var (
+	lockTableExpr   = regexp.MustCompile(`^LOCK\b`)
+	createIndexExpr = regexp.MustCompile(`^CREATE (?:UNIQUE )?INDEX\b`)
+	createTableExpr = regexp.MustCompile(`^CREATE (?:UNLOGGED )?TABLE (?:IF NOT EXISTS )?("[^"]+"|[^ (]+)`)
+	indexTableExpr  = regexp.MustCompile(` ON (?:ONLY )?("[^"]+"|[^ (]+)`)
+	dropTableExpr   = regexp.MustCompile(`^DROP TABLE\b`)
+
+	addColumnExpr       = regexp.MustCompile(`^ADD (?:COLUMN )?`)
+	addConstraintExpr   = regexp.MustCompile(`^ADD (?:CONSTRAINT|PRIMARY|UNIQUE|FOREIGN|CHECK|EXCLUDE)\b`)
+	setNotNullExpr      = regexp.MustCompile(`^ALTER (?:COLUMN )?(?:"[^"]+"|[^ ]+) SET NOT NULL\b`)
+	alterColumnTypeExpr = regexp.MustCompile(`^ALTER (?:COLUMN )?(?:"[^"]+"|[^ ]+) (?:SET DATA )?TYPE\b`)
+	dropColumnExpr      = regexp.MustCompile(`^DROP (?:COLUMN )?`)
+	dropConstraintExpr  = regexp.MustCompile(`^DROP CONSTRAINT\b`)
+)
+
+// LintFile checks content of a single migration file.
+func LintFile(file File, content string) []Issue {
+	statements, ignoredInFile := splitStatements(content)
+
+	var issues []Issue
+	report := func(stmt statement, rule, message string) {
+		if ignoredInFile[rule] || stmt.Ignored[rule] {
+			return
+		}
+		issues = append(issues, Issue{File: file.Name, Line: stmt.Line, Rule: rule, Message: message})
+	}
+
+	if len(statements) == 0 {
+		if !ignoredInFile[RuleEmpty] {
+			issues = append(issues, Issue{File: file.Name, Rule: RuleEmpty, Message: "migration has no sql statements", Warning: true})
+		}
+		return issues
+	}
+
+	if file.Direction != Up {
+		return issues
+	}
+
+	created := make(map[string]bool)
+	for _, stmt := range statements {
+		switch {
+		case createTableExpr.MatchString(stmt.SQL):
+			created[strings.Trim(createTableExpr.FindStringSubmatch(stmt.SQL)[1], `"`)] = true
+		case lockTableExpr.MatchString(stmt.SQL):
+			report(stmt, RuleLockTable, "explicit table lock blocks all queries to the table")
+		case createIndexExpr.MatchString(stmt.SQL) && !strings.Contains(stmt.SQL, " CONCURRENTLY "):
+			if match := indexTableExpr.FindStringSubmatch(stmt.SQL); match != nil && created[strings.Trim(match[1], `"`)] {
+				continue
+			}
+			report(stmt, RuleIndexConcurrently, "index should be created CONCURRENTLY to not block writes")
+		case dropTableExpr.MatchString(stmt.SQL):
+			report(stmt, RuleDropTable, "dropping a table breaks running application replicas")
+		}
+
+		match := alterTableExpr.FindStringSubmatch(stmt.SQL)
+		if match == nil {
+			continue
+		}
+
+		for _, clause := range splitClauses(match[1]) {
+			switch {
+			case addColumnExpr.MatchString(clause) && !addConstraintExpr.MatchString(clause):
+				if strings.Contains(clause, "NOT NULL") && !strings.Contains(clause, "DEFAULT") {
+					report(stmt, RuleNotNullWithoutDefault, "NOT NULL column without default fails on non-empty tables")
+				}
+			case setNotNullExpr.MatchString(clause):
+				report(stmt, RuleSetNotNull, "SET NOT NULL scans the whole table under exclusive lock")
+			case alterColumnTypeExpr.MatchString(clause):
+				report(stmt, RuleAlterColumnType, "changing column type rewrites the whole table under exclusive lock")
+			case dropColumnExpr.MatchString(clause) && !dropConstraintExpr.MatchString(clause):
+				report(stmt, RuleDropColumn, "dropping a column breaks running application replicas")
+			}
+		}
+	}
+
+	return issues
+}
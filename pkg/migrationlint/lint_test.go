@@ -0,0 +1,104 @@
+package migrationlint_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+
+	"project_template/pkg/migrationlint"
+)
+
+func TestLint(t *testing.T) {
+	file := func(content string) *fstest.MapFile {
+		return &fstest.MapFile{Data: []byte(content)}
+	}
+
+	fsys := fstest.MapFS{
+		"000001_init.up.sql":      file("CREATE TABLE foo (id INTEGER);"),
+		"000001_init.down.sql":    file("DROP TABLE foo;"),
+		"000002_bar.up.sql":       file("ALTER TABLE foo ADD COLUMN bar INTEGER NOT NULL;"),
+		"000002_baz.down.sql":     file("ALTER TABLE foo DROP COLUMN bar;"),
+		"000004_index.up.sql":     file("CREATE INDEX foo_idx ON foo (id);"),
+		"000004_index.down.sql":   file("-- nothing to do\n"),
+		"000005_ignored.up.sql":   file("-- lint:ignore drop-column,alter-column-type\nALTER TABLE foo DROP COLUMN id, ALTER COLUMN bar TYPE TEXT;"),
+		"000005_ignored.down.sql": file("-- lint:ignore-file empty\n"),
+		"000006_up_only.up.sql":   file("LOCK TABLE foo; SELECT ';';\nALTER TABLE foo ALTER COLUMN bar SET NOT NULL;"),
+		"bad-name.sql":            file(""),
+		"README.md":               file(""),
+	}
+
+	issues, err := migrationlint.Lint(fsys)
+	require.NoError(t, err)
+
+	type issue struct {
+		File    string
+		Line    int
+		Rule    string
+		Warning bool
+	}
+
+	var actual []issue
+	for _, i := range issues {
+		actual = append(actual, issue{File: i.File, Line: i.Line, Rule: i.Rule, Warning: i.Warning})
+	}
+
+	require.ElementsMatch(t, []issue{
+		{File: "bad-name.sql", Rule: migrationlint.RuleNaming},
+		{File: "000002_bar.up.sql", Rule: migrationlint.RuleDuplicate},
+		{File: "000002_baz.down.sql", Rule: migrationlint.RuleDuplicate},
+		{File: "000006_up_only.up.sql", Rule: migrationlint.RulePairing},
+		{File: "000004_index.up.sql", Rule: migrationlint.RuleGap},
+		{File: "000002_bar.up.sql", Line: 1, Rule: migrationlint.RuleNotNullWithoutDefault},
+		{File: "000004_index.up.sql", Line: 1, Rule: migrationlint.RuleIndexConcurrently},
+		{File: "000004_index.down.sql", Rule: migrationlint.RuleEmpty, Warning: true},
+		{File: "000006_up_only.up.sql", Line: 1, Rule: migrationlint.RuleLockTable},
+		{File: "000006_up_only.up.sql", Line: 2, Rule: migrationlint.RuleSetNotNull},
+	}, actual)
+}
+
+func TestParseName(t *testing.T) {
+	file, ok := migrationlint.ParseName("000012_add_foo.down.sql")
+	require.True(t, ok)
+	require.Equal(t, migrationlint.File{
+		Name:      "000012_add_foo.down.sql",
+		Version:   12,
+		Title:     "add_foo",
+		Direction: migrationlint.Down,
+	}, file)
+
+	for _, name := range []string{"add_foo.up.sql", "000012_add-foo.up.sql", "000012_add_foo.sql", "000012_.up.sql"} {
+		_, ok = migrationlint.ParseName(name)
+		require.False(t, ok, name)
+	}
+}
+
+func TestLintTimestampVersions(t *testing.T) {
+	fsys := fstest.MapFS{
+		"000001_init.up.sql":          {Data: []byte("CREATE TABLE foo (id INTEGER);")},
+		"000001_init.down.sql":        {Data: []byte("DROP TABLE foo;")},
+		"20221019120000_bar.up.sql":   {Data: []byte("ALTER TABLE foo ADD COLUMN bar INTEGER;")},
+		"20221019120000_bar.down.sql": {Data: []byte("ALTER TABLE foo DROP COLUMN bar;")},
+		"20221020093000_baz.up.sql":   {Data: []byte("ALTER TABLE foo ADD COLUMN baz INTEGER;")},
+		"20221020093000_baz.down.sql": {Data: []byte("ALTER TABLE foo DROP COLUMN baz;")},
+	}
+
+	issues, err := migrationlint.Lint(fsys)
+	require.NoError(t, err)
+	require.Empty(t, issues)
+
+	require.False(t, migrationlint.IsTimestamp(1))
+	require.True(t, migrationlint.IsTimestamp(20221019120000))
+}
+
+func TestLintIndexOfCreatedTable(t *testing.T) {
+	file := migrationlint.File{Name: "000001_init.up.sql", Version: 1, Title: "init", Direction: migrationlint.Up}
+
+	issues := migrationlint.LintFile(file, `CREATE TABLE IF NOT EXISTS foo (id INTEGER, bar TEXT);
+CREATE INDEX IF NOT EXISTS foo_idx ON foo (id);
+CREATE UNIQUE INDEX "foo_bar_idx" ON "foo" (bar);
+CREATE INDEX baz_idx ON baz (id);`)
+	require.Len(t, issues, 1)
+	require.Equal(t, migrationlint.RuleIndexConcurrently, issues[0].Rule)
+	require.Equal(t, 4, issues[0].Line)
+}
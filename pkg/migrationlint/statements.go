@@ -0,0 +1,164 @@
+package migrationlint
+
+import (
+	"regexp"
+	"strings"
+)
+
+// statement is a single sql statement of a migration file.
+type statement struct {
+	// SQL is the statement text without comments, upper-cased and with collapsed whitespaces.
+	SQL string
+	// Line is the line number where the statement starts.
+	Line int
+	// Ignored contains rules suppressed by comments inside or right before the statement.
+	Ignored map[string]bool
+}
+
+// directiveExpr matches suppression comments, e.g. "lint:ignore drop-column,drop-table reason".
+var directiveExpr = regexp.MustCompile(`^lint:(ignore|ignore-file)\s+([\w,-]+)`)
+
+// spacesExpr matches sequences of whitespaces.
+var spacesExpr = regexp.MustCompile(`\s+`)
+
+// splitStatements splits sql into statements, taking comments, quoted strings
+// and identifiers and dollar-quoted strings into account.
+// It also returns rules suppressed for the whole file.
+func splitStatements(sql string) (statements []statement, ignoredInFile map[string]bool) {
+	ignoredInFile = make(map[string]bool)
+
+	var (
+		current strings.Builder
+		ignored = make(map[string]bool)
+		line    = 1
+		start   = 0
+	)
+
+	directive := func(comment string) {
+		match := directiveExpr.FindStringSubmatch(strings.TrimSpace(comment))
+		if match == nil {
+			return
+		}
+
+		for _, rule := range strings.Split(match[2], ",") {
+			if match[1] == "ignore-file" {
+				ignoredInFile[rule] = true
+			} else {
+				ignored[rule] = true
+			}
+		}
+	}
+
+	flush := func() {
+		text := strings.TrimSpace(spacesExpr.ReplaceAllString(current.String(), " "))
+		if text != "" {
+			statements = append(statements, statement{
+				SQL:     strings.ToUpper(text),
+				Line:    start,
+				Ignored: ignored,
+			})
+			ignored = make(map[string]bool)
+		}
+		current.Reset()
+		start = 0
+	}
+
+	write := func(s string) {
+		if start == 0 && strings.TrimSpace(s) != "" {
+			start = line
+		}
+		current.WriteString(s)
+		line += strings.Count(s, "\n")
+	}
+
+	for i := 0; i < len(sql); {
+		rest := sql[i:]
+
+		switch {
+		case strings.HasPrefix(rest, "--"):
+			end := strings.IndexByte(rest, '\n')
+			if end < 0 {
+				end = len(rest)
+			}
+			directive(rest[2:end])
+			i += end
+		case strings.HasPrefix(rest, "/*"):
+			end := strings.Index(rest, "*/")
+			if end < 0 {
+				end = len(rest)
+			} else {
+				end += 2
+			}
+			directive(strings.TrimSuffix(rest[2:end], "*/"))
+			line += strings.Count(rest[:end], "\n")
+			current.WriteString(" ")
+			i += end
+		case rest[0] == '\'' || rest[0] == '"':
+			end := strings.IndexByte(rest[1:], rest[0])
+			if end < 0 {
+				end = len(rest)
+			} else {
+				end += 2
+			}
+			write(rest[:end])
+			i += end
+		case rest[0] == '$':
+			tag := dollarTag(rest)
+			if tag == "" {
+				write(rest[:1])
+				i++
+				continue
+			}
+			end := strings.Index(rest[len(tag):], tag)
+			if end < 0 {
+				end = len(rest)
+			} else {
+				end += 2 * len(tag)
+			}
+			write(rest[:end])
+			i += end
+		case rest[0] == ';':
+			flush()
+			i++
+		default:
+			write(rest[:1])
+			i++
+		}
+	}
+	flush()
+
+	return statements, ignoredInFile
+}
+
+// dollarTagExpr matches opening tag of dollar-quoted string, e.g. "$$" or "$body$".
+var dollarTagExpr = regexp.MustCompile(`^\$[A-Za-z_]*\$`)
+
+// dollarTag returns dollar-quoted string tag if s starts with it.
+func dollarTag(s string) string {
+	return dollarTagExpr.FindString(s)
+}
+
+// splitClauses splits comma separated list, ignoring commas inside parentheses.
+func splitClauses(list string) []string {
+	var (
+		clauses []string
+		depth   int
+		start   int
+	)
+
+	for i, c := range list {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				clauses = append(clauses, strings.TrimSpace(list[start:i]))
+				start = i + 1
+			}
+		}
+	}
+
+	return append(clauses, strings.TrimSpace(list[start:]))
+}
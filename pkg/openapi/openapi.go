@@ -0,0 +1,341 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/zeebo/errs"
+)
+
+// Error is the default openapi error class.
+var Error = errs.Class("openapi error")
+
+// Document is an OpenAPI 3 document. Only the subset of the specification used by the api is supported,
+// documents with other fields are rejected by Parse, so nothing is silently left unchecked.
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Servers    []Server            `json:"servers,omitempty"`
+	Tags       []Tag               `json:"tags,omitempty"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+// Info describes the api.
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// Server is a base url of the api, paths of the document are relative to it.
+type Server struct {
+	URL         string `json:"url"`
+	Description string `json:"description,omitempty"`
+}
+
+// Tag groups operations.
+type Tag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem contains operations of a path by lowercase http methods.
+type PathItem map[string]*Operation
+
+// Operation is a single api method on a path.
+type Operation struct {
+	OperationID string               `json:"operationId"`
+	Summary     string               `json:"summary,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+	Parameters  []Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+// Parameter is a path, query or header parameter of an operation, it's either a reference to components
+// or defined in place.
+type Parameter struct {
+	Ref         string  `json:"$ref,omitempty"`
+	Name        string  `json:"name,omitempty"`
+	In          string  `json:"in,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema,omitempty"`
+}
+
+// RequestBody describes the body of a request by media types.
+type RequestBody struct {
+	Description string               `json:"description,omitempty"`
+	Required    bool                 `json:"required,omitempty"`
+	Content     map[string]MediaType `json:"content"`
+}
+
+// Response describes a response, it's either a reference to components or defined in place.
+type Response struct {
+	Ref         string               `json:"$ref,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Headers     map[string]Header    `json:"headers,omitempty"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// Header describes a response header.
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema,omitempty"`
+}
+
+// MediaType describes content of a single media type, the schema is set for json content.
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+// Components contains reusable parameters, schemas and responses.
+type Components struct {
+	Parameters map[string]*Parameter `json:"parameters,omitempty"`
+	Schemas    map[string]*Schema    `json:"schemas,omitempty"`
+	Responses  map[string]*Response  `json:"responses,omitempty"`
+}
+
+// Route is an operation of the document with the full path, including the path of the server url.
+type Route struct {
+	Method string
+	Path   string
+}
+
+// Parse parses the document and checks that its references are resolved.
+func Parse(data []byte) (*Document, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	var doc Document
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, Error.Wrap(err)
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		return nil, Error.New("unsupported openapi version %q", doc.OpenAPI)
+	}
+
+	if err := doc.checkRefs(); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// BasePath returns the path of the first server url, or an empty string if there are no servers.
+func (doc *Document) BasePath() string {
+	if len(doc.Servers) == 0 {
+		return ""
+	}
+	base, err := url.Parse(doc.Servers[0].URL)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSuffix(base.Path, "/")
+}
+
+// Routes returns all operations of the document sorted by path and method.
+func (doc *Document) Routes() []Route {
+	var routes []Route
+	for path, item := range doc.Paths {
+		for method := range item {
+			routes = append(routes, Route{Method: strings.ToUpper(method), Path: doc.BasePath() + path})
+		}
+	}
+
+	sort.Slice(routes, func(i, k int) bool {
+		if routes[i].Path != routes[k].Path {
+			return routes[i].Path < routes[k].Path
+		}
+		return routes[i].Method < routes[k].Method
+	})
+	return routes
+}
+
+// Find returns the operation which serves the method and the request path, including the path of
+// the server url. Literal path segments are preferred over parameters, e.g. "/dummy/search" over "/dummy/{id}".
+func (doc *Document) Find(method, path string) (Route, *Operation, bool) {
+	path, ok := cutPrefix(path, doc.BasePath())
+	if !ok {
+		return Route{}, nil, false
+	}
+	segments := strings.Split(path, "/")
+
+	var (
+		found    *Operation
+		template string
+		literals = -1
+	)
+	for candidate, item := range doc.Paths {
+		operation, ok := item[strings.ToLower(method)]
+		if !ok {
+			continue
+		}
+
+		matched, matchedLiterals := matchPath(strings.Split(candidate, "/"), segments)
+		if matched && matchedLiterals > literals {
+			found, template, literals = operation, candidate, matchedLiterals
+		}
+	}
+	if found == nil {
+		return Route{}, nil, false
+	}
+
+	return Route{Method: strings.ToUpper(method), Path: doc.BasePath() + template}, found, true
+}
+
+// matchPath matches segments of the path to segments of the template and returns the number of literal ones.
+func matchPath(template, path []string) (bool, int) {
+	if len(template) != len(path) {
+		return false, 0
+	}
+
+	var literals int
+	for i, segment := range template {
+		switch {
+		case strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}"):
+			if path[i] == "" {
+				return false, 0
+			}
+		case segment == path[i]:
+			literals++
+		default:
+			return false, 0
+		}
+	}
+	return true, literals
+}
+
+// cutPrefix returns the path without the prefix, "/" is returned for the prefix itself.
+func cutPrefix(path, prefix string) (string, bool) {
+	if !strings.HasPrefix(path, prefix) {
+		return "", false
+	}
+	path = path[len(prefix):]
+	if path == "" {
+		return "/", true
+	}
+	return path, strings.HasPrefix(path, "/")
+}
+
+// response returns the response of the operation to the status, resolving the reference.
+func (doc *Document) response(operation *Operation, status string) (*Response, bool) {
+	response, ok := operation.Responses[status]
+	if !ok {
+		response, ok = operation.Responses["default"]
+	}
+	if !ok {
+		return nil, false
+	}
+
+	if response.Ref != "" {
+		response, ok = doc.Components.Responses[strings.TrimPrefix(response.Ref, responsesRef)]
+	}
+	return response, ok
+}
+
+// parameter resolves the reference of the parameter, it returns nil if the reference is unresolved.
+func (doc *Document) parameter(parameter *Parameter) *Parameter {
+	if parameter.Ref == "" {
+		return parameter
+	}
+	return doc.Components.Parameters[strings.TrimPrefix(parameter.Ref, parametersRef)]
+}
+
+// Prefixes of references to components.
+const (
+	parametersRef = "#/components/parameters/"
+	schemasRef    = "#/components/schemas/"
+	responsesRef  = "#/components/responses/"
+)
+
+// schema resolves the reference of the schema, the schema itself is returned if it's not a reference.
+func (doc *Document) schema(schema *Schema) (*Schema, error) {
+	for seen := 0; schema.Ref != ""; seen++ {
+		if seen > len(doc.Components.Schemas) {
+			return nil, Error.New("circular reference %q", schema.Ref)
+		}
+
+		resolved, ok := doc.Components.Schemas[strings.TrimPrefix(schema.Ref, schemasRef)]
+		if !ok || !strings.HasPrefix(schema.Ref, schemasRef) {
+			return nil, Error.New("unresolved reference %q", schema.Ref)
+		}
+		schema = resolved
+	}
+	return schema, nil
+}
+
+// checkRefs checks that all references of operations and components are resolved.
+func (doc *Document) checkRefs() error {
+	var group errs.Group
+
+	var checkSchema func(schema *Schema)
+	checkSchema = func(schema *Schema) {
+		if schema == nil {
+			return
+		}
+		if schema.Ref != "" {
+			_, err := doc.schema(schema)
+			group.Add(err)
+			return
+		}
+		for _, property := range schema.Properties {
+			checkSchema(property)
+		}
+		checkSchema(schema.Items)
+	}
+	checkParameter := func(parameter *Parameter) {
+		if parameter.Ref != "" {
+			if _, ok := doc.Components.Parameters[strings.TrimPrefix(parameter.Ref, parametersRef)]; !ok || !strings.HasPrefix(parameter.Ref, parametersRef) {
+				group.Add(Error.New("unresolved reference %q", parameter.Ref))
+			}
+			return
+		}
+		checkSchema(parameter.Schema)
+	}
+	checkResponse := func(response *Response) {
+		if response.Ref != "" {
+			if _, ok := doc.Components.Responses[strings.TrimPrefix(response.Ref, responsesRef)]; !ok || !strings.HasPrefix(response.Ref, responsesRef) {
+				group.Add(Error.New("unresolved reference %q", response.Ref))
+			}
+			return
+		}
+		for _, header := range response.Headers {
+			checkSchema(header.Schema)
+		}
+		for _, media := range response.Content {
+			checkSchema(media.Schema)
+		}
+	}
+
+	for _, parameter := range doc.Components.Parameters {
+		checkParameter(parameter)
+	}
+	for _, schema := range doc.Components.Schemas {
+		checkSchema(schema)
+	}
+	for _, response := range doc.Components.Responses {
+		checkResponse(response)
+	}
+	for _, item := range doc.Paths {
+		for _, operation := range item {
+			for i := range operation.Parameters {
+				checkParameter(&operation.Parameters[i])
+			}
+			if operation.RequestBody != nil {
+				for _, media := range operation.RequestBody.Content {
+					checkSchema(media.Schema)
+				}
+			}
+			for _, response := range operation.Responses {
+				checkResponse(response)
+			}
+		}
+	}
+
+	return group.Err()
+}
@@ -0,0 +1,250 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"project_template/pkg/openapi"
+)
+
+const testDocument = `{
+  "openapi": "3.0.3",
+  "info": {"title": "test", "version": "0"},
+  "servers": [{"url": "/api/v0"}],
+  "paths": {
+    "/items": {
+      "get": {
+        "operationId": "listItems",
+        "parameters": [
+          {"$ref": "#/components/parameters/Limit"},
+          {"name": "q", "in": "query", "required": true, "schema": {"type": "string"}},
+          {"name": "X-Trace", "in": "header", "schema": {"type": "string"}}
+        ],
+        "responses": {
+          "200": {"description": "items", "content": {"application/json": {"schema": {"type": "array", "items": {"$ref": "#/components/schemas/Item"}}}}},
+          "500": {"$ref": "#/components/responses/Error"}
+        }
+      },
+      "post": {
+        "operationId": "createItem",
+        "requestBody": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/Item"}}}},
+        "responses": {"204": {"description": "created"}}
+      }
+    },
+    "/items/{id}": {
+      "get": {
+        "operationId": "getItem",
+        "parameters": [{"$ref": "#/components/parameters/ID"}],
+        "responses": {"200": {"description": "item", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Item"}}}}}
+      }
+    },
+    "/items/export": {
+      "get": {
+        "operationId": "exportItems",
+        "responses": {"200": {"description": "items", "content": {"text/csv": {"schema": {"type": "string"}}}}}
+      }
+    }
+  },
+  "components": {
+    "parameters": {
+      "ID": {"name": "id", "in": "path", "required": true, "schema": {"type": "string", "format": "uuid"}},
+      "Limit": {"name": "limit", "in": "query", "schema": {"type": "integer", "minimum": 1, "maximum": 100}}
+    },
+    "schemas": {
+      "Item": {
+        "type": "object",
+        "required": ["id", "kind"],
+        "properties": {
+          "id": {"type": "string", "format": "uuid"},
+          "kind": {"type": "integer", "enum": [1, 2]},
+          "price": {"type": "number", "minimum": 0},
+          "tags": {"type": "array", "items": {"type": "string"}},
+          "createdAt": {"type": "string", "format": "date-time"},
+          "deletedAt": {"type": "string", "format": "date-time", "nullable": true},
+          "extra": {"description": "anything"}
+        }
+      },
+      "Error": {"type": "object", "required": ["error"], "properties": {"error": {"type": "string"}}}
+    },
+    "responses": {
+      "Error": {"description": "error", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}}
+    }
+  }
+}`
+
+func TestParse(t *testing.T) {
+	doc, err := openapi.Parse([]byte(testDocument))
+	require.NoError(t, err)
+	require.Equal(t, "/api/v0", doc.BasePath())
+	require.Equal(t, []openapi.Route{
+		{Method: http.MethodGet, Path: "/api/v0/items"},
+		{Method: http.MethodPost, Path: "/api/v0/items"},
+		{Method: http.MethodGet, Path: "/api/v0/items/export"},
+		{Method: http.MethodGet, Path: "/api/v0/items/{id}"},
+	}, doc.Routes())
+
+	t.Run("unsupported field", func(t *testing.T) {
+		_, err := openapi.Parse([]byte(strings.Replace(testDocument, `"nullable": true`, `"oneOf": []`, 1)))
+		require.Error(t, err)
+		require.True(t, openapi.Error.Has(err))
+	})
+
+	t.Run("unresolved reference", func(t *testing.T) {
+		_, err := openapi.Parse([]byte(strings.Replace(testDocument, `"items": {"$ref": "#/components/schemas/Item"}`, `"items": {"$ref": "#/components/schemas/Missing"}`, 1)))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "Missing")
+	})
+
+	t.Run("unsupported version", func(t *testing.T) {
+		_, err := openapi.Parse([]byte(strings.Replace(testDocument, `"3.0.3"`, `"2.0"`, 1)))
+		require.Error(t, err)
+	})
+}
+
+func TestFind(t *testing.T) {
+	doc, err := openapi.Parse([]byte(testDocument))
+	require.NoError(t, err)
+
+	for _, test := range []struct {
+		method string
+		path   string
+		found  string
+	}{
+		{http.MethodGet, "/api/v0/items", "/api/v0/items"},
+		{http.MethodPost, "/api/v0/items", "/api/v0/items"},
+		{http.MethodGet, "/api/v0/items/export", "/api/v0/items/export"},
+		{http.MethodGet, "/api/v0/items/3f4b", "/api/v0/items/{id}"},
+		{http.MethodDelete, "/api/v0/items/3f4b", ""},
+		{http.MethodGet, "/api/v0/items/", ""},
+		{http.MethodGet, "/api/v0/items/3f4b/parts", ""},
+		{http.MethodGet, "/items", ""},
+		{http.MethodGet, "/api/v01/items", ""},
+	} {
+		route, operation, ok := doc.Find(test.method, test.path)
+		if test.found == "" {
+			require.False(t, ok, test.path)
+			continue
+		}
+		require.True(t, ok, test.path)
+		require.NotNil(t, operation)
+		require.Equal(t, openapi.Route{Method: test.method, Path: test.found}, route)
+	}
+}
+
+func TestValidateResponse(t *testing.T) {
+	doc, err := openapi.Parse([]byte(testDocument))
+	require.NoError(t, err)
+
+	_, list, ok := doc.Find(http.MethodGet, "/api/v0/items")
+	require.True(t, ok)
+	_, create, ok := doc.Find(http.MethodPost, "/api/v0/items")
+	require.True(t, ok)
+	_, export, ok := doc.Find(http.MethodGet, "/api/v0/items/export")
+	require.True(t, ok)
+
+	const item = `{"id": "7d444840-9dc0-11d1-b245-5ffdce74fad2", "kind": 1, "price": 9.5, "tags": ["a"], "createdAt": "2024-01-02T03:04:05.123Z", "deletedAt": null, "extra": {"any": [1]}}`
+
+	for _, test := range []struct {
+		name        string
+		operation   *openapi.Operation
+		status      int
+		contentType string
+		body        string
+		err         string
+	}{
+		{"valid", list, http.StatusOK, "application/json", "[" + item + "]", ""},
+		{"valid with charset", list, http.StatusOK, "application/json; charset=utf-8", "[]", ""},
+		{"referenced response", list, http.StatusInternalServerError, "application/json", `{"error": "failed"}`, ""},
+		{"undocumented status", list, http.StatusNotFound, "application/json", `{"error": "not found"}`, "status 404 is not documented"},
+		{"undocumented content type", list, http.StatusOK, "text/plain", "[]", `content type "text/plain" is not documented`},
+		{"invalid json", list, http.StatusOK, "application/json", "[", "invalid json body"},
+		{"null array", list, http.StatusOK, "application/json", "null", "$: expected array, got null"},
+		{"missing property", list, http.StatusOK, "application/json", `[{"id": "7d444840-9dc0-11d1-b245-5ffdce74fad2"}]`, `$[0]: required property "kind" is missing`},
+		{"undocumented property", list, http.StatusOK, "application/json", `[{"id": "7d444840-9dc0-11d1-b245-5ffdce74fad2", "kind": 1, "color": "red"}]`, `$[0]: property "color" is not documented`},
+		{"invalid uuid", list, http.StatusOK, "application/json", `[{"id": "1", "kind": 1}]`, `$[0].id: invalid uuid "1"`},
+		{"invalid date-time", list, http.StatusOK, "application/json", `[{"id": "7d444840-9dc0-11d1-b245-5ffdce74fad2", "kind": 1, "createdAt": "yesterday"}]`, `$[0].createdAt: invalid date-time`},
+		{"not in enum", list, http.StatusOK, "application/json", `[{"id": "7d444840-9dc0-11d1-b245-5ffdce74fad2", "kind": 3}]`, `$[0].kind: 3 is not one of [1 2]`},
+		{"not integer", list, http.StatusOK, "application/json", `[{"id": "7d444840-9dc0-11d1-b245-5ffdce74fad2", "kind": 1.5}]`, `$[0].kind: expected integer, got 1.5`},
+		{"less than minimum", list, http.StatusOK, "application/json", `[{"id": "7d444840-9dc0-11d1-b245-5ffdce74fad2", "kind": 1, "price": -1}]`, `$[0].price: -1 is less than 0`},
+		{"wrong item type", list, http.StatusOK, "application/json", `[{"id": "7d444840-9dc0-11d1-b245-5ffdce74fad2", "kind": 1, "tags": [1]}]`, `$[0].tags[0]: expected string, got json.Number`},
+		{"not nullable", list, http.StatusOK, "application/json", `[{"id": null, "kind": 1}]`, `$[0].id: expected string, got null`},
+		{"empty response", create, http.StatusNoContent, "application/json", "\n", ""},
+		{"content of empty response", create, http.StatusNoContent, "application/json", "{}", "documented without content"},
+		{"not json content", export, http.StatusOK, "text/csv", "id,kind\n", ""},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			err := doc.ValidateResponse(test.operation, test.status, test.contentType, []byte(test.body))
+			if test.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.True(t, openapi.Error.Has(err))
+			require.Contains(t, err.Error(), test.err)
+		})
+	}
+
+	t.Run("all mismatches", func(t *testing.T) {
+		err := doc.ValidateResponse(list, http.StatusOK, "application/json", []byte(`[{"kind": "1", "tags": {}}]`))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), `$[0]: required property "id" is missing`)
+		require.Contains(t, err.Error(), `$[0].kind: expected integer, got string`)
+		require.Contains(t, err.Error(), `$[0].tags: expected array, got map[string]interface {}`)
+	})
+}
+
+func TestValidateRequest(t *testing.T) {
+	doc, err := openapi.Parse([]byte(testDocument))
+	require.NoError(t, err)
+
+	_, create, ok := doc.Find(http.MethodPost, "/api/v0/items")
+	require.True(t, ok)
+	_, list, ok := doc.Find(http.MethodGet, "/api/v0/items")
+	require.True(t, ok)
+
+	body, err := json.Marshal(map[string]interface{}{"id": "7d444840-9dc0-11d1-b245-5ffdce74fad2", "kind": 2})
+	require.NoError(t, err)
+
+	require.NoError(t, doc.ValidateRequest(create, "application/json", body))
+	require.Error(t, doc.ValidateRequest(create, "application/json", []byte(`{"kind": 2}`)))
+	require.Error(t, doc.ValidateRequest(create, "text/csv", body))
+	require.NoError(t, doc.ValidateRequest(list, "", nil))
+	require.Error(t, doc.ValidateRequest(list, "application/json", body))
+}
+
+func TestValidateQuery(t *testing.T) {
+	doc, err := openapi.Parse([]byte(testDocument))
+	require.NoError(t, err)
+
+	_, list, ok := doc.Find(http.MethodGet, "/api/v0/items")
+	require.True(t, ok)
+	_, create, ok := doc.Find(http.MethodPost, "/api/v0/items")
+	require.True(t, ok)
+
+	require.NoError(t, doc.ValidateQuery(list, url.Values{"q": {"item"}, "limit": {"10"}}))
+	require.NoError(t, doc.ValidateQuery(list, url.Values{"q": {""}}))
+	require.NoError(t, doc.ValidateQuery(create, nil))
+
+	for _, test := range []struct {
+		query    url.Values
+		expected string
+	}{
+		{url.Values{}, `required query parameter "q" is missing`},
+		{url.Values{"q": {"item"}, "limit": {"ten"}}, "limit: expected integer, got string"},
+		{url.Values{"q": {"item"}, "limit": {"1.5"}}, "limit: expected integer, got 1.5"},
+		{url.Values{"q": {"item"}, "limit": {"0"}}, "limit: 0 is less than 1"},
+		{url.Values{"q": {"item"}, "offset": {"1"}}, `query parameter "offset" is not documented`},
+		{url.Values{"q": {"item"}, "X-Trace": {"1"}}, `query parameter "X-Trace" is not documented`},
+		{url.Values{"q": {"a", "b"}}, `query parameter "q" is repeated`},
+	} {
+		err := doc.ValidateQuery(list, test.query)
+		require.Error(t, err, test.query.Encode())
+		require.Contains(t, err.Error(), "listItems: ")
+		require.Contains(t, err.Error(), test.expected)
+	}
+}
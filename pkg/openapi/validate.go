@@ -0,0 +1,313 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zeebo/errs"
+)
+
+// Schema is a JSON schema of a value. Objects with properties are closed: properties which aren't
+// declared are reported, as the api documents every field it sends.
+type Schema struct {
+	Ref         string             `json:"$ref,omitempty"`
+	Type        string             `json:"type,omitempty"`
+	Format      string             `json:"format,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Enum        []interface{}      `json:"enum,omitempty"`
+	Nullable    bool               `json:"nullable,omitempty"`
+	Minimum     *float64           `json:"minimum,omitempty"`
+	Maximum     *float64           `json:"maximum,omitempty"`
+	Default     interface{}        `json:"default,omitempty"`
+	Example     interface{}        `json:"example,omitempty"`
+	Required    []string           `json:"required,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	Items       *Schema            `json:"items,omitempty"`
+}
+
+// ValidateResponse checks that the response of the operation is documented: its status, media type and,
+// for json content, the body. A response documented without content must have an empty body.
+func (doc *Document) ValidateResponse(operation *Operation, status int, contentType string, body []byte) error {
+	response, ok := doc.response(operation, strconv.Itoa(status))
+	if !ok {
+		return Error.New("%s: status %d is not documented", operation.OperationID, status)
+	}
+
+	if len(response.Content) == 0 {
+		if len(bytes.TrimSpace(body)) > 0 {
+			return Error.New("%s: status %d is documented without content, got %q", operation.OperationID, status, body)
+		}
+		return nil
+	}
+
+	return doc.validateContent(operation.OperationID, response.Content, contentType, body)
+}
+
+// ValidateRequest checks that the request body of the operation is documented.
+func (doc *Document) ValidateRequest(operation *Operation, contentType string, body []byte) error {
+	if operation.RequestBody == nil {
+		if len(body) > 0 {
+			return Error.New("%s: request body is not documented", operation.OperationID)
+		}
+		return nil
+	}
+
+	return doc.validateContent(operation.OperationID, operation.RequestBody.Content, contentType, body)
+}
+
+// ValidateQuery checks that query parameters of the request are documented parameters of the operation,
+// their values match the schemas and required ones are set. Repeated parameters are not supported.
+func (doc *Document) ValidateQuery(operation *Operation, query url.Values) error {
+	documented := make(map[string]*Parameter)
+	for i := range operation.Parameters {
+		parameter := doc.parameter(&operation.Parameters[i])
+		if parameter != nil && parameter.In == "query" {
+			documented[parameter.Name] = parameter
+		}
+	}
+
+	var group errs.Group
+	for _, parameter := range documented {
+		if _, ok := query[parameter.Name]; parameter.Required && !ok {
+			group.Add(errs.New("required query parameter %q is missing", parameter.Name))
+		}
+	}
+
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		parameter, ok := documented[name]
+		if !ok {
+			group.Add(errs.New("query parameter %q is not documented", name))
+			continue
+		}
+		if len(query[name]) > 1 {
+			group.Add(errs.New("query parameter %q is repeated", name))
+			continue
+		}
+		if parameter.Schema != nil {
+			doc.validate(&group, name, parameter.Schema, doc.queryValue(parameter.Schema, query.Get(name)))
+		}
+	}
+
+	if err := group.Err(); err != nil {
+		return Error.New("%s: %v", operation.OperationID, err)
+	}
+	return nil
+}
+
+// queryValue converts the query parameter to the json value of its schema type, values which
+// can't be converted are kept as strings and are reported by validation.
+func (doc *Document) queryValue(schema *Schema, value string) interface{} {
+	schema, err := doc.schema(schema)
+	if err != nil {
+		return value
+	}
+
+	switch schema.Type {
+	case "integer", "number":
+		if _, err := strconv.ParseFloat(value, 64); err == nil {
+			return json.Number(value)
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return value
+}
+
+// validateContent checks that the media type is one of the content and the body matches its schema.
+func (doc *Document) validateContent(operationID string, content map[string]MediaType, contentType string, body []byte) error {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return Error.New("%s: invalid content type %q", operationID, contentType)
+	}
+	media, ok := content[mediaType]
+	if !ok {
+		return Error.New("%s: content type %q is not documented", operationID, mediaType)
+	}
+	if media.Schema == nil || !isJSON(mediaType) {
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return Error.New("%s: invalid json body: %v", operationID, err)
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return Error.New("%s: body contains more than one json value", operationID)
+	}
+
+	var group errs.Group
+	doc.validate(&group, "$", media.Schema, value)
+	if err := group.Err(); err != nil {
+		return Error.New("%s: %v", operationID, err)
+	}
+	return nil
+}
+
+// isJSON checks if the media type is json, e.g. application/json or application/problem+json.
+func isJSON(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// Validate checks the value decoded from json with numbers as json.Number against the schema,
+// all mismatches are reported with json paths of the values.
+func (doc *Document) Validate(schema *Schema, value interface{}) error {
+	var group errs.Group
+	doc.validate(&group, "$", schema, value)
+	return Error.Wrap(group.Err())
+}
+
+// validate adds mismatches of the value at the path to the group.
+func (doc *Document) validate(group *errs.Group, path string, schema *Schema, value interface{}) {
+	mismatch := func(format string, args ...interface{}) {
+		group.Add(errs.New("%s: %s", path, fmt.Sprintf(format, args...)))
+	}
+
+	schema, err := doc.schema(schema)
+	if err != nil {
+		mismatch("%v", err)
+		return
+	}
+
+	if value == nil {
+		if !schema.Nullable && schema.Type != "" {
+			mismatch("expected %s, got null", schema.Type)
+		}
+		return
+	}
+
+	if len(schema.Enum) > 0 && !inEnum(schema.Enum, value) {
+		mismatch("%v is not one of %v", value, schema.Enum)
+	}
+
+	switch schema.Type {
+	case "":
+		// any value is allowed.
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			mismatch("expected object, got %T", value)
+			return
+		}
+
+		for _, name := range schema.Required {
+			if _, ok := object[name]; !ok {
+				mismatch("required property %q is missing", name)
+			}
+		}
+		if len(schema.Properties) == 0 {
+			return
+		}
+
+		names := make([]string, 0, len(object))
+		for name := range object {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			property, ok := schema.Properties[name]
+			if !ok {
+				mismatch("property %q is not documented", name)
+				continue
+			}
+			doc.validate(group, path+"."+name, property, object[name])
+		}
+	case "array":
+		array, ok := value.([]interface{})
+		if !ok {
+			mismatch("expected array, got %T", value)
+			return
+		}
+
+		if schema.Items != nil {
+			for i, item := range array {
+				doc.validate(group, path+"["+strconv.Itoa(i)+"]", schema.Items, item)
+			}
+		}
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			mismatch("expected string, got %T", value)
+			return
+		}
+
+		if err := checkFormat(schema.Format, s); err != nil {
+			mismatch("invalid %s %q", schema.Format, s)
+		}
+	case "integer", "number":
+		number, ok := value.(json.Number)
+		if !ok {
+			mismatch("expected %s, got %T", schema.Type, value)
+			return
+		}
+
+		f, err := number.Float64()
+		if err != nil {
+			mismatch("invalid number %s", number)
+			return
+		}
+		if _, err := number.Int64(); schema.Type == "integer" && err != nil {
+			mismatch("expected integer, got %s", number)
+		}
+		if schema.Minimum != nil && f < *schema.Minimum {
+			mismatch("%s is less than %v", number, *schema.Minimum)
+		}
+		if schema.Maximum != nil && f > *schema.Maximum {
+			mismatch("%s is greater than %v", number, *schema.Maximum)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			mismatch("expected boolean, got %T", value)
+		}
+	default:
+		mismatch("unsupported type %q", schema.Type)
+	}
+}
+
+// inEnum checks if the value equals one of the enum values, numbers are compared by value.
+func inEnum(enum []interface{}, value interface{}) bool {
+	for _, allowed := range enum {
+		if number, ok := value.(json.Number); ok {
+			if f, ok := allowed.(float64); ok && number.String() == strconv.FormatFloat(f, 'f', -1, 64) {
+				return true
+			}
+			continue
+		}
+		if allowed == value {
+			return true
+		}
+	}
+	return false
+}
+
+// checkFormat checks string formats which the api relies on, other formats are not checked.
+func checkFormat(format, s string) error {
+	switch format {
+	case "uuid":
+		_, err := uuid.Parse(s)
+		return err
+	case "date-time":
+		_, err := time.Parse(time.RFC3339Nano, s)
+		return err
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,138 @@
+package pgschema
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// Object kinds compared by Diff.
+const (
+	ObjectTable      = "table"
+	ObjectColumn     = "column"
+	ObjectIndex      = "index"
+	ObjectConstraint = "constraint"
+)
+
+// Difference describes a single schema object which differs between expected and actual schemas.
+// Expected is empty if the object is unexpected, Actual is empty if the object is missing.
+type Difference struct {
+	Object   string
+	Name     string
+	Expected string
+	Actual   string
+}
+
+// String returns human readable representation of the difference.
+func (diff Difference) String() string {
+	switch {
+	case diff.Expected == "":
+		return fmt.Sprintf("+ %s %s: %s", diff.Object, diff.Name, diff.Actual)
+	case diff.Actual == "":
+		return fmt.Sprintf("- %s %s: %s", diff.Object, diff.Name, diff.Expected)
+	default:
+		return fmt.Sprintf("~ %s %s: %s -> %s", diff.Object, diff.Name, diff.Expected, diff.Actual)
+	}
+}
+
+// Diff compares expected and actual schemas and returns differences sorted by object name.
+func Diff(expected, actual *Schema) []Difference {
+	var diffs []Difference
+
+	for _, name := range tableNames(expected, actual) {
+		expectedTable, actualTable := expected.Tables[name], actual.Tables[name]
+		switch {
+		case actualTable == nil:
+			diffs = append(diffs, Difference{Object: ObjectTable, Name: name, Expected: "exists"})
+			continue
+		case expectedTable == nil:
+			diffs = append(diffs, Difference{Object: ObjectTable, Name: name, Actual: "exists"})
+			continue
+		}
+
+		diffs = append(diffs, diffObjects(ObjectColumn, name, describeColumns(expectedTable), describeColumns(actualTable))...)
+		diffs = append(diffs, diffObjects(ObjectIndex, name, describeIndexes(expectedTable), describeIndexes(actualTable))...)
+		diffs = append(diffs, diffObjects(ObjectConstraint, name, describeConstraints(expectedTable), describeConstraints(actualTable))...)
+	}
+
+	return diffs
+}
+
+// diffObjects compares descriptions of table objects by their names.
+func diffObjects(object, table string, expected, actual map[string]string) []Difference {
+	var diffs []Difference
+
+	for _, name := range sortedKeys(expected, actual) {
+		if expected[name] != actual[name] {
+			diffs = append(diffs, Difference{
+				Object:   object,
+				Name:     table + "." + name,
+				Expected: expected[name],
+				Actual:   actual[name],
+			})
+		}
+	}
+
+	return diffs
+}
+
+// describeColumns returns column descriptions by name.
+func describeColumns(table *Table) map[string]string {
+	result := make(map[string]string, len(table.Columns))
+	for name, column := range table.Columns {
+		description := column.Type + " nullable=" + strconv.FormatBool(column.Nullable)
+		if column.Generated {
+			description += " generated=" + column.Default
+		} else if column.Default != "" {
+			description += " default=" + column.Default
+		}
+		result[name] = description
+	}
+	return result
+}
+
+// describeIndexes returns index definitions by name.
+func describeIndexes(table *Table) map[string]string {
+	result := make(map[string]string, len(table.Indexes))
+	for name, index := range table.Indexes {
+		result[name] = index.Definition
+	}
+	return result
+}
+
+// describeConstraints returns constraint definitions by name.
+func describeConstraints(table *Table) map[string]string {
+	result := make(map[string]string, len(table.Constraints))
+	for name, constraint := range table.Constraints {
+		result[name] = constraint.Definition
+	}
+	return result
+}
+
+// tableNames returns sorted names of tables from both schemas.
+func tableNames(expected, actual *Schema) []string {
+	names := make(map[string]string)
+	for name := range expected.Tables {
+		names[name] = name
+	}
+	for name := range actual.Tables {
+		names[name] = name
+	}
+	return sortedKeys(names)
+}
+
+// sortedKeys returns sorted union of keys of the maps.
+func sortedKeys(maps ...map[string]string) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, m := range maps {
+		for key := range m {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
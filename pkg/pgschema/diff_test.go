@@ -0,0 +1,78 @@
+package pgschema_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"project_template/pkg/pgschema"
+)
+
+func TestDiff(t *testing.T) {
+	table := func(name string, columns ...pgschema.Column) *pgschema.Table {
+		t := &pgschema.Table{
+			Name:        name,
+			Columns:     make(map[string]pgschema.Column),
+			Indexes:     make(map[string]pgschema.Index),
+			Constraints: make(map[string]pgschema.Constraint),
+		}
+		for _, column := range columns {
+			t.Columns[column.Name] = column
+		}
+		return t
+	}
+
+	expected := &pgschema.Schema{Tables: map[string]*pgschema.Table{
+		"dummy": table("dummy",
+			pgschema.Column{Name: "id", Type: "bytea"},
+			pgschema.Column{Name: "title", Type: "character varying", Nullable: true},
+		),
+		"removed": table("removed"),
+	}}
+	expected.Tables["dummy"].Indexes["dummy_pkey"] = pgschema.Index{Name: "dummy_pkey", Definition: "CREATE UNIQUE INDEX dummy_pkey ON dummy USING btree (id)"}
+
+	actual := &pgschema.Schema{Tables: map[string]*pgschema.Table{
+		"dummy": table("dummy",
+			pgschema.Column{Name: "id", Type: "bytea"},
+			pgschema.Column{Name: "title", Type: "text", Nullable: true},
+			pgschema.Column{Name: "hotfix", Type: "integer", Default: "0"},
+		),
+		"added": table("added"),
+	}}
+
+	require.Empty(t, pgschema.Diff(expected, expected))
+	require.Equal(t, []pgschema.Difference{
+		{Object: pgschema.ObjectTable, Name: "added", Actual: "exists"},
+		{Object: pgschema.ObjectColumn, Name: "dummy.hotfix", Actual: "integer nullable=false default=0"},
+		{Object: pgschema.ObjectColumn, Name: "dummy.title", Expected: "character varying nullable=true", Actual: "text nullable=true"},
+		{Object: pgschema.ObjectIndex, Name: "dummy.dummy_pkey", Expected: "CREATE UNIQUE INDEX dummy_pkey ON dummy USING btree (id)"},
+		{Object: pgschema.ObjectTable, Name: "removed", Expected: "exists"},
+	}, pgschema.Diff(expected, actual))
+}
+
+func TestUnqualify(t *testing.T) {
+	for _, test := range []struct {
+		schema     string
+		definition string
+		expected   string
+	}{
+		{
+			// the live database compared with --schema public.
+			"public",
+			"CREATE INDEX dummy_title_trgm_idx ON public.dummy USING gin (title gin_trgm_ops)",
+			"CREATE INDEX dummy_title_trgm_idx ON dummy USING gin (title gin_trgm_ops)",
+		},
+		{
+			// the scratch schema with migrations, extensions are visible in its search path.
+			"scratch-1a2b3c",
+			`CREATE INDEX dummy_title_trgm_idx ON "scratch-1a2b3c".dummy USING gin (title gin_trgm_ops)`,
+			"CREATE INDEX dummy_title_trgm_idx ON dummy USING gin (title gin_trgm_ops)",
+		},
+		{"public", "FOREIGN KEY (dummy_id) REFERENCES public.dummy(id)", "FOREIGN KEY (dummy_id) REFERENCES dummy(id)"},
+		{"public", "'public.example'::text", "'public.example'::text"},
+		{"public", "'it''s public.example'::text", "'it''s public.example'::text"},
+		{"public", `CREATE INDEX x ON notpublic.dummy USING btree ("public.title")`, `CREATE INDEX x ON notpublic.dummy USING btree ("public.title")`},
+	} {
+		require.Equal(t, test.expected, pgschema.Unqualify(test.schema, test.definition), test.definition)
+	}
+}
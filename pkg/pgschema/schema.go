@@ -0,0 +1,261 @@
+package pgschema
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"strings"
+
+	"github.com/zeebo/errs"
+)
+
+// Error indicates about internal error in pgschema processing.
+var Error = errs.Class("pgschema internal error")
+
+// ExtensionSchema is the schema of extensions shared by all schemas of the database, e.g. pg_trgm.
+// Postgres qualifies names in definitions which aren't visible in the search path, so schemas are
+// introspected with the search path of the schema itself followed by ExtensionSchema, and objects
+// of extensions are printed the same way for every schema.
+const ExtensionSchema = "public"
+
+// ignoredTables are tables which don't belong to the application schema.
+var ignoredTables = map[string]bool{
+	"schema_migrations": true,
+}
+
+// Queryer is for querying sql.
+type Queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// Schema describes tables of a postgres schema.
+type Schema struct {
+	Name   string
+	Tables map[string]*Table
+}
+
+// Table describes a postgres table.
+type Table struct {
+	Name        string
+	Columns     map[string]Column
+	Indexes     map[string]Index
+	Constraints map[string]Constraint
+}
+
+// Column describes a table column.
+type Column struct {
+	Name     string
+	Position int
+	Type     string
+	Nullable bool
+	// Default is the default expression or the expression of a generated column.
+	Default   string
+	Generated bool
+}
+
+// Index describes a table index.
+type Index struct {
+	Name       string
+	Definition string
+}
+
+// Constraint describes a table constraint.
+type Constraint struct {
+	Name       string
+	Type       string
+	Definition string
+}
+
+// Introspect reads tables, columns, indexes and constraints of the current schema of db. db should
+// have the search path of the schema and ExtensionSchema, since definitions depend on the search path.
+// Schema name is removed from definitions, so schemas with different names can be compared.
+func Introspect(ctx context.Context, db Queryer) (*Schema, error) {
+	schema := &Schema{Tables: make(map[string]*Table)}
+
+	err := db.QueryRowContext(ctx, `SELECT current_schema()`).Scan(&schema.Name)
+	if err != nil {
+		return nil, Error.Wrap(err)
+	}
+
+	err = query(ctx, db, `
+		SELECT cls.relname
+		FROM pg_class cls
+		JOIN pg_namespace ns ON ns.oid = cls.relnamespace
+		WHERE ns.nspname = $1 AND cls.relkind IN ('r', 'p')`,
+		[]interface{}{schema.Name},
+		func(rows *sql.Rows) error {
+			var name string
+			if err := rows.Scan(&name); err != nil {
+				return err
+			}
+
+			if !ignoredTables[name] {
+				schema.Tables[name] = &Table{
+					Name:        name,
+					Columns:     make(map[string]Column),
+					Indexes:     make(map[string]Index),
+					Constraints: make(map[string]Constraint),
+				}
+			}
+			return nil
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	err = query(ctx, db, `
+		SELECT cls.relname, att.attname, att.attnum, format_type(att.atttypid, att.atttypmod),
+		       NOT att.attnotnull, COALESCE(pg_get_expr(def.adbin, def.adrelid), ''), att.attgenerated <> ''
+		FROM pg_attribute att
+		JOIN pg_class cls ON cls.oid = att.attrelid
+		JOIN pg_namespace ns ON ns.oid = cls.relnamespace
+		LEFT JOIN pg_attrdef def ON def.adrelid = att.attrelid AND def.adnum = att.attnum
+		WHERE ns.nspname = $1 AND cls.relkind IN ('r', 'p') AND att.attnum > 0 AND NOT att.attisdropped`,
+		[]interface{}{schema.Name},
+		func(rows *sql.Rows) error {
+			var table string
+			var column Column
+			if err := rows.Scan(&table, &column.Name, &column.Position, &column.Type, &column.Nullable, &column.Default, &column.Generated); err != nil {
+				return err
+			}
+
+			if t, ok := schema.Tables[table]; ok {
+				column.Default = Unqualify(schema.Name, column.Default)
+				t.Columns[column.Name] = column
+			}
+			return nil
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	err = query(ctx, db, `
+		SELECT tablename, indexname, indexdef
+		FROM pg_indexes
+		WHERE schemaname = $1`,
+		[]interface{}{schema.Name},
+		func(rows *sql.Rows) error {
+			var table string
+			var index Index
+			if err := rows.Scan(&table, &index.Name, &index.Definition); err != nil {
+				return err
+			}
+
+			if t, ok := schema.Tables[table]; ok {
+				index.Definition = Unqualify(schema.Name, index.Definition)
+				t.Indexes[index.Name] = index
+			}
+			return nil
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	err = query(ctx, db, `
+		SELECT cls.relname, con.conname, con.contype, pg_get_constraintdef(con.oid)
+		FROM pg_constraint con
+		JOIN pg_class cls ON cls.oid = con.conrelid
+		JOIN pg_namespace ns ON ns.oid = con.connamespace
+		WHERE ns.nspname = $1`,
+		[]interface{}{schema.Name},
+		func(rows *sql.Rows) error {
+			var table string
+			var constraint Constraint
+			if err := rows.Scan(&table, &constraint.Name, &constraint.Type, &constraint.Definition); err != nil {
+				return err
+			}
+
+			if t, ok := schema.Tables[table]; ok {
+				constraint.Definition = Unqualify(schema.Name, constraint.Definition)
+				t.Constraints[constraint.Name] = constraint
+			}
+			return nil
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return schema, nil
+}
+
+// OrderedColumns returns table columns in the order of their declaration.
+func (table *Table) OrderedColumns() []Column {
+	columns := make([]Column, 0, len(table.Columns))
+	for _, column := range table.Columns {
+		columns = append(columns, column)
+	}
+
+	sort.Slice(columns, func(i, j int) bool {
+		return columns[i].Position < columns[j].Position
+	})
+
+	return columns
+}
+
+// Unqualify removes qualifiers of the schema from names in the sql definition, e.g. the table
+// name of an index definition. Quoted identifiers and string literals are kept as they are.
+func Unqualify(schema, definition string) string {
+	quoted := `"` + strings.ReplaceAll(schema, `"`, `""`) + `".`
+	unquoted := schema + "."
+
+	var result strings.Builder
+	for i := 0; i < len(definition); {
+		rest := definition[i:]
+		switch {
+		case strings.HasPrefix(rest, quoted):
+			i += len(quoted)
+		case strings.HasPrefix(rest, unquoted) && (i == 0 || !isIdentifier(definition[i-1])):
+			i += len(unquoted)
+		case rest[0] == '\'' || rest[0] == '"':
+			end := quotedEnd(rest)
+			result.WriteString(rest[:end])
+			i += end
+		default:
+			result.WriteByte(rest[0])
+			i++
+		}
+	}
+	return result.String()
+}
+
+// quotedEnd returns the length of the string literal or the quoted identifier the text starts with,
+// doubled quotes are escaped ones.
+func quotedEnd(text string) int {
+	quote := text[0]
+	for i := 1; i < len(text); i++ {
+		if text[i] != quote {
+			continue
+		}
+		if i+1 < len(text) && text[i+1] == quote {
+			i++
+			continue
+		}
+		return i + 1
+	}
+	return len(text)
+}
+
+// isIdentifier checks if the byte can be a part of an unquoted identifier.
+func isIdentifier(c byte) bool {
+	return c == '_' || c == '$' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+// query executes query and calls fn for every row.
+func query(ctx context.Context, db Queryer, query string, args []interface{}, fn func(rows *sql.Rows) error) (err error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return Error.Wrap(err)
+	}
+	defer func() {
+		err = errs.Combine(err, Error.Wrap(rows.Close()))
+	}()
+
+	for rows.Next() {
+		if err = fn(rows); err != nil {
+			return Error.Wrap(err)
+		}
+	}
+
+	return Error.Wrap(rows.Err())
+}
@@ -0,0 +1,73 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"hash/fnv"
+
+	"github.com/zeebo/errs"
+)
+
+// LockKey returns advisory lock key for the given name.
+func LockKey(name string) int64 {
+	hash := fnv.New64a()
+	_, _ = hash.Write([]byte(name))
+	return int64(hash.Sum64())
+}
+
+// WithAdvisoryLock waits for a session-level advisory lock with the given key,
+// calls fn and releases the lock. The lock is held on a dedicated connection,
+// so other connections of db can be used by fn.
+func WithAdvisoryLock(ctx context.Context, db *sql.DB, key int64, fn func(ctx context.Context) error) (err error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errs.Combine(err, conn.Close())
+	}()
+
+	if _, err = conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, key); err != nil {
+		return err
+	}
+	defer func() {
+		_, unlockErr := conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, key)
+		err = errs.Combine(err, unlockErr)
+	}()
+
+	return fn(ctx)
+}
+
+// Lock is a session-level advisory lock held on a dedicated connection.
+type Lock struct {
+	conn *sql.Conn
+	key  int64
+}
+
+// TryAdvisoryLock takes a session-level advisory lock with the given key without waiting,
+// it returns nil if the lock is held by another session. The lock is held until it's released
+// or the connection breaks, since the server releases locks of closed sessions.
+func TryAdvisoryLock(ctx context.Context, db *sql.DB, key int64) (*Lock, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var acquired bool
+	if err = conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, key).Scan(&acquired); err != nil || !acquired {
+		return nil, errs.Combine(err, conn.Close())
+	}
+
+	return &Lock{conn: conn, key: key}, nil
+}
+
+// Check returns an error if the connection holding the lock is broken, so the lock could be taken by another session.
+func (lock *Lock) Check(ctx context.Context) error {
+	return lock.conn.PingContext(ctx)
+}
+
+// Release releases the lock and returns the connection to the pool.
+func (lock *Lock) Release() error {
+	_, err := lock.conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, lock.key)
+	return errs.Combine(err, lock.conn.Close())
+}
@@ -0,0 +1,164 @@
+package sqlgen
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/zeebo/errs"
+
+	"project_template/pkg/pgschema"
+	"project_template/pkg/tempdb"
+)
+
+// Relax drops all constraints and NOT NULL flags in the scratch schema, so queries
+// can be executed with NULL parameters to infer their result columns.
+// It must never be called on a real database.
+func Relax(ctx context.Context, db *sql.DB, schema *pgschema.Schema) error {
+	for _, table := range schema.Tables {
+		for _, constraint := range table.Constraints {
+			_, err := db.ExecContext(ctx, fmt.Sprintf(`ALTER TABLE %s DROP CONSTRAINT IF EXISTS %s CASCADE`,
+				tempdb.QuoteSchema(table.Name), tempdb.QuoteSchema(constraint.Name)))
+			if err != nil {
+				return Error.Wrap(err)
+			}
+		}
+
+		for _, column := range table.Columns {
+			if column.Nullable {
+				continue
+			}
+
+			_, err := db.ExecContext(ctx, fmt.Sprintf(`ALTER TABLE %s ALTER COLUMN %s DROP NOT NULL`,
+				tempdb.QuoteSchema(table.Name), tempdb.QuoteSchema(column.Name)))
+			if err != nil {
+				return Error.Wrap(err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Infer fills parameters and result columns of the file queries. Parameter types
+// are taken from the prepared statement, result columns from the query executed
+// with NULL parameters in a transaction which is always rolled back.
+// Nullability of result columns is taken from the schema.
+func Infer(ctx context.Context, db *sql.DB, schema *pgschema.Schema, file *File) error {
+	for _, query := range file.Queries {
+		if err := inferQuery(ctx, db, schema, query); err != nil {
+			return Error.New("%s: query %s: %v", file.Name, query.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// inferQuery fills parameters and result columns of the query.
+func inferQuery(ctx context.Context, db *sql.DB, schema *pgschema.Schema, query *Query) (err error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errs.Combine(err, tx.Rollback())
+	}()
+
+	if _, err = tx.ExecContext(ctx, `PREPARE sqlgen_query AS `+query.SQL); err != nil {
+		return err
+	}
+
+	var paramTypes []string
+	err = tx.QueryRowContext(ctx, `SELECT parameter_types::text[] FROM pg_prepared_statements WHERE name = 'sqlgen_query'`).
+		Scan(pq.Array(&paramTypes))
+	if err != nil {
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, `DEALLOCATE sqlgen_query`); err != nil {
+		return err
+	}
+
+	rows, err := tx.QueryContext(ctx, query.SQL, make([]interface{}, len(paramTypes))...)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errs.Combine(err, rows.Close())
+	}()
+
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return err
+	}
+
+	var columns []Column
+	for _, columnType := range columnTypes {
+		columns = append(columns, Column{Name: columnType.Name(), PGType: columnType.DatabaseTypeName()})
+	}
+
+	return Describe(schema, query, paramTypes, columns)
+}
+
+// Describe fills parameters and result columns of the query from types reported by postgres: parameter types
+// of the prepared statement and names and types of result columns. Parameters are named after columns
+// they are used with, tables and nullability of columns are taken from the schema.
+func Describe(schema *pgschema.Schema, query *Query, paramTypes []string, columns []Column) error {
+	tables := query.tables()
+	paramColumns := query.paramColumns()
+	seen := make(map[string]bool)
+
+	query.Params = nil
+	for i, pgType := range paramTypes {
+		param := Param{Name: fmt.Sprintf("arg%d", i+1), PGType: pgType}
+
+		if column, ok := paramColumns[i+1]; ok {
+			param.Column = column
+			param.Table = findTable(schema, tables, column)
+			if !seen[column] {
+				param.Name = column
+			}
+		}
+		seen[param.Name] = true
+
+		query.Params = append(query.Params, param)
+	}
+
+	query.Columns = nil
+	for _, column := range columns {
+		column.Nullable = true
+		column.Table = findTable(schema, tables, column.Name)
+		if column.Table != "" {
+			column.Nullable = schema.Tables[column.Table].Columns[column.Name].Nullable
+		}
+
+		query.Columns = append(query.Columns, column)
+	}
+
+	switch query.Kind {
+	case KindOne, KindMany:
+		if len(query.Columns) == 0 {
+			return Error.New("%s query returns no columns", query.Kind)
+		}
+	default:
+		if len(query.Columns) != 0 {
+			return Error.New("%s query returns columns, use :one or :many", query.Kind)
+		}
+	}
+
+	return nil
+}
+
+// findTable returns the first of tables which has the column.
+func findTable(schema *pgschema.Schema, tables []string, column string) string {
+	for _, name := range tables {
+		if table, ok := schema.Tables[name]; ok {
+			if _, ok := table.Columns[column]; ok {
+				return name
+			}
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,208 @@
+package sqlgen
+
+import (
+	"bufio"
+	"regexp"
+	"strings"
+
+	"github.com/zeebo/errs"
+)
+
+// Error indicates about internal error in sqlgen processing.
+var Error = errs.Class("sqlgen internal error")
+
+// Kind defines what a generated query function returns.
+type Kind string
+
+const (
+	// KindOne returns a single row, sql.ErrNoRows is returned if there is none.
+	KindOne Kind = ":one"
+	// KindMany returns all rows.
+	KindMany Kind = ":many"
+	// KindExec returns only an error.
+	KindExec Kind = ":exec"
+	// KindExecRows returns the number of affected rows.
+	KindExecRows Kind = ":execrows"
+)
+
+// File is a parsed file with annotated queries.
+type File struct {
+	// Name is the file name without extension, used as a name of the generated file.
+	Name      string
+	Queries   []*Query
+	Overrides Overrides
+}
+
+// Query is a single annotated query.
+type Query struct {
+	Name    string
+	Kind    Kind
+	SQL     string
+	Params  []Param
+	Columns []Column
+}
+
+// Param is a query parameter, e.g. $1.
+type Param struct {
+	Name   string
+	PGType string
+	// Table and Column reference the table column the parameter is compared with or inserted to, if known.
+	Table  string
+	Column string
+}
+
+// Column is a query result column.
+type Column struct {
+	Name     string
+	PGType   string
+	Nullable bool
+	// Table references the table the column is read from, if known.
+	Table string
+}
+
+// Overrides maps "table.column" to Go type used for it instead of the inferred one.
+type Overrides map[string]string
+
+var (
+	// nameExpr matches query annotations, e.g. "-- name: GetDummy :one".
+	nameExpr = regexp.MustCompile(`^--\s*name:\s*(\w+)\s+(:one|:many|:exec|:execrows)\s*$`)
+	// overrideExpr matches type override annotations, e.g. "-- override: dummy.id uuid.UUID".
+	overrideExpr = regexp.MustCompile(`^--\s*override:\s*(\w+\.\w+)\s+(\S+)\s*$`)
+)
+
+// Parse parses queries annotated with "-- name: <Name> <:one|:many|:exec|:execrows>" comments.
+// Each query ends with the next annotation or with the end of the file.
+func Parse(name, content string) (*File, error) {
+	file := &File{Name: name, Overrides: make(Overrides)}
+
+	var (
+		current *Query
+		body    strings.Builder
+		lineNum int
+	)
+
+	flush := func() {
+		if current != nil {
+			current.SQL = strings.TrimSuffix(strings.TrimSpace(body.String()), ";")
+			file.Queries = append(file.Queries, current)
+		}
+		body.Reset()
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		lineNum++
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+
+		if match := overrideExpr.FindStringSubmatch(trimmed); match != nil {
+			if _, ok := knownTypes[match[2]]; !ok {
+				return nil, Error.New("%s:%d: unsupported override type %q", name, lineNum, match[2])
+			}
+			file.Overrides[match[1]] = match[2]
+			continue
+		}
+
+		if match := nameExpr.FindStringSubmatch(trimmed); match != nil {
+			flush()
+			current = &Query{Name: match[1], Kind: Kind(match[2])}
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "--") || current == nil {
+			continue
+		}
+
+		body.WriteString(line)
+		body.WriteString("\n")
+	}
+	flush()
+
+	if err := scanner.Err(); err != nil {
+		return nil, Error.Wrap(err)
+	}
+
+	seen := make(map[string]bool)
+	for _, query := range file.Queries {
+		if query.SQL == "" {
+			return nil, Error.New("%s: query %s is empty", name, query.Name)
+		}
+		if seen[query.Name] {
+			return nil, Error.New("%s: query %s is declared twice", name, query.Name)
+		}
+		seen[query.Name] = true
+	}
+
+	return file, nil
+}
+
+var (
+	// tableExpr matches tables referenced by the query.
+	tableExpr = regexp.MustCompile(`(?i)\b(?:FROM|JOIN|INTO|UPDATE)\s+(\w+)`)
+	// comparisonExpr matches parameters compared with or assigned to columns, e.g. "id = $1", "title % $1",
+	// "title OPERATOR(public.%) $1" or "id = ANY($1::uuid[])".
+	comparisonExpr = regexp.MustCompile(`(?i)\b(\w+)\s*(?:=|<>|!=|<=|>=|<|>|%|\bLIKE|\bILIKE|\bOPERATOR\s*\(\s*(?:\w+\.)?%\s*\))\s*(?:ANY\s*\(\s*)?\$(\d+)\b`)
+	// paginationExpr matches LIMIT and OFFSET parameters.
+	paginationExpr = regexp.MustCompile(`(?i)\b(LIMIT|OFFSET)\s+\$(\d+)\b`)
+	// insertExpr matches INSERT statement with column and values lists.
+	insertExpr = regexp.MustCompile(`(?is)\bINSERT\s+INTO\s+(\w+)\s*\(([^)]*)\)\s*VALUES\s*\(([^)]*)\)`)
+	// unnestExpr matches unnest of array parameters with the list of column aliases,
+	// e.g. "unnest($1::uuid[], $2::varchar[]) AS u(id, title)".
+	unnestExpr = regexp.MustCompile(`(?is)\bunnest\s*\(([^)]*)\)\s*(?:AS\s+)?\w+\s*\(([^)]*)\)`)
+	// paramExpr matches a single parameter placeholder, optionally with a type cast.
+	paramExpr = regexp.MustCompile(`^\$(\d+)(?:\s*::.*)?$`)
+)
+
+// tables returns names of tables referenced by the query.
+func (query *Query) tables() []string {
+	var tables []string
+	for _, match := range tableExpr.FindAllStringSubmatch(query.SQL, -1) {
+		tables = append(tables, strings.ToLower(match[1]))
+	}
+	return tables
+}
+
+// paramColumns guesses names of parameters from columns they are compared with or inserted to,
+// array parameters of unnest are named after column aliases, LIMIT and OFFSET parameters are
+// named after the clause.
+func (query *Query) paramColumns() map[int]string {
+	columns := make(map[int]string)
+
+	// nameParams names parameters of the values list after columns at the same positions.
+	nameParams := func(columnList, valueList string) {
+		names, values := strings.Split(columnList, ","), strings.Split(valueList, ",")
+		for i := 0; i < len(names) && i < len(values); i++ {
+			param := paramExpr.FindStringSubmatch(strings.TrimSpace(values[i]))
+			if param != nil {
+				columns[atoi(param[1])] = strings.ToLower(strings.TrimSpace(names[i]))
+			}
+		}
+	}
+
+	if match := insertExpr.FindStringSubmatch(query.SQL); match != nil {
+		nameParams(match[2], match[3])
+	}
+	for _, match := range unnestExpr.FindAllStringSubmatch(query.SQL, -1) {
+		nameParams(match[2], match[1])
+	}
+
+	for _, expr := range []*regexp.Regexp{comparisonExpr, paginationExpr} {
+		for _, match := range expr.FindAllStringSubmatch(query.SQL, -1) {
+			index := atoi(match[2])
+			if _, ok := columns[index]; !ok {
+				columns[index] = strings.ToLower(match[1])
+			}
+		}
+	}
+
+	return columns
+}
+
+// atoi converts string of digits to int.
+func atoi(s string) int {
+	var n int
+	for _, c := range s {
+		n = n*10 + int(c-'0')
+	}
+	return n
+}
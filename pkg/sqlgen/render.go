@@ -0,0 +1,382 @@
+package sqlgen
+
+import (
+	"bytes"
+	"go/format"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"project_template/pkg/pgschema"
+)
+
+// Header is the first line of every generated file.
+const Header = "// Code generated by `database generate`. DO NOT EDIT."
+
+// initialisms are words written in upper case in Go identifiers.
+var initialisms = map[string]bool{
+	"id": true, "uuid": true, "url": true, "uri": true, "api": true,
+	"http": true, "json": true, "sql": true, "ip": true, "html": true,
+}
+
+// keywords are Go keywords and names used by generated code which can't be used as parameter names.
+var keywords = map[string]bool{
+	"break": true, "case": true, "chan": true, "const": true, "continue": true, "default": true,
+	"defer": true, "else": true, "fallthrough": true, "for": true, "func": true, "go": true,
+	"goto": true, "if": true, "import": true, "interface": true, "map": true, "package": true,
+	"range": true, "return": true, "select": true, "struct": true, "switch": true, "type": true,
+	"var": true, "ctx": true, "db": true, "err": true, "row": true, "rows": true, "result": true,
+}
+
+// exportedName converts snake_case name to CamelCase Go identifier.
+func exportedName(name string) string {
+	var b strings.Builder
+	for _, part := range strings.FieldsFunc(name, func(r rune) bool { return r == '_' || r == ' ' }) {
+		if initialisms[strings.ToLower(part)] {
+			b.WriteString(strings.ToUpper(part))
+			continue
+		}
+		runes := []rune(part)
+		b.WriteRune(unicode.ToUpper(runes[0]))
+		b.WriteString(string(runes[1:]))
+	}
+	return b.String()
+}
+
+// unexportedName converts snake_case or CamelCase name to camelCase Go identifier.
+func unexportedName(name string) string {
+	exported := exportedName(name)
+	for word := range initialisms {
+		if strings.EqualFold(exported, word) {
+			return strings.ToLower(exported)
+		}
+		upper := strings.ToUpper(word)
+		if strings.HasPrefix(exported, upper) && len(exported) > len(upper) && unicode.IsUpper(rune(exported[len(upper)])) {
+			return strings.ToLower(upper) + exported[len(upper):]
+		}
+	}
+
+	runes := []rune(exported)
+	if len(runes) == 0 {
+		return ""
+	}
+	runes[0] = unicode.ToLower(runes[0])
+	return string(runes)
+}
+
+// paramName returns Go name of the query parameter.
+func paramName(name string) string {
+	result := unexportedName(name)
+	if keywords[result] {
+		result += "Param"
+	}
+	return result
+}
+
+// field is a field of the generated row struct.
+type field struct {
+	Name string
+	Type string
+}
+
+// modelTable is the template data of a table row struct.
+type modelTable struct {
+	Name   string
+	Type   string
+	Fields []field
+}
+
+// funcParam is the template data of a query function parameter.
+type funcParam struct {
+	Name string
+	Type string
+	// Arg is the expression passed to the driver.
+	Arg string
+}
+
+// funcQuery is the template data of a query function.
+type funcQuery struct {
+	Name     string
+	Query    string
+	Kind     Kind
+	SQL      string
+	Params   []funcParam
+	RowType  string
+	Fields   []field
+	OwnModel bool
+}
+
+// imports collects imports of generated file.
+type imports map[string]bool
+
+// add adds import of the Go type.
+func (imports imports) add(typ string) {
+	if path := importOf(typ); path != "" {
+		imports[path] = true
+	}
+}
+
+// sorted returns import paths, standard library first.
+func (imports imports) sorted() []string {
+	var std, external []string
+	for path := range imports {
+		if strings.Contains(path, ".") {
+			external = append(external, path)
+		} else {
+			std = append(std, path)
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(external)
+	if len(external) > 0 && len(std) > 0 {
+		std = append(std, "")
+	}
+	return append(std, external...)
+}
+
+// modelColumns returns table columns which are part of the row struct, generated
+// columns are computed by the database and are never written by the application.
+func modelColumns(table *pgschema.Table) []pgschema.Column {
+	var columns []pgschema.Column
+	for _, column := range table.OrderedColumns() {
+		if !column.Generated {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
+// modelFields returns row struct fields for table columns.
+func modelFields(table *pgschema.Table, overrides Overrides) []field {
+	var fields []field
+	for _, column := range modelColumns(table) {
+		fields = append(fields, field{
+			Name: exportedName(column.Name),
+			Type: resolveType(column.Type, column.Nullable, overrides[table.Name+"."+column.Name]),
+		})
+	}
+	return fields
+}
+
+// tableType returns name of the row struct of the table.
+func tableType(table string) string {
+	return unexportedName(table) + "Row"
+}
+
+// RenderModels renders the dbtx interface and row structs of all schema tables.
+func RenderModels(packageName string, schema *pgschema.Schema, overrides Overrides) ([]byte, error) {
+	imp := imports{"context": true, "database/sql": true}
+
+	var names []string
+	for name := range schema.Tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var tables []modelTable
+	for _, name := range names {
+		fields := modelFields(schema.Tables[name], overrides)
+		for _, f := range fields {
+			imp.add(f.Type)
+		}
+		tables = append(tables, modelTable{Name: name, Type: tableType(name), Fields: fields})
+	}
+
+	return render(modelsTemplate, map[string]interface{}{
+		"Header":  Header,
+		"Package": packageName,
+		"Imports": imp.sorted(),
+		"Tables":  tables,
+	})
+}
+
+// RenderQueries renders functions of the file queries.
+func RenderQueries(packageName string, schema *pgschema.Schema, file *File, overrides Overrides) ([]byte, error) {
+	imp := imports{"context": true}
+
+	var queries []funcQuery
+	for _, query := range file.Queries {
+		fq := funcQuery{
+			Name:  unexportedName(query.Name),
+			Query: query.Name,
+			Kind:  query.Kind,
+			SQL:   query.SQL,
+		}
+
+		for _, param := range query.Params {
+			typ := resolveType(param.PGType, false, overrides[param.Table+"."+param.Column])
+			imp.add(typ)
+
+			p := funcParam{Name: paramName(param.Name), Type: typ}
+			p.Arg = p.Name
+			if isArray(typ) {
+				p.Arg = "pq.Array(" + p.Name + ")"
+				imp["github.com/lib/pq"] = true
+			}
+			fq.Params = append(fq.Params, p)
+		}
+
+		for _, column := range query.Columns {
+			typ := resolveType(column.PGType, column.Nullable, overrides[column.Table+"."+column.Name])
+			fq.Fields = append(fq.Fields, field{Name: exportedName(column.Name), Type: typ})
+		}
+
+		if table := matchingTable(schema, query.Columns); table != "" {
+			fq.RowType = tableType(table)
+		} else if len(fq.Fields) > 0 {
+			fq.RowType = fq.Name + "Row"
+			fq.OwnModel = true
+			for _, f := range fq.Fields {
+				imp.add(f.Type)
+			}
+		}
+
+		if query.Kind == KindMany {
+			imp["github.com/zeebo/errs"] = true
+		}
+
+		queries = append(queries, fq)
+	}
+
+	return render(queriesTemplate, map[string]interface{}{
+		"Header":  Header,
+		"Package": packageName,
+		"Imports": imp.sorted(),
+		"Queries": queries,
+	})
+}
+
+// matchingTable returns table which row struct columns are exactly the result columns.
+// The order may differ, since columns added by later migrations are placed at the end of the table.
+func matchingTable(schema *pgschema.Schema, columns []Column) string {
+	if len(columns) == 0 || columns[0].Table == "" {
+		return ""
+	}
+
+	table := schema.Tables[columns[0].Table]
+	expected := make(map[string]bool)
+	for _, column := range modelColumns(table) {
+		expected[column.Name] = true
+	}
+	if len(expected) != len(columns) {
+		return ""
+	}
+
+	for _, column := range columns {
+		if column.Table != table.Name || !expected[column.Name] {
+			return ""
+		}
+		delete(expected, column.Name)
+	}
+
+	return table.Name
+}
+
+// render executes template and formats the result as Go code.
+func render(tmpl *template.Template, data interface{}) ([]byte, error) {
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, data); err != nil {
+		return nil, Error.Wrap(err)
+	}
+
+	formatted, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, Error.New("could not format generated code: %v\n%s", err, b.String())
+	}
+
+	return formatted, nil
+}
+
+var funcs = template.FuncMap{
+	"backquote": func(s string) string { return "`" + strings.ReplaceAll(s, "`", "` + \"`\" + `") + "`" },
+}
+
+var modelsTemplate = template.Must(template.New("models").Funcs(funcs).Parse(`{{.Header}}
+
+package {{.Package}}
+
+import (
+{{- range .Imports}}
+	{{if .}}"{{.}}"{{end}}
+{{- end}}
+)
+
+// dbtx is implemented by *sql.DB, *sql.Conn and *sql.Tx, so generated queries can run in transactions.
+type dbtx interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+{{range .Tables}}
+// {{.Type}} is a row of the {{.Name}} table.
+type {{.Type}} struct {
+{{- range .Fields}}
+	{{.Name}} {{.Type}}
+{{- end}}
+}
+{{end}}`))
+
+var queriesTemplate = template.Must(template.New("queries").Funcs(funcs).Parse(`{{.Header}}
+
+package {{.Package}}
+
+import (
+{{- range .Imports}}
+	{{if .}}"{{.}}"{{end}}
+{{- end}}
+)
+{{range .Queries}}
+const {{.Name}}SQL = {{backquote .SQL}}
+{{if .OwnModel}}
+// {{.RowType}} is a row returned by the {{.Query}} query.
+type {{.RowType}} struct {
+{{- range .Fields}}
+	{{.Name}} {{.Type}}
+{{- end}}
+}
+{{end}}
+// {{.Name}} executes the {{.Query}} query.
+{{- if eq .Kind ":one"}}
+func {{.Name}}(ctx context.Context, db dbtx{{range .Params}}, {{.Name}} {{.Type}}{{end}}) ({{.RowType}}, error) {
+	var row {{.RowType}}
+	err := db.QueryRowContext(ctx, {{.Name}}SQL{{range .Params}}, {{.Arg}}{{end}}).Scan({{range $i, $f := .Fields}}{{if $i}}, {{end}}&row.{{$f.Name}}{{end}})
+	return row, err
+}
+{{- else if eq .Kind ":many"}}
+func {{.Name}}(ctx context.Context, db dbtx{{range .Params}}, {{.Name}} {{.Type}}{{end}}) (_ []{{.RowType}}, err error) {
+	rows, err := db.QueryContext(ctx, {{.Name}}SQL{{range .Params}}, {{.Arg}}{{end}})
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err = errs.Combine(err, rows.Close())
+	}()
+
+	var result []{{.RowType}}
+	for rows.Next() {
+		var row {{.RowType}}
+		if err = rows.Scan({{range $i, $f := .Fields}}{{if $i}}, {{end}}&row.{{$f.Name}}{{end}}); err != nil {
+			return nil, err
+		}
+		result = append(result, row)
+	}
+
+	return result, rows.Err()
+}
+{{- else if eq .Kind ":execrows"}}
+func {{.Name}}(ctx context.Context, db dbtx{{range .Params}}, {{.Name}} {{.Type}}{{end}}) (int64, error) {
+	result, err := db.ExecContext(ctx, {{.Name}}SQL{{range .Params}}, {{.Arg}}{{end}})
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+{{- else}}
+func {{.Name}}(ctx context.Context, db dbtx{{range .Params}}, {{.Name}} {{.Type}}{{end}}) error {
+	_, err := db.ExecContext(ctx, {{.Name}}SQL{{range .Params}}, {{.Arg}}{{end}})
+	return err
+}
+{{- end}}
+{{end}}`))
@@ -0,0 +1,176 @@
+package sqlgen_test
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"project_template/pkg/pgschema"
+	"project_template/pkg/sqlgen"
+)
+
+var update = flag.Bool("update", false, "updates golden files")
+
+// dummySchema is the schema built from migrations of the dummy table.
+func dummySchema() *pgschema.Schema {
+	return &pgschema.Schema{Tables: map[string]*pgschema.Table{
+		"dummy": {
+			Name: "dummy",
+			Columns: map[string]pgschema.Column{
+				"id":         {Name: "id", Position: 1, Type: "bytea"},
+				"title":      {Name: "title", Position: 2, Type: "character varying", Nullable: true},
+				"status":     {Name: "status", Position: 3, Type: "integer"},
+				"created_at": {Name: "created_at", Position: 4, Type: "timestamp with time zone"},
+				// generated columns are not part of row structs.
+				"title_search": {Name: "title_search", Position: 5, Type: "tsvector", Nullable: true, Generated: true},
+			},
+		},
+	}}
+}
+
+func TestParse(t *testing.T) {
+	content, err := os.ReadFile("testdata/dummy.sql")
+	require.NoError(t, err)
+
+	file, err := sqlgen.Parse("dummy", string(content))
+	require.NoError(t, err)
+
+	require.Equal(t, sqlgen.Overrides{"dummy.id": "uuid.UUID"}, file.Overrides)
+	require.Len(t, file.Queries, 4)
+	require.Equal(t, "GetDummy", file.Queries[0].Name)
+	require.Equal(t, sqlgen.KindOne, file.Queries[0].Kind)
+	require.Equal(t, "SELECT id, title, status, created_at FROM dummy WHERE id = $1 LIMIT 1", file.Queries[0].SQL)
+	require.Equal(t, "INSERT INTO dummy(id, title, status, created_at)\nVALUES ($1, $2, $3, $4)", file.Queries[2].SQL)
+
+	_, err = sqlgen.Parse("bad", "-- name: A :one\nSELECT 1;\n-- name: A :one\nSELECT 2;")
+	require.Error(t, err)
+
+	_, err = sqlgen.Parse("bad", "-- override: dummy.id unknown.Type")
+	require.Error(t, err)
+}
+
+func TestRender(t *testing.T) {
+	schema := dummySchema()
+
+	content, err := os.ReadFile("testdata/dummy.sql")
+	require.NoError(t, err)
+
+	file, err := sqlgen.Parse("dummy", string(content))
+	require.NoError(t, err)
+
+	// types reported by postgres for the queries.
+	columns := []sqlgen.Column{
+		{Name: "id", PGType: "BYTEA", Table: "dummy"},
+		{Name: "title", PGType: "VARCHAR", Nullable: true, Table: "dummy"},
+		{Name: "status", PGType: "INT4", Table: "dummy"},
+		{Name: "created_at", PGType: "TIMESTAMPTZ", Table: "dummy"},
+	}
+	file.Queries[0].Params = []sqlgen.Param{{Name: "id", PGType: "bytea", Table: "dummy", Column: "id"}}
+	file.Queries[0].Columns = columns
+	file.Queries[1].Params = []sqlgen.Param{{Name: "status", PGType: "integer", Table: "dummy", Column: "status"}}
+	file.Queries[1].Columns = columns[:2]
+	file.Queries[2].Params = []sqlgen.Param{
+		{Name: "id", PGType: "bytea", Table: "dummy", Column: "id"},
+		{Name: "title", PGType: "character varying", Table: "dummy", Column: "title"},
+		{Name: "status", PGType: "integer", Table: "dummy", Column: "status"},
+		{Name: "created_at", PGType: "timestamp with time zone", Table: "dummy", Column: "created_at"},
+	}
+	file.Queries[3].Params = []sqlgen.Param{{Name: "id", PGType: "bytea", Table: "dummy", Column: "id"}}
+
+	models, err := sqlgen.RenderModels("database", schema, file.Overrides)
+	require.NoError(t, err)
+	golden(t, "models.gen.go.golden", models)
+
+	queries, err := sqlgen.RenderQueries("database", schema, file, file.Overrides)
+	require.NoError(t, err)
+	golden(t, "dummy_queries.gen.go.golden", queries)
+}
+
+// golden compares actual content with the golden file, or updates it with -update flag.
+func golden(t *testing.T, name string, actual []byte) {
+	path := filepath.Join("testdata", name)
+	if *update {
+		require.NoError(t, os.WriteFile(path, actual, 0644))
+		return
+	}
+
+	expected, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, string(expected), string(actual))
+}
+
+func TestRenderReorderedTable(t *testing.T) {
+	schema := dummySchema()
+	id := schema.Tables["dummy"].Columns["id"]
+	id.Position = 6
+	schema.Tables["dummy"].Columns["id"] = id
+
+	file, err := sqlgen.Parse("dummy", "-- name: GetDummy :one\nSELECT id, title, status, created_at FROM dummy WHERE id = $1;")
+	require.NoError(t, err)
+
+	file.Queries[0].Params = []sqlgen.Param{{Name: "id", PGType: "bytea", Table: "dummy", Column: "id"}}
+	file.Queries[0].Columns = []sqlgen.Column{
+		{Name: "id", PGType: "BYTEA", Table: "dummy"},
+		{Name: "title", PGType: "VARCHAR", Nullable: true, Table: "dummy"},
+		{Name: "status", PGType: "INT4", Table: "dummy"},
+		{Name: "created_at", PGType: "TIMESTAMPTZ", Table: "dummy"},
+	}
+
+	queries, err := sqlgen.RenderQueries("database", schema, file, nil)
+	require.NoError(t, err)
+	require.Contains(t, string(queries), "(dummyRow, error)")
+	require.Contains(t, string(queries), "Scan(&row.ID, &row.Title, &row.Status, &row.CreatedAt)")
+}
+
+func TestRenderArrayParams(t *testing.T) {
+	schema := dummySchema()
+
+	file, err := sqlgen.Parse("dummy", `-- override: dummy.id uuid.UUID
+-- name: CreateDummies :many
+INSERT INTO dummy (id, title, status, created_at)
+SELECT id, title, status, created_at
+FROM unnest($1::bytea[], $2::varchar[], $3::integer[], $4::timestamptz[]) AS c(id, title, status, created_at)
+RETURNING id;
+
+-- name: DeleteDummies :execrows
+DELETE FROM dummy WHERE id = ANY($1::bytea[]);`)
+	require.NoError(t, err)
+
+	// types reported by postgres for the queries.
+	require.NoError(t, sqlgen.Describe(schema, file.Queries[0],
+		[]string{"bytea[]", "character varying[]", "integer[]", "timestamp with time zone[]"},
+		[]sqlgen.Column{{Name: "id", PGType: "BYTEA"}}))
+	require.NoError(t, sqlgen.Describe(schema, file.Queries[1], []string{"bytea[]"}, nil))
+
+	require.Equal(t, []sqlgen.Param{
+		{Name: "id", PGType: "bytea[]", Table: "dummy", Column: "id"},
+		{Name: "title", PGType: "character varying[]", Table: "dummy", Column: "title"},
+		{Name: "status", PGType: "integer[]", Table: "dummy", Column: "status"},
+		{Name: "created_at", PGType: "timestamp with time zone[]", Table: "dummy", Column: "created_at"},
+	}, file.Queries[0].Params)
+	require.Equal(t, []sqlgen.Column{{Name: "id", PGType: "BYTEA", Table: "dummy"}}, file.Queries[0].Columns)
+
+	queries, err := sqlgen.RenderQueries("database", schema, file, file.Overrides)
+	require.NoError(t, err)
+	require.Contains(t, string(queries), `"github.com/lib/pq"`)
+	require.Contains(t, string(queries), "func createDummies(ctx context.Context, db dbtx, id []uuid.UUID, title []string, status []int32, createdAt []time.Time) (_ []createDummiesRow, err error)")
+	require.Contains(t, string(queries), "db.QueryContext(ctx, createDummiesSQL, pq.Array(id), pq.Array(title), pq.Array(status), pq.Array(createdAt))")
+	require.Contains(t, string(queries), "func deleteDummies(ctx context.Context, db dbtx, id []uuid.UUID) (int64, error)")
+	require.Contains(t, string(queries), "db.ExecContext(ctx, deleteDummiesSQL, pq.Array(id))")
+}
+
+func TestDescribeQualifiedOperator(t *testing.T) {
+	file, err := sqlgen.Parse("dummy", `-- name: SearchDummies :many
+SELECT id FROM dummy WHERE title OPERATOR(public.%) $1 LIMIT $2;`)
+	require.NoError(t, err)
+
+	require.NoError(t, sqlgen.Describe(dummySchema(), file.Queries[0], []string{"character varying", "bigint"},
+		[]sqlgen.Column{{Name: "id", PGType: "BYTEA"}}))
+	require.Equal(t, []sqlgen.Param{
+		{Name: "title", PGType: "character varying", Table: "dummy", Column: "title"},
+		{Name: "limit", PGType: "bigint", Column: "limit"},
+	}, file.Queries[0].Params)
+}
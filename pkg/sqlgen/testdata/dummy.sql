@@ -0,0 +1,14 @@
+-- override: dummy.id uuid.UUID
+
+-- name: GetDummy :one
+SELECT id, title, status, created_at FROM dummy WHERE id = $1 LIMIT 1;
+
+-- name: ListTitlesByStatus :many
+SELECT id, title FROM dummy WHERE status = $1;
+
+-- name: CreateDummy :exec
+INSERT INTO dummy(id, title, status, created_at)
+VALUES ($1, $2, $3, $4);
+
+-- name: DeleteDummy :execrows
+DELETE FROM dummy WHERE id = $1;
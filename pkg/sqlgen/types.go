@@ -0,0 +1,129 @@
+package sqlgen
+
+import (
+	"strings"
+)
+
+// knownTypes maps Go types which can be used in generated code to their import paths.
+var knownTypes = map[string]string{
+	"bool":            "",
+	"float32":         "",
+	"float64":         "",
+	"int16":           "",
+	"int32":           "",
+	"int64":           "",
+	"int":             "",
+	"string":          "",
+	"[]byte":          "",
+	"interface{}":     "",
+	"json.RawMessage": "encoding/json",
+	"sql.NullBool":    "database/sql",
+	"sql.NullFloat64": "database/sql",
+	"sql.NullInt16":   "database/sql",
+	"sql.NullInt32":   "database/sql",
+	"sql.NullInt64":   "database/sql",
+	"sql.NullString":  "database/sql",
+	"sql.NullTime":    "database/sql",
+	"time.Time":       "time",
+	"uuid.UUID":       "github.com/google/uuid",
+	"uuid.NullUUID":   "github.com/google/uuid",
+}
+
+// goType describes Go types used for a postgres type.
+type goType struct {
+	NotNull  string
+	Nullable string
+}
+
+// pgTypes maps postgres type names, both from format_type and from the driver, to Go types.
+var pgTypes = map[string]goType{
+	"boolean":                     {"bool", "sql.NullBool"},
+	"bool":                        {"bool", "sql.NullBool"},
+	"smallint":                    {"int16", "sql.NullInt16"},
+	"int2":                        {"int16", "sql.NullInt16"},
+	"integer":                     {"int32", "sql.NullInt32"},
+	"int4":                        {"int32", "sql.NullInt32"},
+	"bigint":                      {"int64", "sql.NullInt64"},
+	"int8":                        {"int64", "sql.NullInt64"},
+	"real":                        {"float32", "sql.NullFloat64"},
+	"float4":                      {"float32", "sql.NullFloat64"},
+	"double precision":            {"float64", "sql.NullFloat64"},
+	"float8":                      {"float64", "sql.NullFloat64"},
+	"numeric":                     {"string", "sql.NullString"},
+	"text":                        {"string", "sql.NullString"},
+	"character varying":           {"string", "sql.NullString"},
+	"varchar":                     {"string", "sql.NullString"},
+	"character":                   {"string", "sql.NullString"},
+	"bpchar":                      {"string", "sql.NullString"},
+	"bytea":                       {"[]byte", "[]byte"},
+	"uuid":                        {"uuid.UUID", "uuid.NullUUID"},
+	"tsvector":                    {"string", "sql.NullString"},
+	"json":                        {"json.RawMessage", "json.RawMessage"},
+	"jsonb":                       {"json.RawMessage", "json.RawMessage"},
+	"date":                        {"time.Time", "sql.NullTime"},
+	"timestamp without time zone": {"time.Time", "sql.NullTime"},
+	"timestamp":                   {"time.Time", "sql.NullTime"},
+	"timestamp with time zone":    {"time.Time", "sql.NullTime"},
+	"timestamptz":                 {"time.Time", "sql.NullTime"},
+}
+
+// arrayTypes maps Go types of array elements to Go types of arrays, which are passed to the driver with pq.Array.
+// Byte slices are encoded by the driver as bytea, so json arrays are passed as strings.
+var arrayTypes = map[string]string{
+	"bool":            "[]bool",
+	"float32":         "[]float32",
+	"float64":         "[]float64",
+	"int32":           "[]int32",
+	"int64":           "[]int64",
+	"string":          "[]string",
+	"[]byte":          "[][]byte",
+	"json.RawMessage": "[]string",
+	"time.Time":       "[]time.Time",
+	"uuid.UUID":       "[]uuid.UUID",
+}
+
+// resolveType returns Go type for postgres type, override has priority if it's set.
+// Override of an array is the type of its elements.
+func resolveType(pgType string, nullable bool, override string) string {
+	name := strings.TrimSpace(strings.ToLower(pgType))
+
+	// arrays are only used as parameters, e.g. of unnest, NULL elements are not supported.
+	if element := strings.TrimSuffix(name, "[]"); element != name {
+		typ, ok := arrayTypes[resolveType(element, false, override)]
+		if !ok {
+			return "interface{}"
+		}
+		return typ
+	}
+
+	if override != "" {
+		return override
+	}
+
+	// strip type modifiers, e.g. "character varying(255)".
+	if i := strings.IndexByte(name, '('); i >= 0 {
+		name = name[:i]
+	}
+
+	typ, ok := pgTypes[strings.TrimSpace(name)]
+	if !ok {
+		return "interface{}"
+	}
+	if nullable {
+		return typ.Nullable
+	}
+	return typ.NotNull
+}
+
+// importOf returns import path of the Go type, empty for builtin types.
+func importOf(typ string) string {
+	if path, ok := knownTypes[typ]; ok {
+		return path
+	}
+	return knownTypes[strings.TrimPrefix(typ, "[]")]
+}
+
+// isArray checks if the Go type is passed to the driver as a postgres array.
+func isArray(typ string) bool {
+	return strings.HasPrefix(typ, "[]") && typ != "[]byte"
+}
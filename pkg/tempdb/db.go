@@ -19,11 +19,17 @@ type Execer interface {
 	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
 }
 
+// Extensions are created in the public schema of the database before temporary schemas, since migrations
+// rely on them and extensions are shared by all schemas, so they can't be created by migrations of every schema.
+var Extensions = []string{"pg_trgm"}
+
 // TempDatabase is a database (or something that works like an isolated database,
 // such as a PostgreSQL schema) with a semi-unique name which will be cleaned up
 // when closed. Mainly useful for testing purposes.
 type TempDatabase struct {
-	conn    *sql.DB
+	conn *sql.DB
+	// connStr is the connection string without the search path.
+	connStr string
 	ConnStr string
 	Schema  string
 	Driver  string
@@ -46,6 +52,13 @@ func OpenUnique(ctx context.Context, config project_template.DBConfig, schemaPre
 		return nil, Error.New("failed to connect to %q with driver postgres: %w", connStrWithSchema, err)
 	}
 
+	for _, extension := range Extensions {
+		err = CreateExtension(ctx, db, extension)
+		if err != nil {
+			return nil, errs.Combine(err, db.Close())
+		}
+	}
+
 	err = CreateSchema(ctx, db, schemaName)
 	if err != nil {
 		return nil, errs.Combine(err, db.Close())
@@ -57,9 +70,20 @@ func OpenUnique(ctx context.Context, config project_template.DBConfig, schemaPre
 
 	return &TempDatabase{
 		conn:    db,
+		connStr: connStr,
 		ConnStr: connStrWithSchema,
 		Schema:  schemaName,
 		Driver:  "postgres",
 		Cleanup: cleanup,
 	}, nil
 }
+
+// ConnStrWithSearchPath returns the connection string with the temporary schema followed by schemas in the search path.
+func (db *TempDatabase) ConnStrWithSearchPath(schemas ...string) string {
+	return ConnstrWithSearchPath(db.connStr, append([]string{db.Schema}, schemas...)...)
+}
+
+// Close drops the temporary schema and closes the connection.
+func (db *TempDatabase) Close() error {
+	return Error.Wrap(errs.Combine(db.Cleanup(db.conn), db.conn.Close()))
+}
@@ -28,6 +28,22 @@ func CreateSchema(ctx context.Context, db Execer, schema string) (err error) {
 	return err
 }
 
+// CreateExtension creates an extension in the public schema if it doesn't exist.
+func CreateExtension(ctx context.Context, db Execer, extension string) (err error) {
+	for try := 0; try < 5; try++ {
+		_, err = db.ExecContext(ctx, `CREATE EXTENSION IF NOT EXISTS `+QuoteSchema(extension)+` SCHEMA public;`)
+
+		// like `CREATE SCHEMA IF NOT EXISTS`, concurrent `CREATE EXTENSION IF NOT EXISTS` may return
+		// "duplicate key value violates unique constraint", since pg_extension is shared by all schemas.
+		if postgres.IsConstraintError(err) {
+			continue
+		}
+		return err
+	}
+
+	return err
+}
+
 // DropSchema drops the named schema.
 func DropSchema(ctx context.Context, db Execer, schema string) error {
 	_, err := db.ExecContext(ctx, `DROP SCHEMA `+QuoteSchema(schema)+` CASCADE;`)
@@ -46,12 +62,22 @@ func CreateRandomTestingSchemaName(n int) string {
 
 // ConnstrWithSchema adds schema to a  connection string.
 func ConnstrWithSchema(connStr, schema string) string {
+	return ConnstrWithSearchPath(connStr, schema)
+}
+
+// ConnstrWithSearchPath adds the search path of schemas to a connection string, objects are created in the first one.
+func ConnstrWithSearchPath(connStr string, schemas ...string) string {
 	if strings.Contains(connStr, "?") {
 		connStr += "&options="
 	} else {
 		connStr += "?options="
 	}
-	return connStr + url.QueryEscape("--search_path="+QuoteSchema(schema))
+
+	quoted := make([]string, 0, len(schemas))
+	for _, schema := range schemas {
+		quoted = append(quoted, QuoteSchema(schema))
+	}
+	return connStr + url.QueryEscape("--search_path="+strings.Join(quoted, ","))
 }
 
 // QuoteSchema quotes a schema for use in an interpolated SQL string.
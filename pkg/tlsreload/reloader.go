@@ -0,0 +1,154 @@
+package tlsreload
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/zeebo/errs"
+
+	"project_template/pkg/logger"
+)
+
+// Error indicates that certificates could not be loaded.
+var Error = errs.Class("tls reload error")
+
+// Files are paths of PEM encoded certificate, its private key and an optional client CA bundle.
+type Files struct {
+	CertFile     string
+	KeyFile      string
+	ClientCAFile string
+}
+
+// Reloader keeps a certificate and client CAs loaded from files and reloads them when the files change,
+// so certificates can be rotated without a restart. If the new files are invalid, e.g. the certificate
+// was written but the key wasn't yet, the previous ones are kept until the next check.
+type Reloader struct {
+	log   logger.Logger
+	files Files
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	versions  map[string]fileVersion
+}
+
+// fileVersion identifies contents of a file without reading it.
+type fileVersion struct {
+	modTime time.Time
+	size    int64
+}
+
+// New loads the files, it fails if they can't be loaded.
+func New(log logger.Logger, files Files) (*Reloader, error) {
+	reloader := &Reloader{log: log, files: files}
+	if _, err := reloader.Reload(); err != nil {
+		return nil, err
+	}
+	return reloader, nil
+}
+
+// Run checks files for changes every interval until the context is canceled.
+func (reloader *Reloader) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		reloaded, err := reloader.Reload()
+		if err != nil {
+			reloader.log.Error("could not reload certificates, previous ones are used", err)
+			continue
+		}
+		if reloaded {
+			reloader.log.Debug("certificates are reloaded")
+		}
+	}
+}
+
+// Reload loads the files if any of them changed since the last load and reports whether they were loaded.
+func (reloader *Reloader) Reload() (bool, error) {
+	versions := make(map[string]fileVersion)
+	for _, path := range []string{reloader.files.CertFile, reloader.files.KeyFile, reloader.files.ClientCAFile} {
+		if path == "" {
+			continue
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			return false, Error.Wrap(err)
+		}
+		versions[path] = fileVersion{modTime: info.ModTime(), size: info.Size()}
+	}
+
+	reloader.mu.RLock()
+	changed := !sameVersions(reloader.versions, versions)
+	reloader.mu.RUnlock()
+	if !changed {
+		return false, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(reloader.files.CertFile, reloader.files.KeyFile)
+	if err != nil {
+		return false, Error.Wrap(err)
+	}
+
+	var clientCAs *x509.CertPool
+	if reloader.files.ClientCAFile != "" {
+		data, err := os.ReadFile(reloader.files.ClientCAFile)
+		if err != nil {
+			return false, Error.Wrap(err)
+		}
+
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(data) {
+			return false, Error.New("no certificates found in %s", reloader.files.ClientCAFile)
+		}
+	}
+
+	reloader.mu.Lock()
+	defer reloader.mu.Unlock()
+
+	reloader.cert = &cert
+	reloader.clientCAs = clientCAs
+	reloader.versions = versions
+	return true, nil
+}
+
+// GetCertificate returns the current certificate, it's used as tls.Config.GetCertificate.
+func (reloader *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	reloader.mu.RLock()
+	defer reloader.mu.RUnlock()
+
+	return reloader.cert, nil
+}
+
+// ClientCAs returns the current pool of client CAs, it's nil if the client CA file isn't set.
+func (reloader *Reloader) ClientCAs() *x509.CertPool {
+	reloader.mu.RLock()
+	defer reloader.mu.RUnlock()
+
+	return reloader.clientCAs
+}
+
+// sameVersions checks if all the files have the same versions.
+func sameVersions(a, b map[string]fileVersion) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for path, version := range a {
+		other, ok := b[path]
+		if !ok || !version.modTime.Equal(other.modTime) || version.size != other.size {
+			return false
+		}
+	}
+	return true
+}
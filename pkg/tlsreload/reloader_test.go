@@ -0,0 +1,132 @@
+package tlsreload_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"project_template/pkg/auth"
+	"project_template/pkg/logger/zaplog"
+	"project_template/pkg/tlsreload"
+)
+
+func TestReloader(t *testing.T) {
+	dir := t.TempDir()
+	files := tlsreload.Files{
+		CertFile:     filepath.Join(dir, "server.crt"),
+		KeyFile:      filepath.Join(dir, "server.key"),
+		ClientCAFile: filepath.Join(dir, "ca.crt"),
+	}
+
+	ca, caKey := newCertificate(t, "ca", nil, nil)
+	writeCertificate(t, files.ClientCAFile, ca, nil, time.Now())
+	first, firstKey := newCertificate(t, "first", ca, caKey)
+	writeCertificate(t, files.CertFile, first, nil, time.Now())
+	writeCertificate(t, files.KeyFile, nil, firstKey, time.Now())
+
+	reloader, err := tlsreload.New(zaplog.NewLog(), files)
+	require.NoError(t, err)
+
+	requireLeaf := func(expected *x509.Certificate) {
+		cert, err := reloader.GetCertificate(nil)
+		require.NoError(t, err)
+		require.Equal(t, expected.Raw, cert.Certificate[0])
+	}
+	requireLeaf(first)
+	require.NotNil(t, reloader.ClientCAs())
+
+	t.Run("unchanged files are not reloaded", func(t *testing.T) {
+		reloaded, err := reloader.Reload()
+		require.NoError(t, err)
+		require.False(t, reloaded)
+	})
+
+	second, secondKey := newCertificate(t, "second", ca, caKey)
+	later := time.Now().Add(time.Minute)
+
+	t.Run("mismatched pair keeps the previous certificate", func(t *testing.T) {
+		writeCertificate(t, files.CertFile, second, nil, later)
+
+		_, err := reloader.Reload()
+		require.Error(t, err)
+		requireLeaf(first)
+	})
+
+	t.Run("rotated certificate is reloaded", func(t *testing.T) {
+		writeCertificate(t, files.KeyFile, nil, secondKey, later)
+
+		reloaded, err := reloader.Reload()
+		require.NoError(t, err)
+		require.True(t, reloaded)
+		requireLeaf(second)
+	})
+
+	t.Run("missing files fail", func(t *testing.T) {
+		_, err := tlsreload.New(zaplog.NewLog(), tlsreload.Files{
+			CertFile: filepath.Join(dir, "missing.crt"),
+			KeyFile:  files.KeyFile,
+		})
+		require.Error(t, err)
+		require.True(t, tlsreload.Error.Has(err))
+	})
+
+	t.Run("identity of certificate", func(t *testing.T) {
+		identity := auth.IdentityFromCertificate(second)
+		require.Equal(t, "second", identity.CommonName)
+		require.Equal(t, "CN=second", identity.Subject)
+		require.Equal(t, "CN=ca", identity.Issuer)
+		require.Equal(t, []string{"second.test"}, identity.DNSNames)
+	})
+}
+
+// newCertificate creates a certificate signed by the parent, it's self-signed CA if the parent is nil.
+func newCertificate(t *testing.T, name string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{name + ".test"},
+	}
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage = x509.KeyUsageCertSign
+		parent, parentKey = template, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return cert, key
+}
+
+// writeCertificate writes the certificate or the key as PEM and sets the modification time of the file.
+func writeCertificate(t *testing.T, path string, cert *x509.Certificate, key *ecdsa.PrivateKey, modTime time.Time) {
+	var block *pem.Block
+	if cert != nil {
+		block = &pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}
+	} else {
+		der, err := x509.MarshalECPrivateKey(key)
+		require.NoError(t, err)
+		block = &pem.Block{Type: "EC PRIVATE KEY", Bytes: der}
+	}
+
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(block), 0600))
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+}
@@ -0,0 +1,79 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zeebo/errs"
+)
+
+// ErrQueue indicates that there was an error in the task queue.
+var ErrQueue = errs.Class("queue error")
+
+// ErrNoTask indicates that a task does not exist.
+var ErrNoTask = errs.Class("task does not exist")
+
+// Payload is a typed task, it's passed to the handler registered for its kind.
+type Payload interface {
+	// TaskKind returns kind of the task, e.g. "dummy.import".
+	TaskKind() string
+}
+
+// TaskStatus is a status of a task.
+type TaskStatus string
+
+const (
+	// TaskPending means that the task waits for its run time or is being handled.
+	TaskPending TaskStatus = "pending"
+	// TaskSucceeded means that the handler of the task returned no error.
+	TaskSucceeded TaskStatus = "succeeded"
+	// TaskDead means that the task failed MaxAttempts times and is not retried anymore.
+	TaskDead TaskStatus = "dead"
+)
+
+// Task is a unit of work stored in the queue.
+type Task struct {
+	ID      uuid.UUID       `json:"id"`
+	Kind    string          `json:"kind"`
+	Payload json.RawMessage `json:"payload"`
+	// DedupKey prevents enqueuing of a task while a pending task of the same kind has the same key.
+	DedupKey string     `json:"dedupKey,omitempty"`
+	Status   TaskStatus `json:"status"`
+	// Attempts is the number of handling attempts, including the current one.
+	Attempts    int `json:"attempts"`
+	MaxAttempts int `json:"maxAttempts"`
+	// RunAt is the time of the next attempt, or the end of the visibility timeout of the current one.
+	RunAt      time.Time  `json:"runAt"`
+	LastError  string     `json:"lastError,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
+
+// DB exposes access to the queue of tasks.
+//
+// architecture: Database
+type DB interface {
+	// Enqueue stores the task, if a pending task of the same kind has the same dedup key it's returned instead.
+	Enqueue(ctx context.Context, task Task) (Task, error)
+
+	// Get returns the task by id.
+	Get(ctx context.Context, id uuid.UUID) (Task, error)
+
+	// Claim returns up to limit pending tasks which are due, oldest first, and hides them from other
+	// claims until visibleAt, so tasks of a crashed worker are handled again when the timeout expires.
+	Claim(ctx context.Context, limit int, visibleAt time.Time) ([]Task, error)
+
+	// Complete marks the task as succeeded.
+	Complete(ctx context.Context, id uuid.UUID, finishedAt time.Time) error
+
+	// Retry schedules the next attempt of the task and records the error of the failed one.
+	Retry(ctx context.Context, id uuid.UUID, runAt time.Time, lastError string) error
+
+	// MarkDead marks the task as dead and records the error of the last attempt.
+	MarkDead(ctx context.Context, id uuid.UUID, finishedAt time.Time, lastError string) error
+
+	// DeleteFinished deletes succeeded and dead tasks finished before the given time and returns their number.
+	DeleteFinished(ctx context.Context, before time.Time) (int64, error)
+}
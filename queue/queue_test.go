@@ -0,0 +1,64 @@
+package queue_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"project_template"
+	"project_template/database/dbtesting"
+	"project_template/queue"
+)
+
+func TestQueue(t *testing.T) {
+	dbtesting.Run(t, func(ctx context.Context, t *testing.T, db project_template.DB) {
+		queueRepo := db.Queue()
+
+		task := queue.Task{
+			ID:          uuid.New(),
+			Kind:        "test",
+			Payload:     []byte(`{}`),
+			DedupKey:    "key",
+			MaxAttempts: 3,
+			RunAt:       time.Now(),
+			CreatedAt:   time.Now(),
+		}
+
+		t.Run("dedup", func(t *testing.T) {
+			stored, err := queueRepo.Enqueue(ctx, task)
+			require.NoError(t, err)
+			require.Equal(t, task.ID, stored.ID)
+
+			duplicate := task
+			duplicate.ID = uuid.New()
+			stored, err = queueRepo.Enqueue(ctx, duplicate)
+			require.NoError(t, err)
+			require.Equal(t, task.ID, stored.ID, "pending task with the same key is returned")
+		})
+
+		t.Run("claim", func(t *testing.T) {
+			claimed, err := queueRepo.Claim(ctx, 10, time.Now().Add(time.Minute))
+			require.NoError(t, err)
+			require.Len(t, claimed, 1)
+			require.Equal(t, 1, claimed[0].Attempts)
+
+			again, err := queueRepo.Claim(ctx, 10, time.Now().Add(time.Minute))
+			require.NoError(t, err)
+			require.Empty(t, again, "claimed tasks are invisible")
+		})
+
+		t.Run("complete", func(t *testing.T) {
+			require.NoError(t, queueRepo.Complete(ctx, task.ID, time.Now()))
+			stored, err := queueRepo.Get(ctx, task.ID)
+			require.NoError(t, err)
+			require.Equal(t, queue.TaskSucceeded, stored.Status)
+
+			deleted, err := queueRepo.DeleteFinished(ctx, time.Now().Add(time.Second))
+			require.NoError(t, err)
+			require.EqualValues(t, 1, deleted)
+		})
+	})
+}
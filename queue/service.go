@@ -0,0 +1,89 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"project_template/pkg/logger"
+)
+
+// Options changes how a task is enqueued.
+type Options struct {
+	// DedupKey makes the task unique among pending tasks of its kind.
+	DedupKey string
+	// RunAt schedules the task, it's run as soon as possible if it's zero.
+	RunAt time.Time
+	// MaxAttempts overrides the configured number of attempts.
+	MaxAttempts int
+}
+
+// Service enqueues tasks and exposes their state.
+//
+// architecture: Service
+type Service struct {
+	log    logger.Logger
+	config Config
+	db     DB
+}
+
+// NewService is a constructor for queue service.
+func NewService(log logger.Logger, config Config, db DB) *Service {
+	return &Service{
+		log:    log,
+		config: config,
+		db:     db,
+	}
+}
+
+// Enqueue stores the task with the payload encoded as json. If the dedup key is set and a pending task
+// of the same kind has the same key, no task is stored and the existing one is returned.
+func (service *Service) Enqueue(ctx context.Context, payload Payload, opts Options) (Task, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return Task{}, ErrQueue.Wrap(err)
+	}
+
+	now := time.Now()
+	task := Task{
+		ID:          uuid.New(),
+		Kind:        payload.TaskKind(),
+		Payload:     data,
+		DedupKey:    opts.DedupKey,
+		Status:      TaskPending,
+		MaxAttempts: opts.MaxAttempts,
+		RunAt:       opts.RunAt,
+		CreatedAt:   now,
+	}
+	if task.MaxAttempts <= 0 {
+		task.MaxAttempts = service.config.MaxAttempts
+	}
+	if task.RunAt.IsZero() {
+		task.RunAt = now
+	}
+
+	task, err = service.db.Enqueue(ctx, task)
+	return task, ErrQueue.Wrap(err)
+}
+
+// Get returns the task by id.
+func (service *Service) Get(ctx context.Context, id uuid.UUID) (Task, error) {
+	task, err := service.db.Get(ctx, id)
+	return task, ErrQueue.Wrap(err)
+}
+
+// Prune deletes succeeded and dead tasks older than the retention, it's run as a scheduled job.
+func (service *Service) Prune(ctx context.Context) error {
+	deleted, err := service.db.DeleteFinished(ctx, time.Now().Add(-service.config.Retention))
+	if err != nil {
+		return ErrQueue.Wrap(err)
+	}
+
+	if deleted > 0 {
+		service.log.Debug(fmt.Sprintf("deleted %d finished tasks", deleted))
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package queue_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"project_template"
+	"project_template/database/dbtesting"
+	"project_template/pkg/logger/zaplog"
+	"project_template/queue"
+)
+
+// sendEmail is a test task.
+type sendEmail struct {
+	To string `json:"to"`
+}
+
+func (sendEmail) TaskKind() string { return "email.send" }
+
+func TestService(t *testing.T) {
+	dbtesting.Run(t, func(ctx context.Context, t *testing.T, masterDB project_template.DB) {
+		config := queue.Config{MaxAttempts: 5, Retention: time.Hour}
+
+		db := masterDB.Queue()
+		service := queue.NewService(zaplog.NewLog(), config, db)
+
+		task, err := service.Enqueue(ctx, sendEmail{To: "a@example.com"}, queue.Options{})
+		require.NoError(t, err)
+		require.Equal(t, "email.send", task.Kind)
+		require.Equal(t, queue.TaskPending, task.Status)
+		require.Equal(t, 5, task.MaxAttempts)
+		require.JSONEq(t, `{"to": "a@example.com"}`, string(task.Payload))
+
+		stored, err := service.Get(ctx, task.ID)
+		require.NoError(t, err)
+		require.Equal(t, task.ID, stored.ID)
+
+		t.Run("dedup", func(t *testing.T) {
+			first, err := service.Enqueue(ctx, sendEmail{To: "b@example.com"}, queue.Options{DedupKey: "b", MaxAttempts: 1})
+			require.NoError(t, err)
+			require.Equal(t, 1, first.MaxAttempts)
+
+			second, err := service.Enqueue(ctx, sendEmail{To: "b@example.com"}, queue.Options{DedupKey: "b"})
+			require.NoError(t, err)
+			require.Equal(t, first.ID, second.ID, "pending task with the same key is returned")
+
+			require.NoError(t, db.Complete(ctx, first.ID, time.Now()))
+
+			third, err := service.Enqueue(ctx, sendEmail{To: "b@example.com"}, queue.Options{DedupKey: "b"})
+			require.NoError(t, err)
+			require.NotEqual(t, first.ID, third.ID, "finished tasks don't prevent enqueuing")
+		})
+
+		t.Run("scheduled", func(t *testing.T) {
+			runAt := time.Now().Add(time.Hour)
+			scheduled, err := service.Enqueue(ctx, sendEmail{To: "c@example.com"}, queue.Options{RunAt: runAt})
+			require.NoError(t, err)
+			require.True(t, runAt.Equal(scheduled.RunAt))
+
+			claimed, err := db.Claim(ctx, 100, time.Now().Add(time.Minute))
+			require.NoError(t, err)
+			for _, task := range claimed {
+				require.NotEqual(t, scheduled.ID, task.ID, "scheduled task is not due")
+			}
+		})
+
+		t.Run("prune", func(t *testing.T) {
+			require.NoError(t, db.MarkDead(ctx, task.ID, time.Now().Add(-2*time.Hour), "failure"))
+			require.NoError(t, service.Prune(ctx))
+
+			_, err := service.Get(ctx, task.ID)
+			require.True(t, queue.ErrNoTask.Has(err))
+		})
+	})
+}
@@ -0,0 +1,232 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/zeebo/errs"
+
+	"project_template/pkg/backoff"
+	"project_template/pkg/logger"
+)
+
+// ErrNoHandler indicates that no handler is registered for the kind of a task.
+var ErrNoHandler = errs.Class("no task handler")
+
+// Config contains configuration of the task queue.
+type Config struct {
+	Workers           int           `env:"QUEUE_WORKERS" validate:"min=1" envDefault:"4" desc:"number of tasks handled concurrently"`
+	PollInterval      time.Duration `env:"QUEUE_POLL_INTERVAL" envDefault:"1s" desc:"how often the queue is checked for due tasks"`
+	VisibilityTimeout time.Duration `env:"QUEUE_VISIBILITY_TIMEOUT" envDefault:"5m" desc:"timeout of a task handler, the claimed task is hidden from other workers until it expires"`
+	MaxAttempts       int           `env:"QUEUE_MAX_ATTEMPTS" validate:"min=1" envDefault:"10" desc:"number of attempts after which a task is marked as dead, unless the task sets its own"`
+	MinRetryDelay     time.Duration `env:"QUEUE_MIN_RETRY_DELAY" envDefault:"5s" desc:"delay before the first retry, doubled after every failed attempt"`
+	MaxRetryDelay     time.Duration `env:"QUEUE_MAX_RETRY_DELAY" envDefault:"1h" desc:"maximum delay between retries"`
+	DrainTimeout      time.Duration `env:"QUEUE_DRAIN_TIMEOUT" envDefault:"30s" desc:"how long running tasks are waited for on shutdown before they are canceled and retried later"`
+	Retention         time.Duration `env:"QUEUE_RETENTION" envDefault:"168h" desc:"how long succeeded and dead tasks are kept"`
+}
+
+// finishTimeout limits recording of the task result, which is done even if the worker is stopped.
+const finishTimeout = 10 * time.Second
+
+// handler decodes the payload and passes it to a typed handler.
+type handler func(ctx context.Context, payload json.RawMessage) error
+
+// Worker handles due tasks with a pool of Workers goroutines. Every task is handled at least once:
+// it's completed only after its handler succeeded, and if the worker crashes the task is handled again
+// when its visibility timeout expires, so handlers should be idempotent. Failed attempts are retried
+// with backoff until MaxAttempts is reached, then the task is marked as dead.
+//
+// architecture: Worker
+type Worker struct {
+	log    logger.Logger
+	config Config
+	db     DB
+
+	mu       sync.Mutex
+	handlers map[string]handler
+	// stop and stopped are set by Run, so Close can stop and drain it.
+	stop    context.CancelFunc
+	stopped chan struct{}
+}
+
+// NewWorker is a constructor for queue worker.
+func NewWorker(log logger.Logger, config Config, db DB) *Worker {
+	return &Worker{
+		log:      log,
+		config:   config,
+		db:       db,
+		handlers: make(map[string]handler),
+	}
+}
+
+// Register sets the handler of tasks of the payload's kind, handlers should be registered before the worker is run.
+func Register[T Payload](worker *Worker, handle func(ctx context.Context, payload T) error) error {
+	var zero T
+	kind := zero.TaskKind()
+
+	worker.mu.Lock()
+	defer worker.mu.Unlock()
+
+	if _, ok := worker.handlers[kind]; ok {
+		return ErrQueue.New("handler of %s is already registered", kind)
+	}
+
+	worker.handlers[kind] = func(ctx context.Context, data json.RawMessage) error {
+		var payload T
+		if err := json.Unmarshal(data, &payload); err != nil {
+			return ErrQueue.Wrap(err)
+		}
+		return handle(ctx, payload)
+	}
+	return nil
+}
+
+// Run handles tasks until the context is canceled or the worker is closed, then it waits for running
+// tasks up to DrainTimeout and cancels the rest, they are retried later.
+func (worker *Worker) Run(ctx context.Context) error {
+	ctx, stop := context.WithCancel(ctx)
+	defer stop()
+
+	stopped := make(chan struct{})
+	defer close(stopped)
+
+	worker.mu.Lock()
+	worker.stop, worker.stopped = stop, stopped
+	worker.mu.Unlock()
+
+	handlerCtx, cancelHandlers := context.WithCancel(context.Background())
+	defer cancelHandlers()
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, worker.config.Workers)
+	// freed is signaled when a task is finished, so the pool is refilled without waiting for the poll.
+	freed := make(chan struct{}, 1)
+
+	for {
+		free := cap(slots) - len(slots)
+
+		var claimed int
+		var err error
+		if free > 0 {
+			var tasks []Task
+			tasks, err = worker.db.Claim(ctx, free, time.Now().Add(worker.config.VisibilityTimeout))
+			if err != nil && ctx.Err() == nil {
+				worker.log.Error("could not claim tasks", ErrQueue.Wrap(err))
+			}
+
+			for _, task := range tasks {
+				slots <- struct{}{}
+				wg.Add(1)
+				go func(task Task) {
+					defer wg.Done()
+					worker.process(handlerCtx, task)
+
+					<-slots
+					select {
+					case freed <- struct{}{}:
+					default:
+					}
+				}(task)
+			}
+			claimed = len(tasks)
+		}
+
+		// all free slots were filled, so there may be more due tasks.
+		if err == nil && free > 0 && claimed == free {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			worker.drain(&wg, cancelHandlers)
+			return ctx.Err()
+		case <-freed:
+		case <-time.After(worker.config.PollInterval):
+		}
+	}
+}
+
+// drain waits for running tasks up to DrainTimeout and then cancels them.
+func (worker *Worker) drain(wg *sync.WaitGroup, cancel func()) {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(worker.config.DrainTimeout):
+		worker.log.Warn("running tasks are canceled, they will be retried")
+		cancel()
+		<-done
+	}
+}
+
+// process handles a single task and records its result.
+func (worker *Worker) process(ctx context.Context, task Task) {
+	worker.mu.Lock()
+	handle, ok := worker.handlers[task.Kind]
+	worker.mu.Unlock()
+
+	var err error
+	if ok {
+		handleCtx, cancel := context.WithTimeout(ctx, worker.config.VisibilityTimeout)
+		err = call(handleCtx, handle, task.Payload)
+		cancel()
+	} else {
+		err = ErrNoHandler.New("%s", task.Kind)
+	}
+
+	finishCtx, cancel := context.WithTimeout(context.Background(), finishTimeout)
+	defer cancel()
+
+	if err = worker.finish(finishCtx, task, err); err != nil {
+		worker.log.Error(fmt.Sprintf("could not record result of task %s %s", task.Kind, task.ID), ErrQueue.Wrap(err))
+	}
+}
+
+// finish completes the task, or schedules a retry if the handler failed.
+func (worker *Worker) finish(ctx context.Context, task Task, handleErr error) error {
+	if handleErr == nil {
+		return worker.db.Complete(ctx, task.ID, time.Now())
+	}
+
+	if task.Attempts >= task.MaxAttempts {
+		worker.log.Error(fmt.Sprintf("task %s %s is dead after %d attempts", task.Kind, task.ID, task.Attempts), ErrQueue.Wrap(handleErr))
+		return worker.db.MarkDead(ctx, task.ID, time.Now(), handleErr.Error())
+	}
+
+	delay := backoff.Exponential(task.Attempts, worker.config.MinRetryDelay, worker.config.MaxRetryDelay)
+	worker.log.Debug(fmt.Sprintf("task %s %s failed, attempt %d, retry in %s: %v", task.Kind, task.ID, task.Attempts, delay, handleErr))
+
+	return worker.db.Retry(ctx, task.ID, time.Now().Add(delay), handleErr.Error())
+}
+
+// call runs the handler, a panic is returned as an error.
+func call(ctx context.Context, handle handler, payload json.RawMessage) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errs.New("panic: %v\n%s", r, debug.Stack())
+		}
+	}()
+
+	return handle(ctx, payload)
+}
+
+// Close stops the worker and waits until running tasks are drained.
+func (worker *Worker) Close() error {
+	worker.mu.Lock()
+	stop, stopped := worker.stop, worker.stopped
+	worker.mu.Unlock()
+
+	if stop != nil {
+		stop()
+		<-stopped
+	}
+	return nil
+}
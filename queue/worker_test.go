@@ -0,0 +1,127 @@
+package queue_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/zeebo/errs"
+
+	"project_template"
+	"project_template/database/dbtesting"
+	"project_template/pkg/logger/zaplog"
+	"project_template/queue"
+)
+
+// failing is a test task whose handler fails.
+type failing struct{}
+
+func (failing) TaskKind() string { return "test.failing" }
+
+// blocking is a test task whose handler waits until its context is canceled.
+type blocking struct{}
+
+func (blocking) TaskKind() string { return "test.blocking" }
+
+func TestWorker(t *testing.T) {
+	dbtesting.Run(t, func(ctx context.Context, t *testing.T, masterDB project_template.DB) {
+		config := queue.Config{
+			Workers:           2,
+			PollInterval:      5 * time.Millisecond,
+			VisibilityTimeout: time.Minute,
+			MaxAttempts:       3,
+			MinRetryDelay:     time.Millisecond,
+			MaxRetryDelay:     time.Millisecond,
+			DrainTimeout:      50 * time.Millisecond,
+		}
+
+		db := masterDB.Queue()
+		service := queue.NewService(zaplog.NewLog(), config, db)
+		worker := queue.NewWorker(zaplog.NewLog(), config, db)
+
+		var mu sync.Mutex
+		var sent []string
+		require.NoError(t, queue.Register(worker, func(ctx context.Context, email sendEmail) error {
+			mu.Lock()
+			defer mu.Unlock()
+			sent = append(sent, email.To)
+			return nil
+		}))
+		require.NoError(t, queue.Register(worker, func(ctx context.Context, _ failing) error {
+			return errs.New("failure")
+		}))
+		require.NoError(t, queue.Register(worker, func(ctx context.Context, _ blocking) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}))
+		require.Error(t, queue.Register(worker, func(ctx context.Context, _ failing) error { return nil }))
+
+		var emails []queue.Task
+		for _, to := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+			task, err := service.Enqueue(ctx, sendEmail{To: to}, queue.Options{})
+			require.NoError(t, err)
+			emails = append(emails, task)
+		}
+		dead, err := service.Enqueue(ctx, failing{}, queue.Options{})
+		require.NoError(t, err)
+
+		stopped := make(chan error, 1)
+		go func() { stopped <- worker.Run(ctx) }()
+
+		waitStatus := func(task queue.Task, expected queue.TaskStatus) queue.Task {
+			require.Eventually(t, func() bool {
+				stored, err := service.Get(ctx, task.ID)
+				return err == nil && stored.Status == expected
+			}, 5*time.Second, 5*time.Millisecond)
+
+			stored, err := service.Get(ctx, task.ID)
+			require.NoError(t, err)
+			return stored
+		}
+
+		for _, task := range emails {
+			stored := waitStatus(task, queue.TaskSucceeded)
+			require.Equal(t, 1, stored.Attempts)
+			require.NotNil(t, stored.FinishedAt)
+		}
+		mu.Lock()
+		require.ElementsMatch(t, []string{"a@example.com", "b@example.com", "c@example.com"}, sent)
+		mu.Unlock()
+
+		stored := waitStatus(dead, queue.TaskDead)
+		require.Equal(t, 3, stored.Attempts)
+		require.Contains(t, stored.LastError, "failure")
+
+		t.Run("unknown kind", func(t *testing.T) {
+			unknown, err := db.Enqueue(ctx, queue.Task{
+				ID: uuid.New(), Kind: "test.unknown", Payload: []byte(`{}`), Status: queue.TaskPending,
+				MaxAttempts: 1, RunAt: time.Now(), CreatedAt: time.Now(),
+			})
+			require.NoError(t, err)
+
+			stored := waitStatus(unknown, queue.TaskDead)
+			require.Contains(t, stored.LastError, "no task handler")
+		})
+
+		t.Run("close drains", func(t *testing.T) {
+			task, err := service.Enqueue(ctx, blocking{}, queue.Options{})
+			require.NoError(t, err)
+
+			require.Eventually(t, func() bool {
+				stored, err := service.Get(ctx, task.ID)
+				return err == nil && stored.Attempts == 1
+			}, 5*time.Second, 5*time.Millisecond)
+
+			require.NoError(t, worker.Close())
+			require.ErrorIs(t, <-stopped, context.Canceled)
+
+			stored, err := service.Get(ctx, task.ID)
+			require.NoError(t, err)
+			require.Equal(t, queue.TaskPending, stored.Status, "canceled task is retried")
+			require.Contains(t, stored.LastError, "context canceled")
+		})
+	})
+}
@@ -0,0 +1,109 @@
+package ratelimit
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limit allows Requests per Period with a token bucket: the bucket holds up to Requests tokens,
+// every request takes one and they are refilled evenly during the Period. The zero Limit is unlimited.
+//
+// In config it's written as "requests/period", e.g. "100/1m", or "off".
+type Limit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// ParseLimit parses "requests/period", "off" or an empty string for the unlimited Limit.
+func ParseLimit(s string) (Limit, error) {
+	if s == "" || s == "off" {
+		return Limit{}, nil
+	}
+
+	requests, period, ok := strings.Cut(s, "/")
+	if !ok {
+		return Limit{}, ErrRateLimit.New("invalid limit %q, expected requests/period", s)
+	}
+
+	var limit Limit
+	var err error
+	if limit.Requests, err = strconv.Atoi(requests); err != nil || limit.Requests <= 0 {
+		return Limit{}, ErrRateLimit.New("invalid number of requests in limit %q", s)
+	}
+	if limit.Period, err = time.ParseDuration(period); err != nil || limit.Period <= 0 {
+		return Limit{}, ErrRateLimit.New("invalid period in limit %q", s)
+	}
+	return limit, nil
+}
+
+// UnmarshalText parses the limit from config.
+func (limit *Limit) UnmarshalText(text []byte) (err error) {
+	*limit, err = ParseLimit(string(text))
+	return err
+}
+
+// String formats the limit as it's written in config.
+func (limit Limit) String() string {
+	if limit.Unlimited() {
+		return "off"
+	}
+	return strconv.Itoa(limit.Requests) + "/" + limit.Period.String()
+}
+
+// Unlimited checks if requests aren't limited.
+func (limit Limit) Unlimited() bool {
+	return limit.Requests <= 0 || limit.Period <= 0
+}
+
+// Bucket is the state of a token bucket, the zero Bucket is a new one which is full.
+type Bucket struct {
+	Tokens    float64
+	UpdatedAt time.Time
+}
+
+// Result describes the bucket after a request.
+type Result struct {
+	Allowed bool
+	// Limit is the capacity of the bucket.
+	Limit int
+	// Remaining is the number of requests which are allowed right away.
+	Remaining int
+	// RetryAfter is when the next request is allowed, it's zero if the request is allowed.
+	RetryAfter time.Duration
+	// Reset is when the bucket is full again.
+	Reset time.Duration
+}
+
+// take refills the bucket for the time passed since its update and takes a token if there is one.
+func (limit Limit) take(bucket Bucket, now time.Time) (Bucket, Result) {
+	capacity := float64(limit.Requests)
+	perSecond := capacity / limit.Period.Seconds()
+
+	tokens := capacity
+	if !bucket.UpdatedAt.IsZero() {
+		// clocks of replicas may differ, a bucket is never refilled for time in the future.
+		if now.Before(bucket.UpdatedAt) {
+			now = bucket.UpdatedAt
+		}
+		tokens = math.Min(capacity, bucket.Tokens+now.Sub(bucket.UpdatedAt).Seconds()*perSecond)
+	}
+
+	result := Result{Limit: limit.Requests}
+	if tokens >= 1 {
+		tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = seconds((1 - tokens) / perSecond)
+	}
+	result.Remaining = int(math.Floor(tokens))
+	result.Reset = seconds((capacity - tokens) / perSecond)
+
+	return Bucket{Tokens: tokens, UpdatedAt: now}, result
+}
+
+// seconds converts fractional seconds to duration.
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
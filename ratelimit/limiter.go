@@ -0,0 +1,87 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/zeebo/errs"
+
+	"project_template/pkg/logger"
+)
+
+// ErrRateLimit indicates that there was an error in the rate limiter.
+var ErrRateLimit = errs.Class("rate limit error")
+
+// Backends of buckets.
+const (
+	// BackendMemory keeps buckets in the process, every replica limits requests on its own.
+	BackendMemory = "memory"
+	// BackendPostgres keeps buckets in the database, so they are shared by replicas.
+	BackendPostgres = "postgres"
+)
+
+// Config contains configuration of the rate limiter, limits are set by the users of the limiter.
+type Config struct {
+	Backend   string        `env:"RATE_LIMIT_BACKEND" validate:"oneof=memory postgres" envDefault:"memory" desc:"where token buckets are kept: memory of every replica or postgres shared by replicas"`
+	Retention time.Duration `env:"RATE_LIMIT_RETENTION" envDefault:"1h" desc:"how long unused buckets are kept, it should be longer than the longest period of limits"`
+}
+
+// DB stores token buckets.
+//
+// architecture: Database
+type DB interface {
+	// Update replaces the bucket of the key with the result of update, the bucket isn't changed by others
+	// in the meantime. The zero Bucket is passed if the key has none.
+	Update(ctx context.Context, key string, update func(bucket Bucket) Bucket) error
+	// DeleteBuckets deletes buckets which weren't updated since before.
+	DeleteBuckets(ctx context.Context, before time.Time) (int64, error)
+}
+
+// Limiter limits requests by keys, e.g. by client ip.
+//
+// architecture: Service
+type Limiter struct {
+	log    logger.Logger
+	config Config
+	db     DB
+}
+
+// NewLimiter is a constructor for rate limiter.
+func NewLimiter(log logger.Logger, config Config, db DB) *Limiter {
+	return &Limiter{
+		log:    log,
+		config: config,
+		db:     db,
+	}
+}
+
+// Allow takes a token from the bucket of the key, the request is allowed if there was one.
+func (limiter *Limiter) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	if limit.Unlimited() {
+		return Result{Allowed: true}, nil
+	}
+
+	var result Result
+	err := limiter.db.Update(ctx, key, func(bucket Bucket) Bucket {
+		bucket, result = limit.take(bucket, time.Now())
+		return bucket
+	})
+	if err != nil {
+		return Result{}, ErrRateLimit.Wrap(err)
+	}
+	return result, nil
+}
+
+// Prune deletes buckets unused for the retention, it's run as a scheduled job.
+func (limiter *Limiter) Prune(ctx context.Context) error {
+	deleted, err := limiter.db.DeleteBuckets(ctx, time.Now().Add(-limiter.config.Retention))
+	if err != nil {
+		return ErrRateLimit.Wrap(err)
+	}
+
+	if deleted > 0 {
+		limiter.log.Debug(fmt.Sprintf("deleted %d rate limit buckets", deleted))
+	}
+	return nil
+}
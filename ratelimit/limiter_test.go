@@ -0,0 +1,134 @@
+package ratelimit_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"project_template/pkg/logger/zaplog"
+	"project_template/ratelimit"
+)
+
+func TestParseLimit(t *testing.T) {
+	limit, err := ratelimit.ParseLimit("100/1m")
+	require.NoError(t, err)
+	require.Equal(t, ratelimit.Limit{Requests: 100, Period: time.Minute}, limit)
+	require.Equal(t, "100/1m0s", limit.String())
+
+	for _, off := range []string{"", "off"} {
+		limit, err = ratelimit.ParseLimit(off)
+		require.NoError(t, err)
+		require.True(t, limit.Unlimited())
+		require.Equal(t, "off", limit.String())
+	}
+
+	for _, invalid := range []string{"100", "0/1m", "-1/1m", "x/1m", "100/0s", "100/x"} {
+		_, err = ratelimit.ParseLimit(invalid)
+		require.Error(t, err, invalid)
+		require.True(t, ratelimit.ErrRateLimit.Has(err))
+	}
+
+	var parsed ratelimit.Limit
+	require.NoError(t, parsed.UnmarshalText([]byte("5/1s")))
+	require.Equal(t, ratelimit.Limit{Requests: 5, Period: time.Second}, parsed)
+}
+
+func TestLimiter(t *testing.T) {
+	ctx := context.Background()
+	db := ratelimit.NewMemoryDB()
+	limiter := ratelimit.NewLimiter(zaplog.NewLog(), ratelimit.Config{Retention: time.Hour}, db)
+
+	limit := ratelimit.Limit{Requests: 2, Period: time.Hour}
+
+	t.Run("bucket is full at first", func(t *testing.T) {
+		result, err := limiter.Allow(ctx, "a", limit)
+		require.NoError(t, err)
+		require.True(t, result.Allowed)
+		require.Equal(t, 2, result.Limit)
+		require.Equal(t, 1, result.Remaining)
+		require.Zero(t, result.RetryAfter)
+		require.InDelta(t, 30*time.Minute, result.Reset, float64(time.Second))
+
+		result, err = limiter.Allow(ctx, "a", limit)
+		require.NoError(t, err)
+		require.True(t, result.Allowed)
+		require.Equal(t, 0, result.Remaining)
+	})
+
+	t.Run("empty bucket rejects requests", func(t *testing.T) {
+		result, err := limiter.Allow(ctx, "a", limit)
+		require.NoError(t, err)
+		require.False(t, result.Allowed)
+		require.Equal(t, 0, result.Remaining)
+		require.InDelta(t, 30*time.Minute, result.RetryAfter, float64(time.Second))
+		require.InDelta(t, time.Hour, result.Reset, float64(time.Second))
+	})
+
+	t.Run("keys have own buckets", func(t *testing.T) {
+		result, err := limiter.Allow(ctx, "b", limit)
+		require.NoError(t, err)
+		require.True(t, result.Allowed)
+	})
+
+	t.Run("tokens are refilled", func(t *testing.T) {
+		fast := ratelimit.Limit{Requests: 1, Period: 20 * time.Millisecond}
+
+		result, err := limiter.Allow(ctx, "c", fast)
+		require.NoError(t, err)
+		require.True(t, result.Allowed)
+
+		result, err = limiter.Allow(ctx, "c", fast)
+		require.NoError(t, err)
+		require.False(t, result.Allowed)
+
+		time.Sleep(result.RetryAfter)
+
+		result, err = limiter.Allow(ctx, "c", fast)
+		require.NoError(t, err)
+		require.True(t, result.Allowed)
+	})
+
+	t.Run("concurrent requests take tokens one by one", func(t *testing.T) {
+		limit := ratelimit.Limit{Requests: 10, Period: time.Hour}
+
+		results := make([]ratelimit.Result, 50)
+		errors := make([]error, 50)
+		var wg sync.WaitGroup
+		for i := range results {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				results[i], errors[i] = limiter.Allow(ctx, "d", limit)
+			}(i)
+		}
+		wg.Wait()
+
+		var allowed int
+		for i, result := range results {
+			require.NoError(t, errors[i])
+			if result.Allowed {
+				allowed++
+			}
+		}
+		require.Equal(t, 10, allowed)
+	})
+
+	t.Run("unlimited", func(t *testing.T) {
+		for i := 0; i < 10; i++ {
+			result, err := limiter.Allow(ctx, "e", ratelimit.Limit{})
+			require.NoError(t, err)
+			require.True(t, result.Allowed)
+		}
+	})
+
+	t.Run("prune", func(t *testing.T) {
+		require.NoError(t, limiter.Prune(ctx))
+
+		deleted, err := db.DeleteBuckets(ctx, time.Now())
+		require.NoError(t, err)
+		require.EqualValues(t, 4, deleted, "buckets are kept for the retention")
+	})
+}
@@ -0,0 +1,47 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ensures that MemoryDB implements DB.
+var _ DB = (*MemoryDB)(nil)
+
+// MemoryDB keeps buckets in memory of the process.
+//
+// architecture: Database
+type MemoryDB struct {
+	mu      sync.Mutex
+	buckets map[string]Bucket
+}
+
+// NewMemoryDB is a constructor for in-memory buckets.
+func NewMemoryDB() *MemoryDB {
+	return &MemoryDB{buckets: make(map[string]Bucket)}
+}
+
+// Update replaces the bucket of the key with the result of update.
+func (db *MemoryDB) Update(ctx context.Context, key string, update func(bucket Bucket) Bucket) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.buckets[key] = update(db.buckets[key])
+	return nil
+}
+
+// DeleteBuckets deletes buckets which weren't updated since before.
+func (db *MemoryDB) DeleteBuckets(ctx context.Context, before time.Time) (int64, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var deleted int64
+	for key, bucket := range db.buckets {
+		if bucket.UpdatedAt.Before(before) {
+			delete(db.buckets, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
@@ -0,0 +1,36 @@
+package ratelimit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"project_template"
+	"project_template/database/dbtesting"
+	"project_template/ratelimit"
+)
+
+func TestRateLimits(t *testing.T) {
+	dbtesting.Run(t, func(ctx context.Context, t *testing.T, db project_template.DB) {
+		buckets := db.RateLimits()
+
+		err := buckets.Update(ctx, "key", func(bucket ratelimit.Bucket) ratelimit.Bucket {
+			require.True(t, bucket.UpdatedAt.IsZero(), "new bucket is zero")
+			return ratelimit.Bucket{Tokens: 1.5, UpdatedAt: time.Now()}
+		})
+		require.NoError(t, err)
+
+		err = buckets.Update(ctx, "key", func(bucket ratelimit.Bucket) ratelimit.Bucket {
+			require.Equal(t, 1.5, bucket.Tokens)
+			require.False(t, bucket.UpdatedAt.IsZero())
+			return bucket
+		})
+		require.NoError(t, err)
+
+		deleted, err := buckets.DeleteBuckets(ctx, time.Now().Add(time.Second))
+		require.NoError(t, err)
+		require.EqualValues(t, 1, deleted)
+	})
+}
@@ -2,40 +2,93 @@ package project_template
 
 import (
 	"context"
-	"errors"
+	"database/sql"
 	"github.com/zeebo/errs"
 	"golang.org/x/sync/errgroup"
 	"net"
+	"time"
 
+	"project_template/admin/adminserver"
 	"project_template/console/consoleserver"
 	"project_template/dummy"
+	"project_template/events"
+	"project_template/jobs"
+	"project_template/pkg/lifecycle"
 	"project_template/pkg/logger"
+	"project_template/queue"
+	"project_template/ratelimit"
+	"project_template/webhooks"
 )
 
 type DB interface {
 	// Dummy provides access to dummy db.
 	Dummy() dummy.DB
 
+	// Outbox provides access to the outbox of domain events.
+	Outbox() events.DB
+
+	// Webhooks provides access to webhook subscriptions and deliveries.
+	Webhooks() webhooks.DB
+
+	// Jobs provides the leader election of jobs and the history of their runs.
+	Jobs() jobs.DB
+
+	// Queue provides access to the queue of tasks.
+	Queue() queue.DB
+
+	// RateLimits provides access to token buckets of the rate limiter.
+	RateLimits() ratelimit.DB
+
+	// Stats returns statistics of the connection pool.
+	Stats() sql.DBStats
+
 	// Close closes underlying db connection.
 	Close() error
 
-	// ExecuteMigrations executes migrations by path in database.
-	ExecuteMigrations(ctx context.Context, migrationsPath string, isUp bool) error
+	// Migrations provides management of schema migrations located by path,
+	// migrations embedded into the binary are used if path is empty.
+	Migrations(migrationsPath string) Migrations
 }
 
+// DBConfig contains database connection credentials.
 type DBConfig struct {
-	User string `env:"DB_USER" validate:"required"`
-	Pass string `env:"DB_PASS" validate:"required"`
-	Name string `env:"DB_NAME" validate:"required"`
+	User string `env:"DB_USER" validate:"required" desc:"database user name"`
+	Pass string `env:"DB_PASS" validate:"required" secret:"true" desc:"database user password"`
+	Name string `env:"DB_NAME" validate:"required" desc:"database name"`
 }
 
 // Config contains the global config.
 type Config struct {
 
+	// Admin keeps the admin server config.
+	Admin struct {
+		Server adminserver.Config
+	}
+
 	// Console keeps the console server config
 	Console struct {
 		Server consoleserver.Config
 	}
+
+	// Dummy keeps the dummy changes stream config.
+	Dummy struct {
+		Stream dummy.StreamConfig
+	}
+
+	// Events keeps the outbox relay config.
+	Events events.Config
+
+	// Webhooks keeps the webhook deliveries config.
+	Webhooks webhooks.Config
+
+	// Jobs keeps the job scheduler config.
+	Jobs jobs.Config
+
+	// Queue keeps the task queue config.
+	Queue queue.Config
+
+	// RateLimit keeps the rate limiter config.
+	RateLimit ratelimit.Config
 }
 
 // TemplateProject is the representation of the project.
@@ -43,9 +96,40 @@ type TemplateProject struct {
 	Log      logger.Logger
 	Database DB
 
+	// Components are run and closed with the project, every subsystem adds its components during setup.
+	Components *lifecycle.Group
+
 	// Dummy exposes dummy related logic.
 	Dummy struct {
 		Service *dummy.Service
+		Stream  *dummy.Stream
+	}
+
+	// Events publishes domain events from the outbox.
+	Events struct {
+		Relay *events.Relay
+	}
+
+	// Webhooks exposes webhook subscriptions logic and delivers events to subscribers.
+	Webhooks struct {
+		Service    *webhooks.Service
+		Dispatcher *webhooks.Dispatcher
+	}
+
+	// Jobs runs periodic work, e.g. pruning of old data.
+	Jobs struct {
+		Scheduler *jobs.Scheduler
+	}
+
+	// Queue offloads slow work from requests to background workers.
+	Queue struct {
+		Service *queue.Service
+		Worker  *queue.Worker
+	}
+
+	// RateLimit limits requests of clients.
+	RateLimit struct {
+		Limiter *ratelimit.Limiter
 	}
 
 	// Console web server with web UI.
@@ -53,18 +137,105 @@ type TemplateProject struct {
 		Listener net.Listener
 		Endpoint *consoleserver.Server
 	}
+
+	// Admin web server with metrics and debug endpoints.
+	Admin struct {
+		Listener net.Listener
+		Endpoint *adminserver.Server
+	}
 }
 
 func New(config Config, logger logger.Logger, db DB) (*TemplateProject, error) {
 	var err error
 
 	app := &TemplateProject{
-		Log:      logger,
-		Database: db,
+		Log:        logger,
+		Database:   db,
+		Components: lifecycle.NewGroup(logger),
 	}
 
 	{ // dummy setup.
 		app.Dummy.Service = dummy.NewService(db.Dummy())
+		app.Dummy.Stream = dummy.NewStream(logger, config.Dummy.Stream, db.Dummy())
+
+		app.Components.Add(lifecycle.Item{
+			Name:  "dummy.stream",
+			Run:   app.Dummy.Stream.Run,
+			Close: app.Dummy.Stream.Close,
+		})
+	}
+
+	{ // webhooks setup.
+		app.Webhooks.Service = webhooks.NewService(config.Webhooks, db.Webhooks())
+		app.Webhooks.Dispatcher = webhooks.NewDispatcher(logger, config.Webhooks, db.Webhooks())
+
+		app.Components.Add(lifecycle.Item{
+			Name:  "webhooks.dispatcher",
+			Run:   app.Webhooks.Dispatcher.Run,
+			Close: app.Webhooks.Dispatcher.Close,
+		})
+	}
+
+	{ // events setup.
+		publisher, err := events.NewPublisher(logger, config.Events)
+		if err != nil {
+			return nil, err
+		}
+
+		// events are also passed to webhook subscriptions.
+		publisher = events.NewMultiPublisher(publisher, webhooks.NewPublisher(app.Webhooks.Service))
+
+		app.Events.Relay = events.NewRelay(logger, config.Events, db.Outbox(), publisher)
+
+		app.Components.Add(lifecycle.Item{
+			Name:  "events.relay",
+			Run:   app.Events.Relay.Run,
+			Close: app.Events.Relay.Close,
+		})
+	}
+
+	{ // queue setup.
+		app.Queue.Service = queue.NewService(logger, config.Queue, db.Queue())
+		app.Queue.Worker = queue.NewWorker(logger, config.Queue, db.Queue())
+
+		app.Components.Add(lifecycle.Item{
+			Name:  "queue.worker",
+			Run:   app.Queue.Worker.Run,
+			Close: app.Queue.Worker.Close,
+		})
+	}
+
+	{ // rate limit setup.
+		var buckets ratelimit.DB = ratelimit.NewMemoryDB()
+		if config.RateLimit.Backend == ratelimit.BackendPostgres {
+			buckets = db.RateLimits()
+		}
+
+		app.RateLimit.Limiter = ratelimit.NewLimiter(logger, config.RateLimit, buckets)
+	}
+
+	{ // jobs setup.
+		app.Jobs.Scheduler = jobs.NewScheduler(logger, config.Jobs, db.Jobs())
+
+		for _, job := range []jobs.Job{
+			{Name: "dummy.prune-changes", Schedule: jobs.Every(time.Hour), Run: app.Dummy.Stream.Prune},
+			{Name: "events.prune-outbox", Schedule: jobs.Every(time.Hour), Run: app.Events.Relay.Prune},
+			{Name: "queue.prune-tasks", Schedule: jobs.Every(time.Hour), Run: app.Queue.Service.Prune},
+			{Name: "ratelimit.prune-buckets", Schedule: jobs.Every(time.Hour), Run: app.RateLimit.Limiter.Prune},
+			{Name: "jobs.prune-runs", Schedule: jobs.MustParseCron("@daily"), Run: app.Jobs.Scheduler.Prune},
+		} {
+			if err = app.Jobs.Scheduler.Register(job); err != nil {
+				return nil, err
+			}
+		}
+
+		// jobs use these components, so the scheduler is stopped before them.
+		app.Components.Add(lifecycle.Item{
+			Name:  "jobs.scheduler",
+			Deps:  []string{"dummy.stream", "events.relay", "queue.worker"},
+			Run:   app.Jobs.Scheduler.Run,
+			Close: app.Jobs.Scheduler.Close,
+		})
 	}
 
 	{ // console setup.
@@ -73,12 +244,38 @@ func New(config Config, logger logger.Logger, db DB) (*TemplateProject, error) {
 			return nil, err
 		}
 
-		app.Console.Endpoint = consoleserver.NewServer(
-			config.Console.Server,
-			logger,
-			app.Console.Listener,
-			app.Dummy.Service,
-		)
+		app.Console.Endpoint, err = consoleserver.NewServer(config.Console.Server, logger, app.Console.Listener, consoleserver.Services{
+			Dummy:       app.Dummy.Service,
+			DummyStream: app.Dummy.Stream,
+			Webhooks:    app.Webhooks.Service,
+			Jobs:        app.Jobs.Scheduler,
+			RateLimiter: app.RateLimit.Limiter,
+		})
+		if err != nil {
+			return nil, errs.Combine(err, app.Console.Listener.Close())
+		}
+
+		app.Components.Add(lifecycle.Item{
+			Name:  "console.server",
+			Deps:  []string{"dummy.stream", "jobs.scheduler"},
+			Run:   app.Console.Endpoint.Run,
+			Close: app.Console.Endpoint.Close,
+		})
+	}
+
+	{ // admin setup.
+		app.Admin.Listener, err = net.Listen("tcp", config.Admin.Server.Address)
+		if err != nil {
+			return nil, errs.Combine(err, app.Console.Listener.Close())
+		}
+
+		app.Admin.Endpoint = adminserver.NewServer(config.Admin.Server, logger, app.Admin.Listener, config, db)
+
+		app.Components.Add(lifecycle.Item{
+			Name:  "admin.server",
+			Run:   app.Admin.Endpoint.Run,
+			Close: app.Admin.Endpoint.Close,
+		})
 	}
 
 	return app, nil
@@ -88,27 +285,14 @@ func New(config Config, logger logger.Logger, db DB) (*TemplateProject, error) {
 func (app *TemplateProject) Run(ctx context.Context) error {
 	group, ctx := errgroup.WithContext(ctx)
 
-	group.Go(func() error {
-		return ignoreCancel(app.Console.Endpoint.Run(ctx))
-	})
+	if err := app.Components.Run(ctx, group); err != nil {
+		return err
+	}
 
 	return group.Wait()
 }
 
 // Close closes all the resources.
 func (app *TemplateProject) Close() error {
-	var errlist errs.Group
-
-	errlist.Add(app.Console.Endpoint.Close())
-
-	return errlist.Err()
-}
-
-// we ignore cancellation and stopping errors since they are expected.
-func ignoreCancel(err error) error {
-	if errors.Is(err, context.Canceled) {
-		return nil
-	}
-
-	return err
+	return app.Components.Close()
 }
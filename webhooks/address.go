@@ -0,0 +1,53 @@
+package webhooks
+
+import (
+	"net"
+	"strings"
+	"syscall"
+
+	"github.com/zeebo/errs"
+)
+
+// ErrForbiddenAddress indicates that a subscription url points to the local network.
+var ErrForbiddenAddress = errs.Class("forbidden webhook address")
+
+// checkHost rejects hosts of subscription urls which point to the local network: localhost names
+// and loopback, link-local, private and unspecified ip addresses. Other host names are checked
+// when a delivery connects, see dialControl.
+func checkHost(host string) error {
+	name := strings.TrimSuffix(strings.ToLower(host), ".")
+	if name == "localhost" || strings.HasSuffix(name, ".localhost") {
+		return ErrForbiddenAddress.New("%s is a local host", host)
+	}
+
+	if ip := net.ParseIP(name); ip != nil {
+		return checkIP(ip)
+	}
+	return nil
+}
+
+// checkIP rejects loopback, link-local, private and unspecified addresses, e.g. 127.0.0.1,
+// 169.254.169.254 of cloud metadata services or 10.0.0.1.
+func checkIP(ip net.IP) error {
+	if ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() ||
+		ip.IsPrivate() || ip.IsUnspecified() {
+		return ErrForbiddenAddress.New("%s is a loopback, link-local or private address", ip)
+	}
+	return nil
+}
+
+// dialControl rejects connections to forbidden addresses. It's called with the resolved address
+// right before connecting, so host names which resolve to local addresses are rejected too,
+// even if they resolved to public ones when the subscription was created.
+func dialControl(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return ErrForbiddenAddress.Wrap(err)
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return ErrForbiddenAddress.New("%s is not an ip address", host)
+	}
+	return checkIP(ip)
+}
@@ -0,0 +1,172 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"project_template/pkg/backoff"
+	"project_template/pkg/logger"
+)
+
+// Config contains configuration of webhook deliveries.
+type Config struct {
+	Timeout       time.Duration `env:"WEBHOOKS_TIMEOUT" envDefault:"10s" desc:"timeout of a single delivery attempt"`
+	PollInterval  time.Duration `env:"WEBHOOKS_POLL_INTERVAL" envDefault:"1s" desc:"how often pending deliveries are checked"`
+	BatchSize     int           `env:"WEBHOOKS_BATCH_SIZE" validate:"min=1" envDefault:"100" desc:"number of deliveries claimed at once"`
+	Lease         time.Duration `env:"WEBHOOKS_LEASE" envDefault:"1m" desc:"time claimed deliveries are hidden from other workers, they are attempted again if it expires"`
+	MaxAttempts   int           `env:"WEBHOOKS_MAX_ATTEMPTS" validate:"min=1" envDefault:"10" desc:"number of attempts after which a delivery is marked as dead"`
+	MinRetryDelay time.Duration `env:"WEBHOOKS_MIN_RETRY_DELAY" envDefault:"10s" desc:"delay before the first retry, doubled after every failed attempt"`
+	MaxRetryDelay time.Duration `env:"WEBHOOKS_MAX_RETRY_DELAY" envDefault:"1h" desc:"maximum delay between retries"`
+
+	AllowPrivateNetworks bool `env:"WEBHOOKS_ALLOW_PRIVATE_NETWORKS" desc:"allow subscription urls on localhost, loopback, link-local and private addresses, e.g. for local development"`
+}
+
+// Dispatcher posts pending deliveries to subscription urls. Every delivery is signed with the
+// subscription secret, failed attempts are retried with backoff until MaxAttempts is reached,
+// then the delivery is marked as dead.
+//
+// architecture: Worker
+type Dispatcher struct {
+	log    logger.Logger
+	config Config
+	db     DB
+	client *http.Client
+}
+
+// NewDispatcher is a constructor for webhook dispatcher. Unless private networks are allowed,
+// connections to local network addresses are rejected after host names are resolved.
+func NewDispatcher(log logger.Logger, config Config, db DB) *Dispatcher {
+	dialer := &net.Dialer{Timeout: config.Timeout}
+	if !config.AllowPrivateNetworks {
+		dialer.Control = dialControl
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// a proxy would connect to receivers instead of the dialer, so addresses couldn't be checked.
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &Dispatcher{
+		log:    log,
+		config: config,
+		db:     db,
+		client: &http.Client{
+			Transport: transport,
+			// redirects are not followed, the subscription url should point to the receiver.
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		},
+	}
+}
+
+// Run delivers pending deliveries until the context is canceled.
+func (dispatcher *Dispatcher) Run(ctx context.Context) error {
+	for {
+		claimed, err := dispatcher.Process(ctx)
+		if err != nil && ctx.Err() == nil {
+			dispatcher.log.Error("could not process webhook deliveries", ErrWebhooks.Wrap(err))
+		}
+
+		// a full batch means there may be more due deliveries.
+		if err == nil && claimed == dispatcher.config.BatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(dispatcher.config.PollInterval):
+		}
+	}
+}
+
+// Process claims a batch of due deliveries and attempts them, it returns the number of claimed deliveries.
+func (dispatcher *Dispatcher) Process(ctx context.Context) (int, error) {
+	deliveries, err := dispatcher.db.ClaimDeliveries(ctx, dispatcher.config.BatchSize, time.Now().Add(dispatcher.config.Lease))
+	if err != nil {
+		return 0, ErrWebhooks.Wrap(err)
+	}
+
+	subscriptions := make(map[uuid.UUID]Subscription)
+	for _, delivery := range deliveries {
+		subscription, ok := subscriptions[delivery.SubscriptionID]
+		if !ok {
+			if subscription, err = dispatcher.db.GetSubscription(ctx, delivery.SubscriptionID); err != nil {
+				return len(deliveries), ErrWebhooks.Wrap(err)
+			}
+			subscriptions[subscription.ID] = subscription
+		}
+
+		if err = dispatcher.deliver(ctx, subscription, delivery); err != nil {
+			return len(deliveries), err
+		}
+	}
+
+	return len(deliveries), nil
+}
+
+// deliver makes a single delivery attempt and records its result.
+func (dispatcher *Dispatcher) deliver(ctx context.Context, subscription Subscription, delivery Delivery) error {
+	attempt := dispatcher.post(ctx, subscription, delivery)
+	if attempt.Error == "" {
+		return ErrWebhooks.Wrap(dispatcher.db.MarkDelivered(ctx, delivery.ID, attempt))
+	}
+
+	if delivery.Attempts >= dispatcher.config.MaxAttempts {
+		dispatcher.log.Warn(fmt.Sprintf("webhook delivery %s of event %s to %s is dead after %d attempts: %s",
+			delivery.ID, delivery.EventID, subscription.URL, delivery.Attempts, attempt.Error))
+		return ErrWebhooks.Wrap(dispatcher.db.MarkDead(ctx, delivery.ID, attempt))
+	}
+
+	delay := backoff.Exponential(delivery.Attempts, dispatcher.config.MinRetryDelay, dispatcher.config.MaxRetryDelay)
+	dispatcher.log.Debug(fmt.Sprintf("webhook delivery %s to %s failed, attempt %d, retry in %s: %s",
+		delivery.ID, subscription.URL, delivery.Attempts, delay, attempt.Error))
+
+	return ErrWebhooks.Wrap(dispatcher.db.Retry(ctx, delivery.ID, attempt, time.Now().Add(delay)))
+}
+
+// post sends the signed delivery, any 2xx response means it was accepted.
+func (dispatcher *Dispatcher) post(ctx context.Context, subscription Subscription, delivery Delivery) Attempt {
+	ctx, cancel := context.WithTimeout(ctx, dispatcher.config.Timeout)
+	defer cancel()
+
+	attempt := Attempt{At: time.Now()}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-ID", delivery.ID.String())
+	req.Header.Set("X-Event-ID", delivery.EventID.String())
+	req.Header.Set("X-Event-Type", delivery.EventType)
+	req.Header.Set(SignatureHeader, Sign(subscription.Secret, attempt.At, delivery.Payload))
+
+	resp, err := dispatcher.client.Do(req)
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	attempt.ResponseStatus = resp.StatusCode
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		attempt.Error = "receiver responded with " + resp.Status
+	}
+
+	return attempt
+}
+
+// Close closes idle connections to receivers.
+func (dispatcher *Dispatcher) Close() error {
+	dispatcher.client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,184 @@
+package webhooks_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"project_template"
+	"project_template/database/dbtesting"
+	"project_template/events"
+	"project_template/pkg/logger/zaplog"
+	"project_template/webhooks"
+)
+
+func TestDispatcher(t *testing.T) {
+	dbtesting.Run(t, func(ctx context.Context, t *testing.T, db project_template.DB) {
+		testDispatcher(ctx, t, db.Webhooks())
+	})
+}
+
+func testDispatcher(ctx context.Context, t *testing.T, db webhooks.DB) {
+	config := webhooks.Config{
+		Timeout:       time.Second,
+		BatchSize:     10,
+		Lease:         time.Minute,
+		MaxAttempts:   3,
+		MinRetryDelay: 50 * time.Millisecond,
+		MaxRetryDelay: time.Second,
+		// the receiver listens on the loopback address.
+		AllowPrivateNetworks: true,
+	}
+
+	status := http.StatusOK
+	var received []events.Event
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.NoError(t, webhooks.Verify("secret", r.Header.Get(webhooks.SignatureHeader), body, time.Now(), time.Minute))
+
+		var event events.Event
+		require.NoError(t, json.Unmarshal(body, &event))
+		require.Equal(t, event.ID.String(), r.Header.Get("X-Event-ID"))
+		require.Equal(t, event.Type, r.Header.Get("X-Event-Type"))
+		require.NotEmpty(t, r.Header.Get("X-Webhook-ID"))
+
+		received = append(received, event)
+		w.WriteHeader(status)
+	}))
+	defer receiver.Close()
+
+	service := webhooks.NewService(config, db)
+	subscription, err := service.Create(ctx, receiver.URL, nil, "secret")
+	require.NoError(t, err)
+
+	dispatcher := webhooks.NewDispatcher(zaplog.NewLog(), config, db)
+	defer func() { require.NoError(t, dispatcher.Close()) }()
+
+	// find returns the delivery of the event to the subscription.
+	find := func(subscriptionID, eventID uuid.UUID) webhooks.Delivery {
+		deliveries, err := service.Deliveries(ctx, subscriptionID, webhooks.MaxDeliveriesLimit, 0)
+		require.NoError(t, err)
+		for _, delivery := range deliveries {
+			if delivery.EventID == eventID {
+				return delivery
+			}
+		}
+		require.FailNow(t, "delivery is not found")
+		return webhooks.Delivery{}
+	}
+
+	// processDue processes deliveries until the due one is claimed.
+	processDue := func() {
+		require.Eventually(t, func() bool {
+			claimed, err := dispatcher.Process(ctx)
+			require.NoError(t, err)
+			return claimed == 1
+		}, 5*time.Second, 10*time.Millisecond)
+	}
+
+	delivered := newEvent(t, "dummy.created")
+	require.NoError(t, service.Enqueue(ctx, delivered))
+
+	claimed, err := dispatcher.Process(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, claimed)
+	require.Len(t, received, 1)
+	require.Equal(t, delivered.ID, received[0].ID)
+
+	deliveries, err := service.Deliveries(ctx, subscription.ID, 0, 0)
+	require.NoError(t, err)
+	require.Equal(t, webhooks.DeliveryDelivered, deliveries[0].Status)
+	require.Equal(t, http.StatusOK, deliveries[0].ResponseStatus)
+	require.NotNil(t, deliveries[0].DeliveredAt)
+
+	t.Run("retries and dead letter", func(t *testing.T) {
+		status = http.StatusInternalServerError
+		failing := newEvent(t, "dummy.updated")
+		require.NoError(t, service.Enqueue(ctx, failing))
+
+		var delivery webhooks.Delivery
+		for attempt := 1; attempt <= config.MaxAttempts; attempt++ {
+			processDue()
+
+			delivery = find(subscription.ID, failing.ID)
+			require.Equal(t, attempt, delivery.Attempts)
+			require.Equal(t, http.StatusInternalServerError, delivery.ResponseStatus)
+			require.Contains(t, delivery.LastError, "500")
+
+			if attempt < config.MaxAttempts {
+				require.Equal(t, webhooks.DeliveryPending, delivery.Status)
+				require.NotNil(t, delivery.LastAttemptAt)
+				delay := time.Duration(1<<(attempt-1)) * config.MinRetryDelay
+				require.WithinDuration(t, delivery.LastAttemptAt.Add(delay), delivery.NextAttemptAt, config.Timeout)
+				require.False(t, delivery.NextAttemptAt.Before(delivery.LastAttemptAt.Add(delay)))
+			}
+		}
+		require.Equal(t, webhooks.DeliveryDead, delivery.Status)
+
+		claimed, err := dispatcher.Process(ctx)
+		require.NoError(t, err)
+		require.Zero(t, claimed, "dead deliveries are not retried")
+
+		status = http.StatusAccepted
+		require.NoError(t, service.Redeliver(ctx, subscription.ID, delivery.ID))
+		claimed, err = dispatcher.Process(ctx)
+		require.NoError(t, err)
+		require.Equal(t, 1, claimed)
+
+		delivery = find(subscription.ID, failing.ID)
+		require.Equal(t, webhooks.DeliveryDelivered, delivery.Status)
+		require.Equal(t, 1, delivery.Attempts)
+	})
+
+	t.Run("unreachable receiver", func(t *testing.T) {
+		unreachable, err := service.Create(ctx, "http://127.0.0.1:1", []string{"dummy.deleted"}, "secret")
+		require.NoError(t, err)
+
+		event := newEvent(t, "dummy.deleted")
+		require.NoError(t, service.Enqueue(ctx, event))
+
+		_, err = dispatcher.Process(ctx)
+		require.NoError(t, err)
+
+		delivery := find(unreachable.ID, event.ID)
+		require.Equal(t, webhooks.DeliveryPending, delivery.Status)
+		require.Zero(t, delivery.ResponseStatus)
+		require.NotEmpty(t, delivery.LastError)
+
+		// deliveries of deleted subscriptions are deleted too, so later tests claim only their own.
+		require.NoError(t, service.Delete(ctx, unreachable.ID))
+	})
+
+	t.Run("local network", func(t *testing.T) {
+		// the subscription could point to a host name which resolves to a local address.
+		require.NoError(t, service.Delete(ctx, subscription.ID))
+		local, err := service.Create(ctx, receiver.URL, nil, "secret")
+		require.NoError(t, err)
+
+		forbidding := config
+		forbidding.AllowPrivateNetworks = false
+		dispatcher := webhooks.NewDispatcher(zaplog.NewLog(), forbidding, db)
+		defer func() { require.NoError(t, dispatcher.Close()) }()
+
+		event := newEvent(t, "dummy.created")
+		require.NoError(t, service.Enqueue(ctx, event))
+		received = nil
+
+		claimed, err := dispatcher.Process(ctx)
+		require.NoError(t, err)
+		require.Equal(t, 1, claimed)
+		require.Empty(t, received)
+
+		delivery := find(local.ID, event.ID)
+		require.Equal(t, webhooks.DeliveryPending, delivery.Status)
+		require.Contains(t, delivery.LastError, "forbidden webhook address")
+	})
+}
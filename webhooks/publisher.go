@@ -0,0 +1,26 @@
+package webhooks
+
+import (
+	"context"
+
+	"project_template/events"
+)
+
+// publisher passes events published by the outbox relay to the webhooks service.
+type publisher struct {
+	service *Service
+}
+
+// NewPublisher creates an events publisher which creates webhook deliveries of published events.
+// Deliveries are deduplicated by event id, so events published again by the relay are delivered once.
+func NewPublisher(service *Service) events.Publisher {
+	return &publisher{service: service}
+}
+
+func (publisher *publisher) Publish(ctx context.Context, event events.Event) error {
+	return publisher.service.Enqueue(ctx, event)
+}
+
+func (publisher *publisher) Close() error {
+	return nil
+}
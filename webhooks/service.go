@@ -0,0 +1,218 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zeebo/errs"
+
+	"project_template/events"
+)
+
+// ErrWebhooks indicates that there was an error in the service.
+var ErrWebhooks = errs.Class("webhooks service error")
+
+const (
+	// DefaultDeliveriesLimit is the number of deliveries returned if the limit is not set.
+	DefaultDeliveriesLimit = 50
+	// MaxDeliveriesLimit is the maximum number of deliveries returned at once.
+	MaxDeliveriesLimit = 500
+)
+
+// Service is handling webhook subscriptions related logic.
+//
+// architecture: Service
+type Service struct {
+	config Config
+	db     DB
+}
+
+// NewService is a constructor for webhooks service.
+func NewService(config Config, db DB) *Service {
+	return &Service{
+		config: config,
+		db:     db,
+	}
+}
+
+// List returns all subscriptions without secrets.
+func (service *Service) List(ctx context.Context) ([]Subscription, error) {
+	subscriptions, err := service.db.ListSubscriptions(ctx)
+	if err != nil {
+		return nil, ErrWebhooks.Wrap(err)
+	}
+
+	for i := range subscriptions {
+		subscriptions[i].Secret = ""
+	}
+
+	return subscriptions, nil
+}
+
+// Get returns subscription without the secret.
+func (service *Service) Get(ctx context.Context, id uuid.UUID) (Subscription, error) {
+	subscription, err := service.db.GetSubscription(ctx, id)
+	if err != nil {
+		return Subscription{}, ErrWebhooks.Wrap(err)
+	}
+
+	subscription.Secret = ""
+	return subscription, nil
+}
+
+// Create creates an active subscription, the secret is generated if it's empty.
+// The returned subscription is the only place where the secret is exposed.
+func (service *Service) Create(ctx context.Context, url string, eventTypes []string, secret string) (Subscription, error) {
+	if secret == "" {
+		var err error
+		if secret, err = NewSecret(); err != nil {
+			return Subscription{}, ErrWebhooks.Wrap(err)
+		}
+	}
+
+	now := time.Now()
+	subscription := Subscription{
+		ID:         uuid.New(),
+		URL:        url,
+		EventTypes: normalizeEventTypes(eventTypes),
+		Secret:     secret,
+		Active:     true,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+
+	if err := service.validate(subscription); err != nil {
+		return Subscription{}, err
+	}
+
+	if err := service.db.CreateSubscription(ctx, subscription); err != nil {
+		return Subscription{}, ErrWebhooks.Wrap(err)
+	}
+
+	return subscription, nil
+}
+
+// Update changes url, event types and active flag of the subscription, the secret is rotated if it's not empty.
+func (service *Service) Update(ctx context.Context, id uuid.UUID, url string, eventTypes []string, active bool, secret string) (Subscription, error) {
+	subscription, err := service.db.GetSubscription(ctx, id)
+	if err != nil {
+		return Subscription{}, ErrWebhooks.Wrap(err)
+	}
+
+	subscription.URL = url
+	subscription.EventTypes = normalizeEventTypes(eventTypes)
+	subscription.Active = active
+	subscription.UpdatedAt = time.Now()
+	if secret != "" {
+		subscription.Secret = secret
+	}
+
+	if err = service.validate(subscription); err != nil {
+		return Subscription{}, err
+	}
+
+	if err = service.db.UpdateSubscription(ctx, subscription); err != nil {
+		return Subscription{}, ErrWebhooks.Wrap(err)
+	}
+
+	subscription.Secret = ""
+	return subscription, nil
+}
+
+// validate checks the subscription and, unless private networks are allowed, rejects urls
+// which point to the local network.
+func (service *Service) validate(subscription Subscription) error {
+	if err := subscription.Validate(); err != nil {
+		return err
+	}
+	if service.config.AllowPrivateNetworks {
+		return nil
+	}
+
+	endpoint, err := url.Parse(subscription.URL)
+	if err != nil {
+		return ErrInvalidSubscription.Wrap(err)
+	}
+	if err = checkHost(endpoint.Hostname()); err != nil {
+		return ErrInvalidSubscription.Wrap(err)
+	}
+	return nil
+}
+
+// Delete deletes the subscription with its delivery history.
+func (service *Service) Delete(ctx context.Context, id uuid.UUID) error {
+	return ErrWebhooks.Wrap(service.db.DeleteSubscription(ctx, id))
+}
+
+// Deliveries returns delivery history of the subscription, newest first.
+func (service *Service) Deliveries(ctx context.Context, subscriptionID uuid.UUID, limit, offset int) ([]Delivery, error) {
+	switch {
+	case limit == 0:
+		limit = DefaultDeliveriesLimit
+	case limit < 0 || limit > MaxDeliveriesLimit:
+		return nil, ErrInvalidSubscription.New("limit must be between 1 and %d", MaxDeliveriesLimit)
+	}
+	if offset < 0 {
+		return nil, ErrInvalidSubscription.New("offset must not be negative")
+	}
+
+	// the subscription is checked, so history of a missing subscription is not reported as empty.
+	if _, err := service.db.GetSubscription(ctx, subscriptionID); err != nil {
+		return nil, ErrWebhooks.Wrap(err)
+	}
+
+	deliveries, err := service.db.ListDeliveries(ctx, subscriptionID, limit, offset)
+	return deliveries, ErrWebhooks.Wrap(err)
+}
+
+// Redeliver schedules the delivery for an immediate attempt, including dead and already delivered ones.
+func (service *Service) Redeliver(ctx context.Context, subscriptionID, deliveryID uuid.UUID) error {
+	return ErrWebhooks.Wrap(service.db.Redeliver(ctx, subscriptionID, deliveryID))
+}
+
+// Enqueue creates pending deliveries of the event for every active subscription which matches its type.
+func (service *Service) Enqueue(ctx context.Context, event events.Event) error {
+	subscriptions, err := service.db.ListSubscriptions(ctx)
+	if err != nil {
+		return ErrWebhooks.Wrap(err)
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return ErrWebhooks.Wrap(err)
+	}
+
+	var deliveries []Delivery
+	for _, subscription := range subscriptions {
+		if !subscription.Active || !subscription.Matches(event.Type) {
+			continue
+		}
+
+		deliveries = append(deliveries, Delivery{
+			ID:             uuid.New(),
+			SubscriptionID: subscription.ID,
+			EventID:        event.ID,
+			EventType:      event.Type,
+			Payload:        payload,
+			Status:         DeliveryPending,
+			CreatedAt:      time.Now(),
+		})
+	}
+
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	return ErrWebhooks.Wrap(service.db.CreateDeliveries(ctx, deliveries))
+}
+
+// normalizeEventTypes keeps nil event types as an empty list, so it's encoded as an empty json array.
+func normalizeEventTypes(eventTypes []string) []string {
+	if eventTypes == nil {
+		return []string{}
+	}
+	return eventTypes
+}
@@ -0,0 +1,170 @@
+package webhooks_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"project_template"
+	"project_template/database/dbtesting"
+	"project_template/events"
+	"project_template/webhooks"
+)
+
+type testPayload struct {
+	Type string `json:"-"`
+}
+
+func (payload testPayload) EventType() string { return payload.Type }
+
+func newEvent(t *testing.T, eventType string) events.Event {
+	event, err := events.New(testPayload{Type: eventType})
+	require.NoError(t, err)
+	return event
+}
+
+func TestService(t *testing.T) {
+	dbtesting.Run(t, func(ctx context.Context, t *testing.T, db project_template.DB) {
+		testService(ctx, t, db.Webhooks())
+	})
+}
+
+func testService(ctx context.Context, t *testing.T, db webhooks.DB) {
+	service := webhooks.NewService(webhooks.Config{}, db)
+
+	t.Run("validation", func(t *testing.T) {
+		for _, url := range []string{"", "ftp://example.com", "/relative", "http://"} {
+			_, err := service.Create(ctx, url, nil, "")
+			require.True(t, webhooks.ErrInvalidSubscription.Has(err), url)
+		}
+
+		_, err := service.Create(ctx, "https://example.com", []string{"dummy.*.x"}, "")
+		require.True(t, webhooks.ErrInvalidSubscription.Has(err))
+	})
+
+	t.Run("local network", func(t *testing.T) {
+		local := []string{
+			"http://localhost:8080", "http://api.localhost", "http://127.0.0.1", "http://[::1]",
+			"http://169.254.169.254/latest/meta-data", "http://10.0.0.1", "http://192.168.1.1", "http://0.0.0.0",
+		}
+		for _, url := range local {
+			_, err := service.Create(ctx, url, nil, "")
+			require.True(t, webhooks.ErrInvalidSubscription.Has(err), url)
+			require.True(t, webhooks.ErrForbiddenAddress.Has(err), url)
+		}
+
+		subscription, err := service.Create(ctx, "https://example.com/local", nil, "")
+		require.NoError(t, err)
+		_, err = service.Update(ctx, subscription.ID, "http://127.0.0.1", nil, true, "")
+		require.True(t, webhooks.ErrForbiddenAddress.Has(err))
+		require.NoError(t, service.Delete(ctx, subscription.ID))
+
+		allowed := webhooks.NewService(webhooks.Config{AllowPrivateNetworks: true}, db)
+		subscription, err = allowed.Create(ctx, "http://localhost:8080", nil, "")
+		require.NoError(t, err)
+		require.NoError(t, allowed.Delete(ctx, subscription.ID))
+	})
+
+	all, err := service.Create(ctx, "https://example.com/all", nil, "")
+	require.NoError(t, err)
+	require.NotEmpty(t, all.Secret)
+	require.True(t, all.Active)
+
+	created, err := service.Create(ctx, "https://example.com/created", []string{"dummy.created"}, "custom-secret")
+	require.NoError(t, err)
+	require.Equal(t, "custom-secret", created.Secret)
+
+	prefixed, err := service.Create(ctx, "https://example.com/prefixed", []string{"dummy.*"}, "")
+	require.NoError(t, err)
+
+	t.Run("secrets are not listed", func(t *testing.T) {
+		list, err := service.List(ctx)
+		require.NoError(t, err)
+		require.Len(t, list, 3)
+		for _, subscription := range list {
+			require.Empty(t, subscription.Secret)
+		}
+
+		got, err := service.Get(ctx, created.ID)
+		require.NoError(t, err)
+		require.Empty(t, got.Secret)
+		require.Equal(t, []string{"dummy.created"}, got.EventTypes)
+
+		encoded, err := json.Marshal(got)
+		require.NoError(t, err)
+		require.NotContains(t, string(encoded), "secret")
+	})
+
+	t.Run("update", func(t *testing.T) {
+		updated, err := service.Update(ctx, prefixed.ID, prefixed.URL, []string{"dummy.*"}, false, "rotated")
+		require.NoError(t, err)
+		require.False(t, updated.Active)
+		stored, err := db.GetSubscription(ctx, prefixed.ID)
+		require.NoError(t, err)
+		require.Equal(t, "rotated", stored.Secret)
+
+		_, err = service.Update(ctx, prefixed.ID, prefixed.URL, nil, true, "")
+		require.NoError(t, err)
+		stored, err = db.GetSubscription(ctx, prefixed.ID)
+		require.NoError(t, err)
+		require.Equal(t, "rotated", stored.Secret, "secret is kept if it's not set")
+		require.Empty(t, stored.EventTypes)
+
+		_, err = service.Update(ctx, prefixed.ID, prefixed.URL, []string{"dummy.*"}, false, "")
+		require.NoError(t, err)
+
+		_, err = service.Update(ctx, uuid.New(), prefixed.URL, nil, true, "")
+		require.True(t, webhooks.ErrNoSubscription.Has(err))
+	})
+
+	t.Run("enqueue", func(t *testing.T) {
+		createdEvent := newEvent(t, "dummy.created")
+		require.NoError(t, service.Enqueue(ctx, createdEvent))
+		require.NoError(t, service.Enqueue(ctx, newEvent(t, "dummy.deleted")))
+		// events published again by the relay are not delivered twice.
+		require.NoError(t, service.Enqueue(ctx, createdEvent))
+
+		deliveries, err := service.Deliveries(ctx, all.ID, 0, 0)
+		require.NoError(t, err)
+		require.Len(t, deliveries, 2)
+
+		deliveries, err = service.Deliveries(ctx, created.ID, 0, 0)
+		require.NoError(t, err)
+		require.Len(t, deliveries, 1)
+		require.Equal(t, createdEvent.ID, deliveries[0].EventID)
+		require.Equal(t, webhooks.DeliveryPending, deliveries[0].Status)
+
+		var body events.Event
+		require.NoError(t, json.Unmarshal(deliveries[0].Payload, &body))
+		require.Equal(t, createdEvent.ID, body.ID)
+		require.Equal(t, "dummy.created", body.Type)
+
+		deliveries, err = service.Deliveries(ctx, prefixed.ID, 0, 0)
+		require.NoError(t, err)
+		require.Empty(t, deliveries, "inactive subscriptions get no deliveries")
+
+		_, err = service.Deliveries(ctx, uuid.New(), 0, 0)
+		require.True(t, webhooks.ErrNoSubscription.Has(err))
+
+		_, err = service.Deliveries(ctx, all.ID, webhooks.MaxDeliveriesLimit+1, 0)
+		require.True(t, webhooks.ErrInvalidSubscription.Has(err))
+	})
+
+	t.Run("redeliver", func(t *testing.T) {
+		deliveries, err := service.Deliveries(ctx, created.ID, 0, 0)
+		require.NoError(t, err)
+
+		err = service.Redeliver(ctx, all.ID, deliveries[0].ID)
+		require.True(t, webhooks.ErrNoDelivery.Has(err), "delivery of another subscription")
+
+		require.NoError(t, service.Redeliver(ctx, created.ID, deliveries[0].ID))
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, service.Delete(ctx, all.ID))
+		require.True(t, webhooks.ErrNoSubscription.Has(service.Delete(ctx, all.ID)))
+	})
+}
@@ -0,0 +1,81 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zeebo/errs"
+)
+
+// SignatureHeader is the header with the signature of a delivery.
+const SignatureHeader = "X-Webhook-Signature"
+
+// ErrSignature indicates that the signature of a delivery is not valid.
+var ErrSignature = errs.Class("invalid webhook signature")
+
+// Sign returns the signature header value "t=<unix time>,v1=<hex hmac>", where hmac is HMAC-SHA256
+// of "<unix time>.<body>" keyed by the subscription secret. The timestamp is signed, so receivers
+// can reject replayed deliveries.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	unix := strconv.FormatInt(timestamp.Unix(), 10)
+	return "t=" + unix + ",v1=" + hex.EncodeToString(signature(secret, unix, body))
+}
+
+// Verify checks the signature header value of the body, signatures older than tolerance are rejected.
+func Verify(secret, header string, body []byte, now time.Time, tolerance time.Duration) error {
+	var (
+		unix       string
+		signatures [][]byte
+	)
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			unix = value
+		case "v1":
+			if decoded, err := hex.DecodeString(value); err == nil {
+				signatures = append(signatures, decoded)
+			}
+		}
+	}
+
+	seconds, err := strconv.ParseInt(unix, 10, 64)
+	if err != nil {
+		return ErrSignature.New("timestamp is missing")
+	}
+	if age := now.Sub(time.Unix(seconds, 0)); age > tolerance || age < -tolerance {
+		return ErrSignature.New("timestamp is outside of the tolerance")
+	}
+
+	expected := signature(secret, unix, body)
+	for _, actual := range signatures {
+		if hmac.Equal(expected, actual) {
+			return nil
+		}
+	}
+
+	return ErrSignature.New("signature does not match")
+}
+
+// signature returns HMAC-SHA256 of the timestamp and body.
+func signature(secret, unix string, body []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unix))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+// NewSecret generates a random secret for a subscription.
+func NewSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
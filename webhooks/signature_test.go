@@ -0,0 +1,42 @@
+package webhooks_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"project_template/webhooks"
+)
+
+func TestSignature(t *testing.T) {
+	body := []byte(`{"id":"1"}`)
+	now := time.Unix(1700000000, 0)
+
+	header := webhooks.Sign("secret", now, body)
+	require.Regexp(t, `^t=1700000000,v1=[0-9a-f]{64}$`, header)
+
+	require.NoError(t, webhooks.Verify("secret", header, body, now.Add(time.Minute), 5*time.Minute))
+	// receivers can accept several signatures while the secret is rotated.
+	require.NoError(t, webhooks.Verify("secret", webhooks.Sign("old", now, body)+","+header[len("t=1700000000,"):], body, now, time.Minute))
+
+	for name, err := range map[string]error{
+		"other secret": webhooks.Verify("other", header, body, now, time.Minute),
+		"other body":   webhooks.Verify("secret", header, []byte(`{"id":"2"}`), now, time.Minute),
+		"expired":      webhooks.Verify("secret", header, body, now.Add(time.Hour), time.Minute),
+		"no timestamp": webhooks.Verify("secret", header[len("t=1700000000,"):], body, now, time.Minute),
+		"empty":        webhooks.Verify("secret", "", body, now, time.Minute),
+	} {
+		require.True(t, webhooks.ErrSignature.Has(err), name)
+	}
+}
+
+func TestNewSecret(t *testing.T) {
+	first, err := webhooks.NewSecret()
+	require.NoError(t, err)
+	second, err := webhooks.NewSecret()
+	require.NoError(t, err)
+
+	require.Regexp(t, `^whsec_[0-9a-f]{64}$`, first)
+	require.NotEqual(t, first, second)
+}
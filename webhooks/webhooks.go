@@ -0,0 +1,172 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zeebo/errs"
+)
+
+var (
+	// ErrNoSubscription indicates that webhook subscription does not exist.
+	ErrNoSubscription = errs.Class("webhook subscription does not exist")
+	// ErrNoDelivery indicates that webhook delivery does not exist.
+	ErrNoDelivery = errs.Class("webhook delivery does not exist")
+	// ErrInvalidSubscription indicates that subscription data is not valid.
+	ErrInvalidSubscription = errs.Class("invalid webhook subscription")
+)
+
+// DB exposes access to webhook subscriptions and their deliveries.
+//
+// architecture: Database
+type DB interface {
+	// ListSubscriptions returns all subscriptions ordered by creation time.
+	ListSubscriptions(ctx context.Context) ([]Subscription, error)
+
+	// GetSubscription returns subscription by id.
+	GetSubscription(ctx context.Context, id uuid.UUID) (Subscription, error)
+
+	// CreateSubscription stores a new subscription.
+	CreateSubscription(ctx context.Context, subscription Subscription) error
+
+	// UpdateSubscription updates url, event types, secret and active flag of the subscription.
+	UpdateSubscription(ctx context.Context, subscription Subscription) error
+
+	// DeleteSubscription deletes the subscription with its deliveries.
+	DeleteSubscription(ctx context.Context, id uuid.UUID) error
+
+	// CreateDeliveries stores pending deliveries in one transaction, deliveries of an event
+	// which already has a delivery to the same subscription are skipped.
+	CreateDeliveries(ctx context.Context, deliveries []Delivery) error
+
+	// ListDeliveries returns deliveries of the subscription, newest first.
+	ListDeliveries(ctx context.Context, subscriptionID uuid.UUID, limit, offset int) ([]Delivery, error)
+
+	// ClaimDeliveries returns up to limit pending deliveries of active subscriptions which are due, oldest first,
+	// and hides them from other claims until leaseUntil, so deliveries of a crashed worker are retried.
+	ClaimDeliveries(ctx context.Context, limit int, leaseUntil time.Time) ([]Delivery, error)
+
+	// MarkDelivered records the successful attempt of the delivery.
+	MarkDelivered(ctx context.Context, id uuid.UUID, attempt Attempt) error
+
+	// Retry records the failed attempt of the delivery and schedules the next one.
+	Retry(ctx context.Context, id uuid.UUID, attempt Attempt, nextAttemptAt time.Time) error
+
+	// MarkDead records the failed attempt of the delivery and stops retrying it.
+	MarkDead(ctx context.Context, id uuid.UUID, attempt Attempt) error
+
+	// Redeliver makes the delivery of the subscription pending again, with attempts counted from zero.
+	Redeliver(ctx context.Context, subscriptionID, id uuid.UUID) error
+}
+
+// Subscription receives events of the listed types posted to its url.
+type Subscription struct {
+	ID  uuid.UUID `json:"id"`
+	URL string    `json:"url"`
+	// EventTypes filters delivered events, "dummy.*" matches all types with the "dummy." prefix.
+	// All events are delivered if it's empty.
+	EventTypes []string `json:"eventTypes"`
+	// Secret signs deliveries, it's returned only when the subscription is created.
+	Secret    string    `json:"secret,omitempty"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Validate checks that the subscription can be stored.
+func (subscription Subscription) Validate() error {
+	if subscription.ID == uuid.Nil {
+		return ErrInvalidSubscription.New("id is required")
+	}
+
+	endpoint, err := url.Parse(subscription.URL)
+	if err != nil {
+		return ErrInvalidSubscription.New("invalid url: %v", err)
+	}
+	if (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return ErrInvalidSubscription.New("url must be an absolute http or https url")
+	}
+
+	for _, eventType := range subscription.EventTypes {
+		if strings.TrimSpace(eventType) == "" || strings.Contains(strings.TrimSuffix(eventType, "*"), "*") {
+			return ErrInvalidSubscription.New("invalid event type %q", eventType)
+		}
+	}
+
+	if subscription.Secret == "" {
+		return ErrInvalidSubscription.New("secret is required")
+	}
+
+	return nil
+}
+
+// Matches returns true if events of the type are delivered to the subscription.
+func (subscription Subscription) Matches(eventType string) bool {
+	if len(subscription.EventTypes) == 0 {
+		return true
+	}
+
+	for _, filter := range subscription.EventTypes {
+		if prefix, ok := cutSuffix(filter, "*"); ok {
+			if strings.HasPrefix(eventType, prefix) {
+				return true
+			}
+			continue
+		}
+		if filter == eventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// cutSuffix returns s without the suffix and true if s ends with it.
+func cutSuffix(s, suffix string) (string, bool) {
+	if !strings.HasSuffix(s, suffix) {
+		return s, false
+	}
+	return s[:len(s)-len(suffix)], true
+}
+
+// DeliveryStatus defines the list of possible delivery statuses.
+type DeliveryStatus string
+
+const (
+	// DeliveryPending indicates that delivery is waiting for the next attempt.
+	DeliveryPending DeliveryStatus = "pending"
+	// DeliveryDelivered indicates that the receiver accepted the delivery.
+	DeliveryDelivered DeliveryStatus = "delivered"
+	// DeliveryDead indicates that all attempts failed, the delivery is retried only if it's redelivered manually.
+	DeliveryDead DeliveryStatus = "dead"
+)
+
+// Delivery is an event sent to a single subscription.
+type Delivery struct {
+	ID             uuid.UUID `json:"id"`
+	SubscriptionID uuid.UUID `json:"subscriptionId"`
+	EventID        uuid.UUID `json:"eventId"`
+	EventType      string    `json:"eventType"`
+	// Payload is the request body, the event encoded as json.
+	Payload       json.RawMessage `json:"payload"`
+	Status        DeliveryStatus  `json:"status"`
+	Attempts      int             `json:"attempts"`
+	NextAttemptAt time.Time       `json:"nextAttemptAt"`
+	// LastAttemptAt, ResponseStatus and LastError describe the latest attempt, ResponseStatus is 0 if there was no response.
+	LastAttemptAt  *time.Time `json:"lastAttemptAt,omitempty"`
+	ResponseStatus int        `json:"responseStatus,omitempty"`
+	LastError      string     `json:"lastError,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	DeliveredAt    *time.Time `json:"deliveredAt,omitempty"`
+}
+
+// Attempt is a result of a delivery attempt.
+type Attempt struct {
+	At             time.Time
+	ResponseStatus int
+	Error          string
+}